@@ -0,0 +1,111 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maxTrackedClients = 100000 // Clients counted per window, to bound the memory
+
+var clientRateLimit = 0 // Requests per window of a client, 0 disables the detection
+var clientRateWindow = time.Minute
+var clientRateCooldown = 10 * time.Minute
+var clientCounts = map[string]int{}
+var clientWarnings = map[string]time.Time{} // Last warning per client
+var clientWindowStart = time.Now()
+var clientRateLock = sync.Mutex{}
+
+// Logs a WARN entry when a client IP (X-Forwarded-For or remote address) sends more than limit
+// requests within a window, observed on the public access log (sampled out entries included).
+// A client is warned about at most once per cooldown. A limit of 0 disables the detection.
+func SetClientRateWarning(limit int, window time.Duration, cooldown time.Duration) {
+
+	clientRateLock.Lock()
+	defer clientRateLock.Unlock()
+
+	clientRateLimit = limit
+	clientRateWindow = window
+	clientRateCooldown = cooldown
+	clientCounts = map[string]int{}
+	clientWarnings = map[string]time.Time{}
+	clientWindowStart = time.Now()
+}
+
+// Counts the request of the client and warns if it exceeds the rate.
+func observeClient(addr string) {
+
+	clientRateLock.Lock()
+
+	if clientRateLimit <= 0 {
+		clientRateLock.Unlock()
+		return
+	}
+
+	now := time.Now()
+
+	if now.Sub(clientWindowStart) >= clientRateWindow {
+		clientCounts = map[string]int{}
+		clientWindowStart = now
+		for ip, t := range clientWarnings {
+			if now.Sub(t) >= clientRateCooldown {
+				delete(clientWarnings, ip)
+			}
+		}
+	}
+
+	ip := addr
+	if parsed := clientIP(addr); parsed != nil {
+		ip = parsed.String()
+	}
+
+	count, ok := clientCounts[ip]
+	if !ok && len(clientCounts) >= maxTrackedClients {
+		clientRateLock.Unlock()
+		return
+	}
+
+	count++
+	clientCounts[ip] = count
+
+	warn := false
+	if count > clientRateLimit {
+		if last, ok := clientWarnings[ip]; !ok || now.Sub(last) >= clientRateCooldown {
+			clientWarnings[ip] = now
+			warn = true
+		}
+	}
+
+	limit, window := clientRateLimit, clientRateWindow
+
+	clientRateLock.Unlock()
+
+	if warn {
+		fields := []Field{{Key: "client_ip", Value: ip}, {Key: "requests", Value: count}, {Key: "window", Value: window}}
+		appLog(WARN, appLogLevel(), fields, []interface{}{"Client exceeding " + strconv.Itoa(limit) + " requests per " + window.String()})
+	}
+}
@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientRateWarning(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetClientRateWarning(5, time.Minute, time.Hour)
+	defer SetClientRateWarning(0, time.Minute, 10*time.Minute)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 20; j++ {
+		req, _ := http.NewRequest("GET", "http://www.deal.com/login", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		Public(*req, 401, 0, time.Millisecond)
+	}
+
+	for j := 0; j < 5; j++ {
+		req, _ := http.NewRequest("GET", "http://www.deal.com/", nil)
+		req.RemoteAddr = "198.51.100.1:4000"
+		Public(*req, 200, 0, time.Millisecond)
+	}
+
+	Stop()
+
+	text := readFile("./application.log", t)
+
+	if strings.Count(text, "Client exceeding 5 requests per 1m0s") != 1 || !strings.Contains(text, "client_ip=203.0.113.7 requests=6") ||
+		strings.Contains(text, "198.51.100.1") {
+		fmt.Println("Unexpected warnings", text)
+		t.Fail()
+	}
+}
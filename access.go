@@ -0,0 +1,215 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessEntry is a public access log entry.
+type AccessEntry struct {
+	Time          time.Time
+	Method        string
+	URL           string
+	Proto         string
+	RemoteAddr    string // X-Forwarded-For header or remote address
+	UserAgent     string
+	Duration      time.Duration
+	Status        int
+	ContentLength int
+	RequestID     string  // Set by the middleware
+	Errors        int     // Number of ERROR entries logged for the request through the middleware
+	SampleRate    float64 // Fraction of the similar entries kept, 1 if not sampled
+	Sequence      uint64  // Sequence number in the public access log, 0 if not stamped
+	Route         string  // Route pattern of the URL, see AddRoutePattern
+	Class         string  // Class of the request, e.g. health or bot, see SetClassRules
+	Fields        []Field // Added by the public hooks, see AddPublicHook
+
+	request *http.Request // Logged request, nil for the parsed entries
+}
+
+// Returns the logged request, for the public hooks. Nil for the entries read from the files.
+func (e *AccessEntry) Request() *http.Request {
+	return e.request
+}
+
+var accessLinePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) (\S*) (\S*) (\S*) from \[(.*?)\] with agent \[(.*)\] in (\d+)(ms|μs|ns) => (\d+) with (\d+) bytes ?(.*)$`)
+
+// Returns the entry formatted as in the public access log file.
+func (e *AccessEntry) String() string {
+
+	d := e.Duration
+	ns := int64(d)
+	μs := int64(d / time.Microsecond)
+	ms := int64(d / time.Millisecond)
+
+	message := e.Time.Format("2006-01-02 15:04:05") + " "
+	message += e.Method + " " + e.URL + " " + e.Proto + " from [" + e.RemoteAddr + "] with agent [" + e.UserAgent + "]"
+
+	if ms > 0 {
+		message += " in " + strconv.FormatInt(ms, 10) + "ms => " + strconv.Itoa(e.Status)
+	} else if μs > 0 {
+		message += " in " + strconv.FormatInt(μs, 10) + "μs => " + strconv.Itoa(e.Status)
+	} else {
+		// Very fast computer ;)
+		message += " in " + strconv.FormatInt(ns, 10) + "ns => " + strconv.Itoa(e.Status)
+	}
+
+	message += " with " + strconv.Itoa(e.ContentLength) + " bytes "
+
+	if e.RequestID != "" {
+		message += "request_id=" + e.RequestID + " "
+	}
+
+	if e.Route != "" {
+		message += "route=" + e.Route + " "
+	}
+
+	if e.Class != "" {
+		message += "class=" + e.Class + " "
+	}
+
+	for _, f := range e.Fields {
+		message += formatField(f) + " "
+	}
+
+	if e.Errors > 0 {
+		message += "had_errors=true errors=" + strconv.Itoa(e.Errors) + " "
+	}
+
+	if e.SampleRate < 1 {
+		message += "sampled at " + formatSampleRate(e.SampleRate) + " "
+	}
+
+	if e.Sequence > 0 {
+		message += formatSequence(e.Sequence) + " "
+	}
+
+	if g := loadGlobals().text; g != "" {
+		message += g[1:] + " "
+	}
+
+	message += "\n"
+
+	return message
+}
+
+// Parses a line of the public access log. Durations are truncated to the unit they were logged with.
+func ParseAccessLine(s string) (AccessEntry, error) {
+
+	m := accessLinePattern.FindStringSubmatch(strings.TrimRight(s, "\r\n"))
+
+	if m == nil {
+		return AccessEntry{}, errors.New("invalid public access log line [" + s + "]")
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+	if err != nil {
+		return AccessEntry{}, err
+	}
+
+	e := AccessEntry{
+		Time:       t,
+		Method:     m[2],
+		URL:        m[3],
+		Proto:      m[4],
+		RemoteAddr: m[5],
+		UserAgent:  m[6],
+		SampleRate: 1,
+	}
+
+	amount, _ := strconv.ParseInt(m[7], 10, 64)
+
+	switch m[8] {
+	case "ms":
+		e.Duration = time.Duration(amount) * time.Millisecond
+	case "μs":
+		e.Duration = time.Duration(amount) * time.Microsecond
+	default:
+		e.Duration = time.Duration(amount)
+	}
+
+	if e.Status, err = strconv.Atoi(m[9]); err != nil {
+		return AccessEntry{}, err
+	}
+
+	if e.ContentLength, err = strconv.Atoi(m[10]); err != nil {
+		return AccessEntry{}, err
+	}
+
+	tail := strings.TrimSpace(m[11])
+
+	if s := sampledPattern.FindStringSubmatch(tail); s != nil {
+		if percent, err := strconv.ParseFloat(s[2], 64); err == nil {
+			e.SampleRate = percent / 100
+		}
+		tail = strings.TrimSpace(strings.Replace(tail, s[0], " ", 1))
+	}
+
+	if tail == "" {
+		return e, nil
+	}
+
+	fields, ok := parseFields(" " + tail)
+	if !ok {
+		return AccessEntry{}, errors.New("invalid fields in public access log line [" + s + "]")
+	}
+
+	global := map[string]bool{}
+	for _, f := range loadGlobals().fields {
+		global[f.Key] = true
+	}
+
+	for _, f := range fields {
+		value := f.Value.(string)
+
+		switch f.Key {
+		case "request_id":
+			e.RequestID = value
+		case "route":
+			e.Route = value
+		case "class":
+			e.Class = value
+		case "seq":
+			e.Sequence, _ = strconv.ParseUint(value, 10, 64)
+		case "errors":
+			e.Errors, _ = strconv.Atoi(value)
+		case "had_errors":
+		default:
+			if !global[f.Key] { // Written by String from the current global fields
+				e.Fields = append(e.Fields, f)
+			}
+		}
+	}
+
+	return e, nil
+}
+
+var sampledPattern = regexp.MustCompile(`(^| )sampled at (\S+)%( |$)`)
@@ -0,0 +1,140 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseAccessLine(t *testing.T) {
+
+	req, _ := http.NewRequest("POST", "http://www.deal.com/abc?p=xyz", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 [beta] in 2ms => 500")
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	e := decoratePublicAccessLogEntry(*req, 404, 12, 3*time.Millisecond, 0.25)
+	e.RequestID = "id-1"
+	e.Errors = 2
+	e.Sequence = 7
+	e.Route = "/abc"
+	e.Class = "bot"
+	e.Fields = []Field{{Key: "accept", Value: "text/html"}, {Key: "note", Value: "two words"}, {Key: "download_bytes_per_sec", Value: "4000"}}
+
+	parsed, err := ParseAccessLine(e.String())
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	e.Time = e.Time.Truncate(time.Second)
+
+	if !reflect.DeepEqual(parsed, *e) {
+		fmt.Printf("Expected %+v got %+v\n", *e, parsed)
+		t.Fail()
+	}
+
+	SetGlobalFields(map[string]string{"env": "prod"})
+	parsed, err = ParseAccessLine(e.String())
+	SetGlobalFields(nil)
+
+	if err != nil || !reflect.DeepEqual(parsed.Fields, e.Fields) {
+		fmt.Println("Global fields parsed back as entry fields", parsed.Fields, err)
+		t.Fail()
+	}
+
+	if _, err := ParseAccessLine("not an access log line"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestAccessReader(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetPublicLogMaxSize(1)
+	LogToStdout(false)
+
+	// Compressed archive of a previous day
+	archive, _ := os.Create("./2000-01-01-0-access.log.gz")
+	gz := gzip.NewWriter(archive)
+	req, _ := http.NewRequest("GET", "http://www.deal.com/archived", nil)
+	io.WriteString(gz, decoratePublicAccessLogEntry(*req, 200, 10, time.Millisecond, 1).String())
+	gz.Close()
+	archive.Close()
+	defer os.Remove("./2000-01-01-0-access.log.gz")
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 30; j++ {
+		req, _ := http.NewRequest("GET", "http://www.deal.com/abc?p="+strconv.Itoa(j), nil)
+		Public(*req, 200, 10, 1*time.Millisecond)
+	}
+
+	Stop()
+
+	r, err := OpenAccessReader(".", "access.log")
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	defer r.Close()
+
+	var urls []string
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		urls = append(urls, e.URL)
+	}
+
+	// Entries logged concurrently by the write routines may be slightly out of order
+	if len(urls) != 31 || urls[0] != "http://www.deal.com/archived" {
+		fmt.Println("Unexpected entries", urls)
+		t.Fail()
+	}
+}
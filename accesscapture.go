@@ -0,0 +1,464 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Binary capture segment format: the magic line, then records starting with their type. Strings
+// are defined once per segment and referenced by index, times are deltas in µs.
+const captureMagic = "GOLCAP1\n"
+const captureExtension = ".cap"
+const capturePrefix = "access-capture-"
+const captureMaxString = 16 * 1024 * 1024 // Longest string read back, as the lines of the log readers
+
+const (
+	captureString = 1 // uvarint length, bytes
+	captureEntry  = 2 // See writeEntry
+)
+
+var accessCapture *captureWriter
+var accessCaptureLock = sync.Mutex{}
+var publicTextLog = true
+
+// Writes the public access log entries into compact rolling binary segments of the folder
+// (access-capture-N.cap, segmentSize KB each, the last segments kept), readable with
+// OpenCaptureReader or the golcap tool, for high traffic edges where the text access log is too
+// large. The captured entries are the ones of the text access log (after sampling and hooks). They
+// are buffered, up to 64KB may be lost on a crash. An empty folder disables the capture.
+func SetAccessCapture(folder string, segmentSize int64, segments int) {
+
+	accessCaptureLock.Lock()
+	defer accessCaptureLock.Unlock()
+
+	if accessCapture != nil {
+		if err := accessCapture.close(); err != nil {
+			reportError(err)
+		}
+	}
+
+	if folder == "" {
+		accessCapture = nil
+		return
+	}
+
+	accessCapture = &captureWriter{folder: folder, maxSize: segmentSize * 1024, segments: segments}
+}
+
+// Enables (default) or disables the text public access log file, e.g. when the access log entries
+// are only captured in binary segments (see SetAccessCapture).
+func SetPublicTextLog(enabled bool) {
+	publicTextLog = enabled
+}
+
+// Writes the entry in the binary capture if enabled.
+func captureAccessEntry(e *AccessEntry) {
+
+	accessCaptureLock.Lock()
+	defer accessCaptureLock.Unlock()
+
+	if accessCapture == nil {
+		return
+	}
+
+	if err := accessCapture.write(e); err != nil {
+		log.Println("ERROR - Unable to capture access log entry", err)
+	}
+}
+
+// Flushes and closes the current capture segment.
+func closeAccessCapture() {
+
+	accessCaptureLock.Lock()
+	defer accessCaptureLock.Unlock()
+
+	if accessCapture == nil {
+		return
+	}
+
+	if err := accessCapture.close(); err != nil {
+		reportError(err)
+	}
+}
+
+// Writer of the rolling capture segments.
+type captureWriter struct {
+	folder   string
+	maxSize  int64 // in bytes
+	segments int   // Segments kept
+	number   int   // Number of the current segment
+	file     *os.File
+	buf      *bufio.Writer
+	size     int64
+	strings  map[string]uint64 // Indexes of the strings defined in the segment
+	last     int64             // Time of the last entry in µs
+	scratch  []byte
+}
+
+func (w *captureWriter) write(e *AccessEntry) error {
+
+	if w.file == nil || w.size >= w.maxSize {
+		if err := w.next(); err != nil {
+			return err
+		}
+	}
+
+	b := w.scratch[:0]
+
+	refs := [...]string{e.Method, e.URL, e.Proto, e.RemoteAddr, e.UserAgent, e.Route, e.Class}
+	var indexes [len(refs)]uint64
+
+	for i, s := range refs {
+		index, ok := w.strings[s]
+		if !ok {
+			index = uint64(len(w.strings))
+			w.strings[s] = index
+			b = append(b, captureString)
+			b = appendUvarint(b, uint64(len(s)))
+			b = append(b, s...)
+		}
+		indexes[i] = index
+	}
+
+	t := e.Time.UnixNano() / 1000
+
+	b = append(b, captureEntry)
+	b = appendVarint(b, t-w.last)
+	for _, index := range indexes {
+		b = appendUvarint(b, index)
+	}
+	b = appendUvarint(b, uint64(e.Status))
+	b = appendUvarint(b, uint64(e.Duration))
+	b = appendUvarint(b, uint64(e.ContentLength))
+	b = appendUvarint(b, uint64(e.Errors))
+	b = appendUvarint(b, e.Sequence)
+	b = appendUvarint(b, uint64(math.Round(e.SampleRate*1e6)))
+	b = appendUvarint(b, uint64(len(e.RequestID)))
+	b = append(b, e.RequestID...)
+
+	w.last = t
+	w.scratch = b
+
+	n, err := w.buf.Write(b)
+	w.size += int64(n)
+
+	return err
+}
+
+// Starts the next segment, removing the segments beyond the ones kept.
+func (w *captureWriter) next() error {
+
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	os.MkdirAll(w.folder, 0744)
+
+	if w.number == 0 {
+		numbers, err := captureSegments(w.folder)
+		if err != nil {
+			return err
+		}
+		if len(numbers) > 0 {
+			w.number = numbers[len(numbers)-1]
+		}
+	}
+
+	w.number++
+
+	f, err := os.OpenFile(w.folder+"/"+capturePrefix+strconv.Itoa(w.number)+captureExtension, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	w.file = f
+	w.buf = bufio.NewWriterSize(f, 64*1024)
+	w.strings = map[string]uint64{}
+	w.last = 0
+	w.size = int64(len(captureMagic))
+
+	if _, err := w.buf.WriteString(captureMagic); err != nil {
+		return err
+	}
+
+	numbers, err := captureSegments(w.folder)
+	if err != nil {
+		return err
+	}
+
+	for len(numbers) > w.segments && w.segments > 0 {
+		if err := os.Remove(w.folder + "/" + capturePrefix + strconv.Itoa(numbers[0]) + captureExtension); err != nil {
+			return err
+		}
+		numbers = numbers[1:]
+	}
+
+	return nil
+}
+
+func (w *captureWriter) close() error {
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.buf.Flush()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+
+	w.file = nil
+
+	return err
+}
+
+// Returns the numbers of the capture segments of the folder, in order.
+func captureSegments(folder string) ([]int, error) {
+
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []int
+
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasPrefix(name, capturePrefix) && strings.HasSuffix(name, captureExtension) {
+			if n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, capturePrefix), captureExtension)); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+
+	sort.Ints(numbers)
+
+	return numbers, nil
+}
+
+// CaptureReader reads the access log entries of the capture segments of a folder, oldest first.
+type CaptureReader struct {
+	folder  string
+	numbers []int
+	file    *os.File
+	r       *bufio.Reader
+	strings []string
+	last    int64
+}
+
+// Opens the capture segments of the folder, see SetAccessCapture.
+func OpenCaptureReader(folder string) (*CaptureReader, error) {
+
+	numbers, err := captureSegments(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CaptureReader{folder: folder, numbers: numbers}, nil
+}
+
+// Returns the next entry, or io.EOF after the last one. The entries have no Fields, and their
+// Time is in the local time zone.
+func (r *CaptureReader) Next() (AccessEntry, error) {
+
+	for {
+		if r.r == nil {
+			if len(r.numbers) == 0 {
+				return AccessEntry{}, io.EOF
+			}
+			if err := r.openSegment(r.numbers[0]); err != nil {
+				return AccessEntry{}, err
+			}
+			r.numbers = r.numbers[1:]
+		}
+
+		e, err := r.readEntry()
+		if err == nil {
+			return e, nil
+		}
+
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			return AccessEntry{}, err
+		}
+
+		r.closeSegment() // End of the segment, or record truncated by a crash
+	}
+}
+
+func (r *CaptureReader) openSegment(number int) error {
+
+	f, err := os.Open(r.folder + "/" + capturePrefix + strconv.Itoa(number) + captureExtension)
+	if err != nil {
+		return err
+	}
+
+	r.file = f
+	r.r = bufio.NewReader(f)
+	r.strings = r.strings[:0]
+	r.last = 0
+
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(r.r, magic); err != nil || string(magic) != captureMagic {
+		r.closeSegment()
+		return errors.New("invalid capture segment [" + f.Name() + "]")
+	}
+
+	return nil
+}
+
+func (r *CaptureReader) closeSegment() {
+	if r.file != nil {
+		r.file.Close()
+		r.file, r.r = nil, nil
+	}
+}
+
+func (r *CaptureReader) readEntry() (AccessEntry, error) {
+
+	for {
+		kind, err := r.r.ReadByte()
+		if err != nil {
+			return AccessEntry{}, err
+		}
+
+		switch kind {
+		case captureString:
+			s, err := r.readString()
+			if err != nil {
+				return AccessEntry{}, unexpected(err)
+			}
+			r.strings = append(r.strings, s)
+		case captureEntry:
+			return r.readFields()
+		default:
+			return AccessEntry{}, errors.New("invalid capture record type " + strconv.Itoa(int(kind)))
+		}
+	}
+}
+
+func (r *CaptureReader) readFields() (e AccessEntry, err error) {
+
+	delta, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return e, unexpected(err)
+	}
+
+	var values [13]uint64
+	for i := range values {
+		if values[i], err = binary.ReadUvarint(r.r); err != nil {
+			return e, unexpected(err)
+		}
+	}
+
+	for _, index := range values[:7] {
+		if index >= uint64(len(r.strings)) {
+			return e, errors.New("invalid capture string reference")
+		}
+	}
+
+	requestID, err := r.readString()
+	if err != nil {
+		return e, unexpected(err)
+	}
+
+	r.last += delta
+
+	s := r.strings
+	e = AccessEntry{
+		Time:          time.Unix(0, r.last*1000),
+		Method:        s[values[0]],
+		URL:           s[values[1]],
+		Proto:         s[values[2]],
+		RemoteAddr:    s[values[3]],
+		UserAgent:     s[values[4]],
+		Route:         s[values[5]],
+		Class:         s[values[6]],
+		Status:        int(values[7]),
+		Duration:      time.Duration(values[8]),
+		ContentLength: int(values[9]),
+		Errors:        int(values[10]),
+		Sequence:      values[11],
+		RequestID:     requestID,
+		SampleRate:    float64(values[12]) / 1e6,
+	}
+
+	return e, nil
+}
+
+func (r *CaptureReader) readString() (string, error) {
+
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return "", err
+	}
+
+	if n > captureMaxString {
+		return "", errors.New("invalid capture string length " + strconv.FormatUint(n, 10))
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Closes the current segment.
+func (r *CaptureReader) Close() error {
+	r.closeSegment()
+	return nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(b, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(b, tmp[:binary.PutVarint(tmp[:], v)]...)
+}
+
+// Returns io.ErrUnexpectedEOF for an EOF inside a record.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
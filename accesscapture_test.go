@@ -0,0 +1,122 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAccessCapture(t *testing.T) {
+	removeLogFiles(".")
+
+	folder := t.TempDir()
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetAccessCapture(folder, 1, 3)
+	defer SetAccessCapture("", 0, 0)
+	SetPublicTextLog(false)
+	defer SetPublicTextLog(true)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 200; j++ {
+		req, _ := http.NewRequest("GET", "http://www.deal.com/items/"+strconv.Itoa(j), nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		Public(*req, 200+j%2, j, time.Duration(j)*time.Microsecond)
+	}
+
+	Stop()
+
+	if readFile("./access.log", t) != "" {
+		fmt.Println("Text access log written")
+		t.Fail()
+	}
+
+	segments, _ := captureSegments(folder)
+
+	if len(segments) != 3 {
+		fmt.Println("Unexpected segments", segments)
+		t.FailNow()
+	}
+
+	// Record truncated by a crash
+	f, _ := os.OpenFile(folder+"/"+capturePrefix+strconv.Itoa(segments[2])+captureExtension, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write([]byte{captureEntry, 4})
+	f.Close()
+
+	r, err := OpenCaptureReader(folder)
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	defer r.Close()
+
+	var entries []AccessEntry
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		entries = append(entries, e)
+	}
+
+	last := entries[len(entries)-1]
+
+	if len(entries) < 10 || len(entries) >= 200 || last.URL != "http://www.deal.com/items/199" || last.Status != 201 ||
+		last.ContentLength != 199 || last.Duration != 199*time.Microsecond || last.RemoteAddr != "203.0.113.7:4000" ||
+		last.UserAgent != "Mozilla/5.0" || last.SampleRate != 1 || time.Since(last.Time) > time.Minute {
+		fmt.Printf("Unexpected captured entries %d %+v\n", len(entries), last)
+		t.Fail()
+	}
+
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.Before(entries[i-1].Time) {
+			fmt.Println("Entries out of order")
+			t.Fail()
+			break
+		}
+	}
+}
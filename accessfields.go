@@ -0,0 +1,109 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var negotiationFields = false
+var throughputMinBytes = 0 // Smallest body with a throughput field, 0 to disable
+var rangeField = false
+
+// Adds the accept and content_type headers of the request and the content_encoding header of the
+// response (requests logged by the Middleware only) to the public access log entries, to diagnose
+// client compatibility and compression issues. Absent headers are omitted.
+func SetContentNegotiationFields(enabled bool) {
+	negotiationFields = enabled
+}
+
+// Adds the effective throughput in bytes per second (body size / request duration) to the public
+// access log entries of the responses (download_bytes_per_sec) and requests (upload_bytes_per_sec)
+// with a body of at least minBytes, to tell slow client links from server slowness. 0 disables it.
+func SetThroughputField(minBytes int) {
+	throughputMinBytes = minBytes
+}
+
+// Adds the Range header of the requests to the public access log entries, e.g. range="bytes=0-1023",
+// aligned with the CDN logs.
+func SetRangeField(enabled bool) {
+	rangeField = enabled
+}
+
+// Sets the cache status of the request carried by the context (logged by the Middleware), e.g.
+// HIT, MISS or STALE, logged as cache_status in its public access log entry.
+func SetCacheStatus(ctx context.Context, status string) {
+	if scope := scopeFrom(ctx); scope != nil {
+		scope.cacheStatus.Store(status)
+	}
+}
+
+// Returns the optional fields of the access log entry of the request.
+func accessFields(r *http.Request, contentLength int, d time.Duration) (fields []Field) {
+
+	if negotiationFields {
+		fields = appendHeaderField(fields, "accept", r.Header.Get("Accept"))
+		fields = appendHeaderField(fields, "content_type", r.Header.Get("Content-Type"))
+		if scope := scopeFrom(r.Context()); scope != nil && scope.response != nil {
+			fields = appendHeaderField(fields, "content_encoding", scope.response.Get("Content-Encoding"))
+		}
+	}
+
+	if rangeField {
+		fields = appendHeaderField(fields, "range", r.Header.Get("Range"))
+	}
+
+	if scope := scopeFrom(r.Context()); scope != nil {
+		if status, ok := scope.cacheStatus.Load().(string); ok {
+			fields = appendHeaderField(fields, "cache_status", status)
+		}
+	}
+
+	if min := throughputMinBytes; min > 0 && d > 0 {
+		if contentLength >= min {
+			fields = append(fields, Field{Key: "download_bytes_per_sec", Value: throughput(int64(contentLength), d)})
+		}
+		if r.ContentLength >= int64(min) {
+			fields = append(fields, Field{Key: "upload_bytes_per_sec", Value: throughput(r.ContentLength, d)})
+		}
+	}
+
+	return fields
+}
+
+func throughput(bytes int64, d time.Duration) int64 {
+	return int64(float64(bytes) / d.Seconds())
+}
+
+func appendHeaderField(fields []Field, key string, value string) []Field {
+
+	if value == "" {
+		return fields
+	}
+
+	return append(fields, Field{Key: key, Value: value})
+}
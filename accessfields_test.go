@@ -0,0 +1,144 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentNegotiationFields(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	SetContentNegotiationFields(true)
+	defer SetContentNegotiationFields(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("compressed"))
+	}))
+
+	req := httptest.NewRequest("POST", "http://www.deal.com/orders", nil)
+	req.Header.Set("Accept", "application/json, */*")
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	Stop()
+
+	if !fileContains("./access.log", `accept="application/json, */*" content_type=application/json content_encoding=gzip`, t) {
+		fmt.Println("Missing content negotiation fields", readFile("./access.log", t))
+		t.Fail()
+	}
+}
+
+func TestThroughputField(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	SetThroughputField(1000)
+	defer SetThroughputField(0)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	download, _ := http.NewRequest("GET", "http://www.deal.com/video", nil)
+	Public(*download, 200, 5000000, 2*time.Second)
+
+	upload, _ := http.NewRequest("PUT", "http://www.deal.com/upload", strings.NewReader(strings.Repeat("x", 3000)))
+	Public(*upload, 201, 10, 500*time.Millisecond)
+
+	Stop()
+
+	if !fileContains("./access.log", "/video HTTP/1.1", t) || !fileContains("./access.log", "download_bytes_per_sec=2500000", t) ||
+		!fileContains("./access.log", "upload_bytes_per_sec=6000", t) || strings.Count(readFile("./access.log", t), "bytes_per_sec") != 2 {
+		fmt.Println("Unexpected throughput fields", readFile("./access.log", t))
+		t.Fail()
+	}
+}
+
+func TestRangeAndCacheStatus(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	SetRangeField(true)
+	defer SetRangeField(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			SetCacheStatus(r.Context(), "HIT")
+		}
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+
+	req := httptest.NewRequest("GET", "http://www.deal.com/cached", nil)
+	req.Header.Set("Range", "bytes=0-1023")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://www.deal.com/other", nil))
+
+	Stop()
+
+	text := readFile("./access.log", t)
+
+	if !strings.Contains(text, `range="bytes=0-1023" cache_status=HIT`) || strings.Count(text, "range=") != 1 ||
+		strings.Count(text, "cache_status=") != 1 {
+		fmt.Println("Unexpected range and cache status fields", text)
+		t.Fail()
+	}
+}
@@ -0,0 +1,257 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxTailLines = 10000
+
+// Returns an http handler serving the last entries of the app log as text, filtered by the query
+// parameters lines (default 100), level (e.g. WARN), grep (substring) and since (RFC 3339 time
+// or duration, e.g. 10m). It exposes the logs and must only be served on an admin port.
+func TailHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		query := r.URL.Query()
+
+		lines := 100
+		if s := query.Get("lines"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid lines ["+s+"]", http.StatusBadRequest)
+				return
+			}
+			lines = n
+		}
+		if lines > maxTailLines {
+			lines = maxTailLines
+		}
+
+		opts := ReaderOptions{}
+
+		if s := query.Get("level"); s != "" {
+			level, ok := parseLevel(s)
+			if !ok {
+				http.Error(w, "invalid level ["+s+"]", http.StatusBadRequest)
+				return
+			}
+			opts.MinLevel = level
+		}
+
+		if s := query.Get("since"); s != "" {
+			since, ok := parseSince(s)
+			if !ok {
+				http.Error(w, "invalid since ["+s+"]", http.StatusBadRequest)
+				return
+			}
+			opts.Since = since
+		}
+
+		grep := query.Get("grep")
+
+		appChannel.lock.RLock()
+		folder, name := appChannel.folder, appChannel.name
+		appChannel.lock.RUnlock()
+
+		tail, err := tailEntries(folder, name, opts, grep, lines)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		for _, entry := range tail {
+			io.WriteString(w, entry)
+		}
+	})
+}
+
+// Returns the last n entries of the app log name matching the filters, oldest first. The files
+// are read from the current one back, so that the older archives are only opened if needed.
+func tailEntries(folder string, name string, opts ReaderOptions, grep string, n int) ([]string, error) {
+
+	paths, err := logFiles(folder, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var tail []string
+
+	for i := len(paths) - 1; i >= 0 && len(tail) < n; i-- {
+
+		if !opts.Since.IsZero() {
+			// Files last modified before the time range only contain older entries
+			if info, err := os.Stat(paths[i]); err == nil && info.ModTime().Before(opts.Since) {
+				break
+			}
+		}
+
+		reader := &LogReader{lines: &lineReader{paths: paths[i : i+1]}, opts: opts}
+
+		// Ring buffer of the last matching entries of the file
+		keep := n - len(tail)
+		entries := make([]string, 0, keep)
+		next := 0
+
+		for {
+			e, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if _, invalid := err.(*invalidLineError); invalid {
+				continue
+			}
+			if err != nil {
+				break // Unreadable file, e.g. a line over the size limit or a truncated archive
+			}
+			if grep != "" && !strings.Contains(e.String(), grep) {
+				continue
+			}
+			if len(entries) < keep {
+				entries = append(entries, e.String())
+			} else {
+				entries[next] = e.String()
+				next = (next + 1) % keep
+			}
+		}
+
+		reader.Close()
+
+		tail = append(append(entries[next:len(entries):len(entries)], entries[:next]...), tail...)
+	}
+
+	return tail, nil
+}
+
+// Returns an http handler streaming the new app log entries as Server-Sent Events (one entry per
+// event), filtered by the query parameters level (e.g. WARN) and grep (substring). It exposes
+// the logs and must only be served on an admin port.
+func LiveTailHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+
+		minLevel := DEBUG
+		if s := query.Get("level"); s != "" {
+			level, ok := parseLevel(s)
+			if !ok {
+				http.Error(w, "invalid level ["+s+"]", http.StatusBadRequest)
+				return
+			}
+			minLevel = level
+		}
+
+		grep := query.Get("grep")
+
+		entries, cancel := subscribe(func(e *Entry) bool {
+			return atLeast(e.Level, minLevel) && (grep == "" || strings.Contains(e.String(), grep))
+		}, 100)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-entries:
+				for _, line := range strings.Split(strings.TrimRight(e.String(), "\n"), "\n") {
+					io.WriteString(w, "data: "+line+"\n")
+				}
+				io.WriteString(w, "\n")
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// Parses a level name (e.g. WARN) or number.
+func parseLevel(s string) (int, bool) {
+
+	for level, name := range levelNames() {
+		if strings.EqualFold(name, s) {
+			return level, true
+		}
+	}
+
+	level, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+
+	return level, isLevel(level)
+}
+
+// Parses an RFC 3339 time, or a duration before now.
+func parseSince(s string) (time.Time, bool) {
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), true
+	}
+
+	return time.Time{}, false
+}
+
+// Returns an http handler listing the named loggers with their effective level, and the logger
+// it is inherited from, as text. It must only be served on an admin port.
+func LevelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		io.WriteString(w, "* "+levelName(appLogLevel())+"\n")
+
+		for _, l := range LevelTree() {
+			line := l.Name + " " + levelName(l.Level)
+			if l.From == "" {
+				line += " (inherited from *)"
+			} else if !l.Override {
+				line += " (inherited from " + l.From + ")"
+			}
+			io.WriteString(w, line+"\n")
+		}
+	})
+}
@@ -0,0 +1,233 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailHandler(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 10; j++ {
+		Info("info", j)
+		Error("error", j)
+	}
+
+	Stop()
+
+	res := httptest.NewRecorder()
+	TailHandler().ServeHTTP(res, httptest.NewRequest("GET", "/logs?lines=3&level=error&grep=error&since=1h", nil))
+
+	body := res.Body.String()
+
+	if res.Code != 200 || strings.Count(body, "\n") != 3 || strings.Contains(body, "INFO") || !strings.Contains(body, "[error 9]") {
+		fmt.Println("Unexpected tail", res.Code, body)
+		t.Fail()
+	}
+
+	res = httptest.NewRecorder()
+	TailHandler().ServeHTTP(res, httptest.NewRequest("GET", "/logs?level=LOUD", nil))
+
+	if res.Code != 400 {
+		t.Fail()
+	}
+}
+
+func TestLiveTailHandler(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	server := httptest.NewServer(LiveTailHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", server.URL+"?level=WARN", nil)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	defer res.Body.Close()
+
+	Info("live info")
+	Warn("live warning")
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+
+	if err != nil || !strings.HasPrefix(line, "data: ") || !strings.Contains(line, "[live warning]") {
+		fmt.Println("Unexpected event", line, err)
+		t.Fail()
+	}
+}
+
+func TestTailHandlerArchives(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetAppLogMaxSize(1)
+	defer SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := Start(); err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 100; j++ {
+		Info("entry", j)
+	}
+
+	Stop()
+
+	if paths, _ := logFiles(".", appChannel.name); len(paths) < 3 {
+		fmt.Println("Expected archives", paths)
+		t.Fail()
+	}
+
+	entries, err := tailEntries(".", appChannel.name, ReaderOptions{MinLevel: DEBUG}, "", 40)
+
+	if err != nil || len(entries) != 40 {
+		fmt.Println("Unexpected tail", err, len(entries))
+		t.FailNow()
+	}
+
+	for i, entry := range entries {
+		if !strings.Contains(entry, fmt.Sprintf("[entry %d]", 60+i)) {
+			fmt.Println("Tail out of order", i, entry)
+			t.Fail()
+		}
+	}
+}
+
+// Returns the tail of the app log, failing the test if it doesn't return in time.
+func tailWithin(t *testing.T, n int) []string {
+
+	result := make(chan []string, 1)
+
+	go func() {
+		entries, err := tailEntries(".", appChannel.name, ReaderOptions{MinLevel: DEBUG}, "", n)
+		if err != nil {
+			fmt.Println(err)
+			t.Fail()
+		}
+		result <- entries
+	}()
+
+	select {
+	case entries := <-result:
+		return entries
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tail not returning")
+		return nil
+	}
+}
+
+func TestTailUnreadableFiles(t *testing.T) {
+	removeLogFiles(".")
+	defer removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+	Info("before")
+	Stop()
+
+	// Truncated compressed archive, read when the current file has too few entries
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for j := 0; j < 1000; j++ {
+		fmt.Fprintf(gz, "2020-01-01 10:00:00 INFO [archived %d]\n", j)
+	}
+	gz.Close()
+	ioutil.WriteFile("./2020-01-01-1-"+appChannel.name+".gz", buf.Bytes()[:buf.Len()/2], 0644)
+
+	// The entries before the truncation are still read
+	if entries := tailWithin(t, 10); len(entries) != 10 || !strings.Contains(entries[9], "[before]") || !strings.Contains(entries[8], "[archived ") {
+		fmt.Println("Unexpected tail with a truncated archive", entries)
+		t.Fail()
+	}
+
+	// Line over the size limit of the reader
+	f, err := os.OpenFile("./"+appChannel.name, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(bytes.Repeat([]byte("x"), 17*1024*1024))
+	f.WriteString("\n")
+	f.Close()
+
+	if entries := tailWithin(t, 10); len(entries) != 10 || !strings.Contains(entries[9], "[before]") {
+		fmt.Println("Unexpected tail with an oversized line", len(entries))
+		t.Fail()
+	}
+}
@@ -0,0 +1,137 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var anonymization = false
+var anonymizedFields = map[string]bool{}
+var saltRotation = 24 * time.Hour
+var salt []byte
+var saltExpiry time.Time
+var anonymizationLock = sync.Mutex{}
+
+// Anonymizes the public access log entries before they are written (GDPR data minimization): the
+// last octet of the IPv4 client addresses and the last 80 bits of the IPv6 ones are zeroed, and the
+// values of the user fields (e.g. added by a public hook) are replaced by a keyed hash. The secret
+// salt of the hash is random and replaced every rotation (default 24h), so that the hashes
+// correlate the requests of a user within a period only.
+func SetAccessAnonymization(enabled bool, rotation time.Duration, userFields ...string) {
+
+	anonymizationLock.Lock()
+	defer anonymizationLock.Unlock()
+
+	anonymization = enabled
+	anonymizedFields = map[string]bool{}
+	for _, key := range userFields {
+		anonymizedFields[key] = true
+	}
+
+	if rotation <= 0 {
+		rotation = 24 * time.Hour
+	}
+	saltRotation = rotation
+	saltExpiry = time.Time{} // New salt on the next entry
+}
+
+// Anonymizes the client address and the user fields of the entry if the anonymization is enabled.
+func anonymizeAccessEntry(e *AccessEntry) {
+
+	anonymizationLock.Lock()
+	defer anonymizationLock.Unlock()
+
+	if !anonymization {
+		return
+	}
+
+	e.RemoteAddr = anonymizeAddrs(e.RemoteAddr)
+
+	for i, f := range e.Fields {
+		if anonymizedFields[f.Key] {
+			e.Fields[i].Value = hashUser(fmt.Sprint(f.Value))
+		}
+	}
+}
+
+// Anonymizes the addresses of a remote address or of a X-Forwarded-For list.
+func anonymizeAddrs(addrs string) string {
+
+	parts := strings.Split(addrs, ",")
+
+	for i, addr := range parts {
+		parts[i] = anonymizeIP(strings.TrimSpace(addr))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Returns the IP address with its host part zeroed, without the port. Other strings are
+// returned hashed, as they may identify the client.
+func anonymizeIP(addr string) string {
+
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		if addr == "" {
+			return ""
+		}
+		return hashUser(addr)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// Returns the keyed hash of the user identifier with the current salt. The lock must be held.
+func hashUser(id string) string {
+
+	if now := time.Now(); now.After(saltExpiry) {
+		salt = make([]byte, 32)
+		rand.Read(salt)
+		saltExpiry = now.Add(saltRotation)
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(id))
+
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -0,0 +1,99 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+
+	for addr, expected := range map[string]string{
+		"192.168.1.42":                  "192.168.1.0",
+		"192.168.1.42:54321":            "192.168.1.0",
+		"[2001:db8:85a3:1:2:3:4:5]:443": "2001:db8:85a3::",
+		"2001:db8:85a3:1:2:3:4:5":       "2001:db8:85a3::",
+		"10.0.0.7, 172.16.4.9":          "10.0.0.0, 172.16.4.0",
+		"":                              "",
+	} {
+		if anonymized := anonymizeAddrs(addr); anonymized != expected {
+			fmt.Printf("%q anonymized as %q instead of %q\n", addr, anonymized, expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestAccessAnonymization(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetAccessAnonymization(true, time.Hour, "user")
+	defer SetAccessAnonymization(false, 0)
+
+	AddPublicHook(func(e *AccessEntry) bool {
+		e.Fields = append(e.Fields, Field{Key: "user", Value: "alice@example.com"})
+		return true
+	})
+	defer ClearPublicHooks()
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		Public(http.Request{Method: "GET", URL: &url.URL{Path: "/"}, RemoteAddr: "203.0.113.77:40000", Header: http.Header{}}, 200, 10, time.Millisecond)
+	}
+
+	Stop()
+
+	text := readFile("./access.log", t)
+
+	if fileContains("./access.log", "203.0.113.77", t) || !fileContains("./access.log", "from [203.0.113.0]", t) {
+		fmt.Println("Client address not anonymized", text)
+		t.Fail()
+	}
+
+	if fileContains("./access.log", "alice", t) {
+		fmt.Println("User not hashed", text)
+		t.Fail()
+	}
+
+	hashes := regexp.MustCompile(`user=(\w+)`).FindAllStringSubmatch(text, -1)
+
+	if len(hashes) != 2 || hashes[0][1] != hashes[1][1] {
+		fmt.Println("Hashes differ within a salt period", hashes)
+		t.Fail()
+	}
+}
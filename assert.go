@@ -0,0 +1,99 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"io"
+)
+
+// Logs at ERROR level if the condition is true. Returns the condition, e.g.
+// if gol.ErrorIf(err != nil, "saving user", err) { return }
+func ErrorIf(cond bool, v ...interface{}) bool {
+	if cond {
+		appLog(ERROR, appLogLevel(), nil, v)
+	}
+	return cond
+}
+
+// Logs at WARN level if the condition is true. Returns the condition.
+func WarnIf(cond bool, v ...interface{}) bool {
+	if cond {
+		appLog(WARN, appLogLevel(), nil, v)
+	}
+	return cond
+}
+
+// Logs the message and the error at ERROR level if the error is not nil. Returns true if it is not nil.
+func ErrorIfErr(err error, v ...interface{}) bool {
+	if err != nil {
+		appLog(ERROR, appLogLevel(), nil, append(v, err))
+	}
+	return err != nil
+}
+
+// Logs the message and the error at ERROR level unless the error is nil or is one of the
+// expected errors (see errors.Is), e.g. io.EOF or context.Canceled. Returns true if it is not nil.
+func ErrorIfNotIs(err error, expected []error, v ...interface{}) bool {
+
+	if err == nil {
+		return false
+	}
+
+	for _, e := range expected {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	appLog(ERROR, appLogLevel(), nil, append(v, err))
+
+	return true
+}
+
+// Logs the message and the error at WARN level if the error matches the target (see errors.As),
+// which is then set. Returns true if it matches, e.g. gol.WarnIfAs(err, &pathErr, "reading")
+func WarnIfAs(err error, target interface{}, v ...interface{}) bool {
+
+	if err == nil || !errors.As(err, target) {
+		return false
+	}
+
+	appLog(WARN, appLogLevel(), nil, append(v, err))
+
+	return true
+}
+
+// Closes the closer and logs the error at ERROR level if it fails, e.g. defer gol.LogClose(f, "config file")
+func LogClose(c io.Closer, what string) {
+
+	if c == nil {
+		return
+	}
+
+	if err := c.Close(); err != nil {
+		appLog(ERROR, appLogLevel(), nil, []interface{}{"closing " + what, err})
+	}
+}
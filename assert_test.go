@@ -0,0 +1,112 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConditionalLogging(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	wrapped := fmt.Errorf("reading: %w", io.EOF)
+	_, pathErr := os.Open("/does/not/exist")
+
+	var target *os.PathError
+
+	if ErrorIf(false, "not logged") || !WarnIf(true, "warn if") ||
+		ErrorIfErr(nil, "not logged") || !ErrorIfErr(errors.New("boom"), "saving user") ||
+		!ErrorIfNotIs(wrapped, []error{io.EOF}, "not logged") || !ErrorIfNotIs(errors.New("bad"), []error{io.EOF}, "reading") ||
+		WarnIfAs(wrapped, &target, "not logged") || !WarnIfAs(pathErr, &target, "opening") || target == nil {
+		fmt.Println("Unexpected results")
+		t.Fail()
+	}
+
+	content := readFile("./application.log", t)
+
+	if strings.Contains(content, "not logged") || !strings.Contains(content, "WARN [warn if]") ||
+		!strings.Contains(content, "ERROR [saving user boom]") || !strings.Contains(content, "ERROR [reading bad]") ||
+		!strings.Contains(content, "WARN [opening open /does/not/exist") {
+		fmt.Println("Unexpected entries", content)
+		t.Fail()
+	}
+}
+
+type failingCloser struct{}
+
+func (failingCloser) Close() error {
+	return errors.New("disk full")
+}
+
+func TestLogClose(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	func() {
+		defer LogClose(failingCloser{}, "config file")
+		defer LogClose(io.NopCloser(nil), "not logged")
+	}()
+
+	content := readFile("./application.log", t)
+
+	if !strings.Contains(content, "ERROR [closing config file disk full] at ") || strings.Contains(content, "not logged") {
+		fmt.Println("Unexpected entries", content)
+		t.Fail()
+	}
+}
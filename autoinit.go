@@ -0,0 +1,124 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// Configures gol from the usual environment conventions and starts it, for apps without any
+// logging configuration code:
+//
+//   - LOG_LEVEL: app log level, debug, info, warn (or warning) or error, case insensitive
+//   - LOG_FORMAT: json or text, format of the entries printed to stdout
+//   - NO_COLOR: disables the colors (https://no-color.org), which are otherwise enabled when
+//     stdout is a terminal
+//
+// Without LOG_FORMAT, the entries are printed as JSON when running in a container (and stdout is
+// not a terminal), for the log collectors, and as text otherwise. Under systemd (JOURNAL_STREAM
+// set), the entries get the journal priority prefixes.
+func AutoInit() error {
+	applyEnvironment(os.Getenv, isTerminal(os.Stdout), inContainer())
+	return Start()
+}
+
+func applyEnvironment(getenv func(string) string, terminal bool, container bool) {
+
+	if value := getenv("LOG_LEVEL"); value != "" {
+		if level, ok := parseEnvLevel(value); ok {
+			SetAppLogLevel(level)
+		} else {
+			log.Println("ERROR - Invalid LOG_LEVEL [" + value + "]")
+		}
+	}
+
+	switch format := strings.ToLower(getenv("LOG_FORMAT")); format {
+	case TextFormat, JSONFormat:
+		SetStdoutFormat(format)
+	case "":
+		if container && !terminal {
+			SetStdoutFormat(JSONFormat)
+		} else {
+			SetStdoutFormat(TextFormat)
+		}
+	default:
+		log.Println("ERROR - Invalid LOG_FORMAT [" + format + "]")
+	}
+
+	SetStdoutColors(terminal && getenv("NO_COLOR") == "")
+
+	if getenv("JOURNAL_STREAM") != "" && !container {
+		SetJournalPrefixes(true)
+	}
+
+	LogToStdout(true)
+}
+
+func parseEnvLevel(value string) (int, bool) {
+
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "warning") {
+		value = "WARN"
+	}
+
+	level, ok := parseLevel(value)
+
+	return level, ok && level != FATAL
+}
+
+// Returns true if the file is a terminal (character device).
+func isTerminal(f *os.File) bool {
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Returns true if the app seems to run in a container (Docker, Podman, Kubernetes, containerd).
+func inContainer() bool {
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+
+	for _, f := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(f); err == nil {
+			return true
+		}
+	}
+
+	cgroup, _ := ioutil.ReadFile("/proc/1/cgroup")
+
+	for _, s := range []string{"docker", "kubepods", "containerd", "libpod"} {
+		if strings.Contains(string(cgroup), s) {
+			return true
+		}
+	}
+
+	return false
+}
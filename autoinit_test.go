@@ -0,0 +1,127 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+
+	defer func() {
+		SetAppLogLevel(INFO)
+		SetStdoutFormat(TextFormat)
+		SetStdoutColors(false)
+		SetJournalPrefixes(false)
+	}()
+
+	env := map[string]string{"LOG_LEVEL": "warning"}
+	getenv := func(key string) string { return env[key] }
+
+	applyEnvironment(getenv, false, true)
+
+	if appLogLevel() != WARN || consoleFormat() != JSONFormat || stdoutColors == 1 {
+		fmt.Println("Unexpected container settings", appLogLevel(), consoleFormat(), stdoutColors)
+		t.Fail()
+	}
+
+	env = map[string]string{"LOG_LEVEL": "Debug", "LOG_FORMAT": "TEXT", "JOURNAL_STREAM": "8:1234"}
+
+	applyEnvironment(getenv, true, true)
+
+	if appLogLevel() != DEBUG || consoleFormat() != TextFormat || stdoutColors == 0 || journalPrefixes == 1 {
+		fmt.Println("Unexpected terminal settings", appLogLevel(), consoleFormat(), stdoutColors, journalPrefixes)
+		t.Fail()
+	}
+
+	env = map[string]string{"LOG_LEVEL": "LOUD", "NO_COLOR": "1", "JOURNAL_STREAM": "8:1234"}
+
+	applyEnvironment(getenv, true, false)
+
+	if appLogLevel() != DEBUG || stdoutColors == 1 || journalPrefixes == 0 {
+		fmt.Println("Unexpected systemd settings", appLogLevel(), stdoutColors, journalPrefixes)
+		t.Fail()
+	}
+}
+
+func TestStdoutFormats(t *testing.T) {
+
+	defer SetStdoutFormat(TextFormat)
+	defer SetStdoutColors(false)
+
+	e := &Entry{
+		Time:    time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC),
+		Level:   WARN,
+		Message: `disk "almost" full`,
+		Fields:  []Field{{Key: "free", Value: 12}, {Key: "err", Value: errors.New("EIO")}, JSON("ids", []int{1, 2})},
+		File:    "main.go",
+		Line:    42,
+		text:    "2017-03-01 10:00:00 WARN [disk full]\n",
+	}
+
+	SetStdoutColors(true)
+
+	if text := stdoutText(e); !strings.Contains(text, "\x1b[33mWARN\x1b[0m [disk full]") {
+		fmt.Printf("Unexpected colored entry %q\n", text)
+		t.Fail()
+	}
+
+	SetShortLevels(true)
+	defer SetShortLevels(false)
+
+	if text := stdoutText(e); !strings.Contains(text, "\x1b[33mWRN\x1b[0m [disk full]") {
+		fmt.Printf("Unexpected short level entry %q\n", text)
+		t.Fail()
+	}
+
+	SetStdoutColors(false)
+
+	if shortLevelName(DEBUG) != "DBG" || !strings.Contains(stdoutText(e), " WRN [disk full]") {
+		fmt.Printf("Unexpected short level %q\n", stdoutText(e))
+		t.Fail()
+	}
+
+	SetStdoutFormat(JSONFormat)
+
+	var decoded map[string]interface{}
+	text := stdoutText(e)
+
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil || !strings.HasSuffix(text, "}\n") {
+		fmt.Println("Invalid JSON entry", text, err)
+		t.FailNow()
+	}
+
+	if decoded["level"] != "WARN" || decoded["msg"] != `disk "almost" full` || decoded["free"] != 12.0 ||
+		decoded["err"] != "EIO" || fmt.Sprint(decoded["ids"]) != "[1 2]" || decoded["caller"] != "main.go:42" ||
+		decoded["time"] != "2017-03-01T10:00:00.000Z" {
+		fmt.Println("Unexpected JSON entry", text)
+		t.Fail()
+	}
+}
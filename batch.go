@@ -0,0 +1,105 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+const maxBatchEntries = 256     // Max entries written with one write
+const maxBatchBytes = 64 * 1024 // Max bytes written with one write
+
+var orderedWrites int32 // 1 if a single routine writes the app log entries
+
+// Writes the app log entries with a single routine, in the order of the log calls, instead of
+// NUM_LOGGING_ROUTINES concurrent ones. The entries queued when the routine wakes up are written
+// in the app log file with one write call. Applied by the next Start.
+func SetOrderedWrites(enabled bool) {
+	atomic.StoreInt32(&orderedWrites, toggle(enabled))
+}
+
+// Writes the app log entries of the channel in batches.
+func appLogBatchWrite(appDataChannel chan *Entry) {
+
+	defer wg.Done()
+
+	batch := make([]*Entry, 0, maxBatchEntries)
+	buf := make([]byte, 0, maxBatchBytes)
+
+	for e := range appDataChannel {
+		batch = append(batch[:0], e)
+		size := len(e.String())
+
+		// Takes the entries already queued, without waiting
+	queued:
+		for len(batch) < maxBatchEntries && size < maxBatchBytes {
+			select {
+			case next, more := <-appDataChannel:
+				if !more {
+					break queued
+				}
+				batch = append(batch, next)
+				size += len(next.String())
+			default:
+				break queued
+			}
+		}
+
+		buf = buf[:0]
+		for _, e := range batch {
+			if e.group != nil {
+				// Groups are written at once, flushing the batch first to keep the order
+				if len(buf) > 0 {
+					appChannel.write(buf)
+					buf = buf[:0]
+				}
+				doGroupWrite(e.group)
+				continue
+			}
+			if e.toFile && routeFor(e) == nil {
+				if !budget.take(len(e.String()), e.budgetLevel()) {
+					e.toFile = false
+					continue
+				}
+				buf = append(buf, e.String()...)
+				e.written = true
+			}
+		}
+
+		if len(buf) > 0 {
+			appChannel.write(buf)
+		}
+
+		for _, e := range batch {
+			if e.group != nil {
+				continue
+			}
+			if err := doAppLogWrite(e); err != nil {
+				log.Println("Unable to log message ["+e.String()+"]", err)
+			}
+		}
+	}
+}
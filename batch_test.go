@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestOrderedWrites(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetOrderedWrites(true)
+	defer SetOrderedWrites(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for i := 0; i < 1000; i++ {
+		Info("ordered " + strconv.Itoa(i))
+	}
+
+	Stop()
+
+	lines := strings.Split(strings.TrimSpace(readFile("./application.log", t)), "\n")
+
+	if len(lines) != 1000 {
+		fmt.Println("Unexpected number of entries", len(lines))
+		t.FailNow()
+	}
+
+	for i, line := range lines {
+		if !strings.Contains(line, "[ordered "+strconv.Itoa(i)+"]") {
+			fmt.Println("Entry out of order", i, line)
+			t.FailNow()
+		}
+	}
+
+	if Stats().Entries["INFO"] != 1000 {
+		fmt.Println("Unexpected stats", Stats().Entries)
+		t.Fail()
+	}
+}
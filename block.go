@@ -0,0 +1,97 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// BlockWriter collects a multi-line block (e.g. a config dump or a table) logged as a single
+// entry on Close, see Block.
+type BlockWriter struct {
+	lock   sync.Mutex
+	level  int
+	title  string
+	buf    bytes.Buffer
+	closed bool
+}
+
+var errBlockClosed = errors.New("gol block already closed")
+
+// Indentation of the lines of a block, which can't be taken for the start of an entry
+const blockIndent = "    "
+
+// Returns a writer whose content is logged on Close as one entry at the level: the title then
+// the written lines, indented, never interleaved with the entries of other goroutines. E.g.
+//
+//	b := gol.Block(gol.INFO, "configuration")
+//	fmt.Fprintf(b, "%-10s %s\n", "folder", folder)
+//	b.Close()
+func Block(level int, title string) *BlockWriter {
+	return &BlockWriter{level: level, title: title}
+}
+
+func (b *BlockWriter) Write(p []byte) (int, error) {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return 0, errBlockClosed
+	}
+
+	return b.buf.Write(p)
+}
+
+// Logs the block.
+func (b *BlockWriter) Close() error {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return errBlockClosed
+	}
+	b.closed = true
+
+	if !isLevel(b.level) || b.level == FATAL {
+		return nil
+	}
+
+	message := b.title
+
+	if content := strings.TrimRight(b.buf.String(), "\r\n"); content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			message += "\n" + blockIndent + strings.TrimRight(line, "\r")
+		}
+	}
+
+	appLogSkip(b.level, appLogLevel(), nil, []interface{}{message}, 4)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"text/tabwriter"
+)
+
+func TestBlock(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := Block(INFO, "configuration")
+	w := tabwriter.NewWriter(b, 0, 4, 1, ' ', 0)
+	fmt.Fprintln(w, "folder\t/var/log")
+	fmt.Fprintln(w, "max_size\t1024")
+	w.Flush()
+
+	Info("not in the block")
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.Write([]byte("late")); err == nil {
+		fmt.Println("Write accepted after Close")
+		t.Fail()
+	}
+
+	Stop()
+
+	text := readFile("./application.log", t)
+	block := "INFO [configuration\n    folder   /var/log\n    max_size 1024] at "
+
+	if !fileContains("./application.log", block, t) {
+		fmt.Println("Block not written", text)
+		t.Fail()
+	}
+
+	f, _ := os.Open("./application.log")
+	defer f.Close()
+
+	r := NewLogReader(f, ReaderOptions{})
+	r.Next() // Entry logged before the block
+
+	if e, err := r.Next(); err != nil || e.Message != "configuration\n    folder   /var/log\n    max_size 1024" {
+		fmt.Printf("Block not read back %q %v\n", e.Message, err)
+		t.Fail()
+	}
+}
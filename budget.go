@@ -0,0 +1,83 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var requestBudgetEntries int64
+var requestBudgetBytes int64
+
+// Caps the app log entries a request may log through the *Context functions of the Middleware,
+// in number of entries and in bytes of their messages, 0 for no limit. Beyond the budget the
+// entries are dropped, a single WARN entry marking the request. Protects the logs from
+// floods triggered by malicious input.
+func SetRequestBudget(entries int64, bytes int64) {
+	atomic.StoreInt64(&requestBudgetEntries, entries)
+	atomic.StoreInt64(&requestBudgetBytes, bytes)
+}
+
+// Logs the entry of the request carried by the context, within its budget.
+func contextLog(ctx context.Context, level int, v []interface{}) {
+
+	minLevel := appLogLevelFor(ctx)
+
+	if scope := scopeFrom(ctx); scope != nil && atLeast(level, minLevel) && !scope.spend(v) {
+		return
+	}
+
+	appLogSkip(level, minLevel, contextFields(ctx), v, 4)
+}
+
+// Counts the entry in the budget of the request, returns false if it exceeds the budget.
+func (s *requestScope) spend(v []interface{}) bool {
+
+	maxEntries := atomic.LoadInt64(&requestBudgetEntries)
+	maxBytes := atomic.LoadInt64(&requestBudgetBytes)
+
+	if maxEntries <= 0 && maxBytes <= 0 {
+		return true
+	}
+
+	entries := atomic.AddInt64(&s.entries, 1)
+
+	var bytes int64
+	if maxBytes > 0 {
+		bytes = atomic.AddInt64(&s.bytes, int64(len(formatMessage(v))))
+	}
+
+	if (maxEntries <= 0 || entries <= maxEntries) && (maxBytes <= 0 || bytes <= maxBytes) {
+		return true
+	}
+
+	if atomic.CompareAndSwapInt32(&s.overBudget, 0, 1) {
+		fields := []Field{{Key: "request_id", Value: s.id}, {Key: "max_entries", Value: maxEntries}, {Key: "max_bytes", Value: maxBytes}}
+		appLog(WARN, appLogLevel(), fields, []interface{}{"request log budget exceeded, next entries dropped"})
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestBudget(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetRequestBudget(3, 0)
+	defer SetRequestBudget(0, 0)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 10; i++ {
+			InfoContext(r.Context(), "flood", i)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/b", nil))
+
+	Stop()
+
+	text := readFile("./application.log", t)
+
+	if n := strings.Count(text, "[flood"); n != 6 {
+		fmt.Println("Unexpected number of entries within the budgets of 2 requests", n)
+		t.Fail()
+	}
+
+	if n := strings.Count(text, "request log budget exceeded"); n != 2 {
+		fmt.Println("Unexpected number of budget markers", n)
+		t.Fail()
+	}
+
+	if !strings.Contains(text, "[flood 2] request_id=") || strings.Contains(text, "[flood 3]") {
+		fmt.Println("Unexpected entries kept")
+		t.Fail()
+	}
+
+	// Caller of the context function reported
+	if !strings.Contains(text, "budget_test.go") {
+		fmt.Println("Caller not reported")
+		t.Fail()
+	}
+}
+
+func TestRequestBudgetBytes(t *testing.T) {
+
+	SetRequestBudget(0, 10)
+	defer SetRequestBudget(0, 0)
+
+	s := &requestScope{}
+
+	if !s.spend([]interface{}{"12345"}) || !s.spend([]interface{}{"12345"}) || s.spend([]interface{}{"1"}) {
+		fmt.Println("Bytes budget not enforced")
+		t.Fail()
+	}
+}
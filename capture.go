@@ -0,0 +1,252 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var captureMaxBytes = 0 // Maximum number of body bytes captured by the middleware, 0 to disable
+var captureContentTypes = []string{"application/json", "application/x-www-form-urlencoded", "text/"}
+var captureRedactions []*regexp.Regexp
+var captureLock = sync.RWMutex{}
+
+// Makes the middleware log up to maxBytes of the request and response bodies as DEBUG entries,
+// for the content types starting with one of the prefixes (JSON, forms and text if none given).
+// A maxBytes of 0 disables the capture.
+func SetBodyCapture(maxBytes int, contentTypes ...string) {
+
+	captureLock.Lock()
+	defer captureLock.Unlock()
+
+	captureMaxBytes = maxBytes
+
+	if len(contentTypes) > 0 {
+		captureContentTypes = contentTypes
+	}
+}
+
+// Replaces the parts of the captured bodies matching the patterns with [REDACTED],
+// e.g. regexp.MustCompile(`"password":"[^"]*"`). A truncated body is cut before what may be the
+// start of a match, so that a secret cut by the truncation isn't logged.
+func SetBodyRedactions(patterns ...*regexp.Regexp) {
+	captureLock.Lock()
+	captureRedactions = patterns
+	captureLock.Unlock()
+}
+
+// Returns the maximum number of body bytes to capture for the request, 0 if none.
+func bodyCaptureSize(r *http.Request) int {
+
+	if rank(appLogLevelFor(r.Context())) > rank(DEBUG) {
+		return 0
+	}
+
+	captureLock.RLock()
+	defer captureLock.RUnlock()
+
+	return captureMaxBytes
+}
+
+// Logs a captured body if its content type is allowed.
+func logCapturedBody(r *http.Request, what string, contentType string, body *bytes.Buffer, length int) {
+
+	if body == nil || body.Len() == 0 {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	captureLock.RLock()
+	defer captureLock.RUnlock()
+
+	allowed := false
+	for _, prefix := range captureContentTypes {
+		if mediaType != "" && strings.HasPrefix(mediaType, prefix) {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return
+	}
+
+	captured := body.Bytes()
+	for _, pattern := range captureRedactions {
+		captured = pattern.ReplaceAll(captured, []byte("[REDACTED]"))
+	}
+
+	// A secret cut by the truncation doesn't match, what may be its start is dropped
+	if length > body.Len() {
+		for _, pattern := range captureRedactions {
+			captured = captured[:partialMatchStart(pattern, captured)]
+		}
+	}
+
+	fields := append(contextFields(r.Context()), []Field{
+		{Key: "method", Value: r.Method},
+		{Key: "url", Value: r.URL.String()},
+		{Key: "content_type", Value: mediaType},
+		{Key: "body", Value: string(captured)},
+	}...)
+
+	if length > body.Len() {
+		fields = append(fields, Field{Key: "truncated", Value: length - body.Len()})
+	}
+
+	appLog(DEBUG, appLogLevelFor(r.Context()), fields, []interface{}{what})
+}
+
+// Thread of the pattern program, started at a position of the text.
+type matchThread struct {
+	pc    uint32
+	start int
+}
+
+// Returns the start of the earliest match of the pattern which may go on past the end of the
+// text, the length of the text if none.
+func partialMatchStart(pattern *regexp.Regexp, text []byte) int {
+
+	parsed, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return len(text)
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return len(text)
+	}
+
+	seen := make([]bool, len(prog.Inst))
+
+	// Adds the thread and the threads reached from it without reading a rune
+	var add func(threads []matchThread, pc uint32, start int, context syntax.EmptyOp) []matchThread
+	add = func(threads []matchThread, pc uint32, start int, context syntax.EmptyOp) []matchThread {
+
+		if seen[pc] {
+			return threads
+		}
+		seen[pc] = true
+
+		inst := &prog.Inst[pc]
+
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			threads = add(threads, inst.Out, start, context)
+			threads = add(threads, inst.Arg, start, context)
+		case syntax.InstCapture, syntax.InstNop:
+			threads = add(threads, inst.Out, start, context)
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(inst.Arg)&^context == 0 {
+				threads = add(threads, inst.Out, start, context)
+			}
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			threads = append(threads, matchThread{pc: pc, start: start})
+		}
+
+		return threads
+	}
+
+	var pending, threads []matchThread
+	previous := rune(-1)
+
+	for pos := 0; ; {
+
+		// Past the end of the text the body goes on, any assertion may hold
+		context := ^syntax.EmptyOp(0)
+		r, size := rune(-1), 0
+
+		if pos < len(text) {
+			r, size = utf8.DecodeRune(text[pos:])
+			context = syntax.EmptyOpContext(previous, r)
+		}
+
+		// The threads started first are kept over the later ones at the same instruction
+		for i := range seen {
+			seen[i] = false
+		}
+
+		threads = threads[:0]
+		for _, t := range pending {
+			threads = add(threads, t.pc, t.start, context)
+		}
+		threads = add(threads, uint32(prog.Start), pos, context)
+
+		if pos == len(text) {
+			break
+		}
+
+		pending = pending[:0]
+		for _, t := range threads {
+			if inst := &prog.Inst[t.pc]; inst.MatchRune(r) {
+				pending = append(pending, matchThread{pc: inst.Out, start: t.start})
+			}
+		}
+
+		pos += size
+		previous = r
+	}
+
+	start := len(text)
+	for _, t := range threads {
+		if t.start < start {
+			start = t.start
+		}
+	}
+
+	return start
+}
+
+// Keeps up to max bytes of what is read from the body.
+type captureReader struct {
+	io.ReadCloser
+	buf    bytes.Buffer
+	max    int
+	length int
+}
+
+func (c *captureReader) Read(p []byte) (int, error) {
+
+	n, err := c.ReadCloser.Read(p)
+	c.length += n
+
+	if left := c.max - c.buf.Len(); left > 0 {
+		if n < left {
+			left = n
+		}
+		c.buf.Write(p[:left])
+	}
+
+	return n, err
+}
@@ -0,0 +1,455 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Log file rotated once it reaches its max size, and purged once older than its max age.
+type channel struct {
+	folder        string // Path to gol file
+	name          string
+	maxSize       int64 // in KB
+	maxAge        int   // File older than MaxAge days will be deleted automatically
+	quota         int64 // Max size of the current file and archives in KB, 0 for no quota
+	retention     Retention
+	purgePaused   bool // Legal hold, no file is deleted
+	suffix        int
+	file          *os.File
+	lock          sync.RWMutex
+	rotateCounter int32  // Writes since the last rotation check, shared by the write routines
+	rotations     int64  // Number of rotations, for the stats
+	bytes         int64  // Number of bytes written, for the stats
+	sequence      uint64 // Last sequence number stamped on an entry
+	mmap          bool   // Writes through a memory mapping, see SetMmapWrites
+	mm            *mmapAppender
+	format        string    // Format of the entries for the schema header, app if empty
+	boundary      time.Time // Next daily rotation, zero if none
+	compressing   int32     // 1 while the archives are compressed, see SetArchiveCompression
+}
+
+func (c *channel) open() (err error) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Rotation state of a previous run
+	c.suffix = 0
+	atomic.StoreInt32(&c.rotateCounter, 0)
+	c.boundary = time.Time{}
+
+	c.file, err = openLogFile(c.folder, c.name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.mapFile(); err != nil {
+		return err
+	}
+
+	c.writeHeader()
+	c.scheduleRotation()
+
+	return nil
+}
+
+// Maps the current file if the channel writes through a memory mapping.
+func (c *channel) mapFile() (err error) {
+
+	if !c.mmap {
+		c.mm = nil
+		return nil
+	}
+
+	// A shared mapping needs a file opened for reading and writing, without append
+	path := c.file.Name()
+	c.file.Close()
+
+	c.file, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	c.mm, err = openMmapAppender(c.file)
+
+	return err
+}
+
+// Closes the current file.
+func (c *channel) closeFile() error {
+
+	if c.mm != nil {
+		err := c.mm.Close()
+		c.mm = nil
+		return err
+	}
+
+	return c.file.Close()
+}
+
+// Closes the current file of the channel on Stop, truncating a memory mapped file to its data.
+func (c *channel) close() error {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.closeFile()
+	c.file = nil
+
+	return err
+}
+
+// Returns true if the file of the channel is open.
+func (c *channel) isOpen() bool {
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.file != nil
+}
+
+// Returns true if the current file reached the max size of the channel.
+func (c *channel) needRotation() bool {
+
+	if c.rotationDue() || chaosRotates() {
+		return true
+	}
+
+	if c.mm != nil {
+		return c.mm.size() > c.maxSize*1024
+	}
+
+	return needRotation(c.file, c.maxSize)
+}
+
+// Returns the folder of the channel, which may be changed meanwhile.
+func (c *channel) logFolder() string {
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.folder
+}
+
+// Applies a setting to the channel under its lock, as it may be written meanwhile.
+func (c *channel) update(set func()) {
+	c.lock.Lock()
+	set()
+	c.lock.Unlock()
+}
+
+// Writes the message, rotating the file first if it reached its max size.
+func (c *channel) write(msg []byte) {
+
+	if chaosWriteFails() {
+		return
+	}
+
+	if atomic.LoadInt32(&discardWrites) == 1 {
+		atomic.AddInt64(&c.bytes, int64(len(msg)))
+		return
+	}
+
+	if atomic.AddInt32(&c.rotateCounter, 1) <= 10 {
+		atomic.StoreInt32(&c.rotateCounter, 0)
+		rotated := false
+		c.lock.Lock()
+		if c.file != nil && c.needRotation() {
+			c.closeFile()
+			newLogFile, err := rotate(c.folder, c.name, &c.suffix, c.archiveDate())
+			if err != nil {
+				log.Println("ERROR - Rotation required and unable to create file ", err)
+			} else {
+				c.file = newLogFile
+				rotated = true
+				atomic.AddInt64(&c.rotations, 1)
+				if err := c.mapFile(); err != nil {
+					log.Println("ERROR - Unable to map file ", err)
+				}
+				c.writeHeader()
+				c.scheduleRotation()
+			}
+		}
+		c.lock.Unlock()
+
+		if rotated {
+			c.enforceQuota()
+			if !c.compressArchives() {
+				c.updateManifest()
+			}
+		}
+	}
+
+	var n int
+
+	c.lock.RLock()
+	if c.mm != nil {
+		n, _ = c.mm.Write(msg)
+	} else if c.file != nil {
+		n, _ = c.file.Write(msg)
+	}
+	c.lock.RUnlock()
+
+	atomic.AddInt64(&c.bytes, int64(n))
+}
+
+// Switches the writes to the folder. The current file is archived into the new folder if
+// moveCurrent is true, otherwise it is left in the old folder. Nothing is done if the folder
+// is the current one.
+func (c *channel) move(folder string, moveCurrent bool) error {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if sameFolder(c.folder, folder) {
+		return nil
+	}
+
+	if c.file == nil {
+		c.folder = folder
+		return nil
+	}
+
+	newLogFile, err := openLogFile(folder, c.name)
+	if err != nil {
+		return err
+	}
+
+	oldFilePath := c.folder + "/" + c.name
+
+	c.closeFile()
+	c.file = newLogFile
+	c.folder = folder
+
+	if err := c.mapFile(); err != nil {
+		return err
+	}
+
+	c.writeHeader()
+	c.scheduleRotation()
+
+	if !moveCurrent {
+		return nil
+	}
+
+	archiveFilePath, err := archivePath(folder, c.name, &c.suffix, c.archiveDate())
+	if err != nil {
+		return err
+	}
+
+	return moveFile(oldFilePath, archiveFilePath)
+}
+
+// Moves the current log files of the app, public access and error logs to a new folder, without
+// losing any entry. The current files are archived into the new folder if moveCurrent is true,
+// otherwise they are left in the old folders. Older archives are left in the old folders.
+func MoveLogFolder(path string, moveCurrent bool) error {
+
+	if err := os.MkdirAll(path, 0744); err != nil {
+		return err
+	}
+
+	for _, c := range logChannels() {
+		if err := c.move(path, moveCurrent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns true if both paths are the same folder, e.g. "." and "./logs/.." or through a link.
+func sameFolder(a string, b string) bool {
+
+	if infoA, err := os.Stat(a); err == nil {
+		if infoB, err := os.Stat(b); err == nil {
+			return os.SameFile(infoA, infoB)
+		}
+	}
+
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
+
+// Returns the path of the next archive of the file name in the folder.
+func archivePath(folder string, fileName string, fileNumber *int, date string) (string, error) {
+
+	for {
+		archiveFilePath := folder + "/" + date + "-" + strconv.Itoa(*fileNumber) + "-" + fileName
+		*fileNumber++
+
+		_, err := os.Stat(archiveFilePath)
+
+		if os.IsNotExist(err) {
+			return archiveFilePath, nil
+		} else if err != nil {
+			log.Println("Error while archiving, unable to stat ["+archiveFilePath+"]", err)
+			return "", err
+		}
+	}
+}
+
+// Renames the file, or copies it if it's on another volume.
+func moveFile(from string, to string) error {
+
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(from)
+}
+
+// Returns the archives of the channel, oldest first.
+func (c *channel) archives() (files []os.FileInfo, err error) {
+
+	c.lock.RLock()
+	folder, name := c.folder, c.name
+	c.lock.RUnlock()
+
+	all, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range all {
+		if f.Name() != name && strings.HasSuffix(archiveName(f.Name()), "-"+name) && !f.IsDir() {
+			files = append(files, f)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	return files, nil
+}
+
+// Returns the oldest archives to remove for the current file and the archives to fit in the quota.
+func (c *channel) overQuota() ([]os.FileInfo, error) {
+
+	c.lock.RLock()
+	quota := c.quota * 1024
+	c.lock.RUnlock()
+
+	if quota <= 0 {
+		return nil, nil
+	}
+
+	files, err := c.archives()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+
+	c.lock.RLock()
+	folder := c.folder
+	if c.file != nil {
+		if fileInfo, err := c.file.Stat(); err == nil {
+			total = fileInfo.Size()
+		}
+	}
+	c.lock.RUnlock()
+
+	for _, f := range files {
+		total += f.Size()
+	}
+
+	var over []os.FileInfo
+
+	for _, f := range files {
+		if total <= quota {
+			break
+		}
+		if awaitingShipment(folder + "/" + f.Name()) {
+			continue
+		}
+		total -= f.Size()
+		over = append(over, f)
+	}
+
+	return over, nil
+}
+
+// Removes the oldest archives until the current file and the archives fit in the quota.
+func (c *channel) enforceQuota() {
+
+	if c.deletionPaused() {
+		return
+	}
+
+	files, err := c.overQuota()
+	if err != nil {
+		log.Println("ERROR: Quota enforcement unable to list archives", err)
+		return
+	}
+
+	for _, f := range files {
+		path := c.folder + "/" + f.Name()
+		err := removeArchive(path)
+		if err != nil {
+			log.Println("ERROR: Quota enforcement unable to remove file ["+path+"]", err)
+		} else {
+			log.Println("Quota enforcement removed file [" + path + "]")
+		}
+	}
+}
@@ -0,0 +1,154 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMoveLogFolder(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	defer os.RemoveAll("./moved")
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(INFO)
+
+	Info("before move")
+
+	if !fileContains("./application.log", "before move", t) {
+		t.FailNow()
+	}
+
+	if err := MoveLogFolder("./moved", true); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	Info("after move")
+
+	if !fileContains("./moved/application.log", "after move", t) {
+		fmt.Println("Missing entry from the moved app log")
+		t.FailNow()
+	}
+
+	archive := "./moved/" + time.Now().Local().Format("2006-01-02") + "-0-application.log"
+
+	if !fileContains(archive, "before move", t) || fileExists("./application.log", t) {
+		fmt.Println("Current app log not archived into the new folder")
+		t.FailNow()
+	}
+
+	if err := MoveLogFolder(".", false); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	Info("moved back")
+
+	if !fileContains("./application.log", "moved back", t) || !fileExists("./moved/application.log", t) {
+		fmt.Println("Current app log not left in the old folder")
+		t.FailNow()
+	}
+
+	// Same folder, the current file is kept
+	if err := MoveLogFolder("./moved/..", true); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	Info("not moved")
+
+	if !fileContains("./application.log", "moved back", t) || !fileContains("./application.log", "not moved", t) {
+		fmt.Println("Current app log archived when moved to its own folder")
+		t.FailNow()
+	}
+}
+
+func TestChannelQuota(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetAppLogQuota(3)
+	defer SetAppLogQuota(0)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(INFO)
+
+	for j := 0; j < 500; j++ {
+		Info("Hello " + strconv.Itoa(j))
+	}
+
+	req, _ := http.NewRequest("GET", "http://www.deal.com/quota", nil)
+	Public(*req, 200, 10, 1*time.Millisecond)
+
+	Stop()
+
+	archives, _ := appChannel.archives()
+
+	var total int64
+	for _, f := range archives {
+		total += f.Size()
+	}
+
+	if len(archives) == 0 || total > 3*1024 {
+		fmt.Println("App log archives over quota", total)
+		t.Fail()
+	}
+
+	// Entries are written by concurrent routines, the last one logged is not always in the current file
+	if readFile("./application.log", t) == "" || !fileContains("./access.log", "/quota", t) {
+		fmt.Println("Quota removed current files")
+		t.Fail()
+	}
+}
@@ -0,0 +1,114 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Chaos describes the failures injected in the logging pipeline by SetChaos.
+type Chaos struct {
+	WriteFailureRate float64       // Fraction of the file writes failing, the entry being lost and the error reported
+	SinkDelay        time.Duration // Maximum random delay before each sink write
+	RotationRate     float64       // Fraction of the file writes preceded by a forced rotation
+	Seed             int64         // Seed of the random failures, for reproducible runs
+}
+
+var chaos Chaos
+var chaosEnabled int32 // 1 if a failure is injected, read without lock on the write path
+var chaosRandom *rand.Rand
+var chaosLock = sync.Mutex{}
+
+// ErrChaos is the error of the writes failed by the chaos mode.
+var ErrChaos = errors.New("gol chaos: injected write failure")
+
+// FOR TESTS ONLY: injects random failures in the logging pipeline (failed writes reported through
+// the error handler, delayed sinks, forced rotations), to check that the app and gol behave under
+// logging failures, e.g. in CI. SetChaos(Chaos{}) disables it.
+func SetChaos(c Chaos) {
+
+	chaosLock.Lock()
+	defer chaosLock.Unlock()
+
+	chaos = c
+	chaosRandom = rand.New(rand.NewSource(c.Seed))
+
+	if c != (Chaos{}) {
+		atomic.StoreInt32(&chaosEnabled, 1)
+	} else {
+		atomic.StoreInt32(&chaosEnabled, 0)
+	}
+}
+
+// Returns true with the probability returned by rate.
+func chaosDraw(rate func(c Chaos) float64) bool {
+
+	if atomic.LoadInt32(&chaosEnabled) == 0 {
+		return false
+	}
+
+	chaosLock.Lock()
+	defer chaosLock.Unlock()
+
+	return chaosRandom.Float64() < rate(chaos)
+}
+
+// Returns true if the write must fail, reporting the failure.
+func chaosWriteFails() bool {
+
+	if !chaosDraw(func(c Chaos) float64 { return c.WriteFailureRate }) {
+		return false
+	}
+
+	reportError(ErrChaos)
+
+	return true
+}
+
+// Returns true if the write must rotate the file first.
+func chaosRotates() bool {
+	return chaosDraw(func(c Chaos) float64 { return c.RotationRate })
+}
+
+// Sleeps for a random part of the sink delay.
+func chaosSinkDelay() {
+
+	if atomic.LoadInt32(&chaosEnabled) == 0 {
+		return
+	}
+
+	chaosLock.Lock()
+	d := time.Duration(0)
+	if chaos.SinkDelay > 0 {
+		d = time.Duration(chaosRandom.Int63n(int64(chaos.SinkDelay)))
+	}
+	chaosLock.Unlock()
+
+	time.Sleep(d)
+}
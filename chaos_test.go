@@ -0,0 +1,91 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChaos(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	var failures int32
+	SetErrorHandler(func(err error) {
+		if err == ErrChaos {
+			atomic.AddInt32(&failures, 1)
+		}
+	})
+	defer SetErrorHandler(nil)
+
+	sink := &recordingSink{}
+	AddSink(sink, INFO)
+	defer RemoveSink(sink)
+
+	SetChaos(Chaos{WriteFailureRate: 0.5, RotationRate: 0.2, SinkDelay: time.Millisecond, Seed: 1})
+	defer SetChaos(Chaos{})
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 100; j++ {
+		Info("chaos entry", j)
+	}
+
+	Stop()
+
+	written := 0
+	archives := 0
+
+	files, _ := ioutil.ReadDir(".")
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), "application.log") {
+			written += strings.Count(readFile("./"+f.Name(), t), "chaos entry")
+			if f.Name() != "application.log" {
+				archives++
+			}
+		}
+	}
+
+	if failures == 0 || written == 0 || int(failures)+written != 100 || archives == 0 || len(sink.entries) != 100 {
+		fmt.Println("Unexpected chaos results", failures, written, archives, len(sink.entries))
+		t.Fail()
+	}
+}
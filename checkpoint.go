@@ -0,0 +1,192 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Checkpoint is the position of a log persisted by gol, see SetSequenceCheckpoints.
+type Checkpoint struct {
+	Sequence uint64    `json:"sequence"` // Last sequence number stamped
+	File     string    `json:"file"`     // Current file
+	Offset   int64     `json:"offset"`   // Bytes written in the current file
+	Time     time.Time `json:"time"`
+}
+
+const checkpointSuffix = ".checkpoint.json"
+
+var checkpointInterval time.Duration // 0 disables the checkpoints
+
+var sequencePattern = regexp.MustCompile(`(?:^| )seq=(\d+)(?: |$)`)
+
+// Persists the sequence number and the size of the current file of the app and public access logs
+// every interval (and on Stop) in name.checkpoint.json, next to the log. After a restart, clean or
+// not, the sequence numbers resume after the last one written (found from the checkpoint), so
+// that the remote sinks and shippers with at-least-once delivery can deduplicate the entries by
+// their sequence number and resume from a checkpoint, see ReadCheckpoint. Needs the sequence
+// numbers, see SetSequenceNumbers. 0 disables the checkpoints.
+func SetSequenceCheckpoints(interval time.Duration) {
+	checkpointInterval = interval
+}
+
+// Returns the last checkpoint of the log name in the folder.
+func ReadCheckpoint(folder string, name string) (Checkpoint, error) {
+
+	var cp Checkpoint
+
+	b, err := ioutil.ReadFile(folder + "/" + name + checkpointSuffix)
+	if err != nil {
+		return cp, err
+	}
+
+	err = json.Unmarshal(b, &cp)
+
+	return cp, err
+}
+
+// Writes the checkpoints of the channels every interval until Stop.
+func checkpointFiles(channels []*channel, interval time.Duration) {
+
+	defer routines.Done()
+
+	for idle(interval) {
+		for _, c := range channels {
+			c.checkpoint()
+		}
+	}
+}
+
+// Writes the checkpoint of the channel, replacing the previous one at once.
+func (c *channel) checkpoint() {
+
+	c.lock.RLock()
+	if c.file == nil {
+		c.lock.RUnlock()
+		return
+	}
+
+	cp := Checkpoint{Sequence: atomic.LoadUint64(&c.sequence), File: c.name, Time: time.Now()}
+
+	if c.mm != nil {
+		cp.Offset = c.mm.size()
+	} else if info, err := c.file.Stat(); err == nil {
+		cp.Offset = info.Size()
+	}
+	folder := c.folder
+	c.lock.RUnlock()
+
+	b, err := json.Marshal(cp)
+	if err != nil {
+		reportError(err)
+		return
+	}
+
+	path := folder + "/" + c.name + checkpointSuffix
+	if err := ioutil.WriteFile(path+".tmp", append(b, '\n'), 0644); err != nil {
+		reportError(err)
+		return
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		reportError(err)
+	}
+}
+
+// Resumes the sequence numbers of the channel after the last one written, from its checkpoint
+// and the entries written since.
+func (c *channel) restoreSequence() {
+
+	c.lock.RLock()
+	folder, name, file := c.folder, c.name, c.file
+	c.lock.RUnlock()
+
+	cp, err := ReadCheckpoint(folder, name)
+	if err != nil || file == nil {
+		return
+	}
+
+	last := cp.Sequence
+
+	offset := cp.Offset
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		offset = 0 // Rotated since the checkpoint, the entries written since may be in the last archive
+		if files, err := c.archives(); err == nil && len(files) > 0 {
+			if seq := lastSequence(folder+"/"+files[len(files)-1].Name(), 0); seq > last {
+				last = seq
+			}
+		}
+	}
+
+	if seq := lastSequence(folder+"/"+name, offset); seq > last {
+		last = seq
+	}
+
+	if last > atomic.LoadUint64(&c.sequence) {
+		atomic.StoreUint64(&c.sequence, last)
+	}
+}
+
+// Returns the highest sequence number of the entries of the file after the offset.
+func lastSequence(path string, offset int64) (last uint64) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	if offset > 0 {
+		if _, err := f.Seek(offset, 0); err != nil {
+			return 0
+		}
+	}
+
+	r, err := openArchive(f)
+	if err != nil {
+		return 0
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if m := sequencePattern.FindSubmatch(scanner.Bytes()); m != nil {
+			if seq, err := strconv.ParseUint(string(m[1]), 10, 64); err == nil && seq > last {
+				last = seq
+			}
+		}
+	}
+
+	return last
+}
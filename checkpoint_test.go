@@ -0,0 +1,89 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSequenceCheckpoints(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	SetSequenceNumbers(true)
+	SetSequenceCheckpoints(time.Hour)
+	atomic.StoreUint64(&appChannel.sequence, 0)
+
+	defer func() {
+		SetSynchronous(false)
+		SetSequenceNumbers(false)
+		SetSequenceCheckpoints(0)
+		atomic.StoreUint64(&appChannel.sequence, 0)
+	}()
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("one")
+	Info("two")
+	Info("three")
+
+	Stop()
+
+	cp, err := ReadCheckpoint(".", "application.log")
+	if err != nil || cp.Sequence != 3 || cp.File != "application.log" || cp.Offset == 0 {
+		fmt.Printf("Unexpected checkpoint %+v %v\n", cp, err)
+		t.Fail()
+	}
+
+	// Entries written after the checkpoint by a process which then crashed
+	f, _ := os.OpenFile("./application.log", os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("2026-01-02 10:00:00 INFO [four] seq=4\n2026-01-02 10:00:00 INFO [five] seq=5 at main.go:1\n")
+	f.Close()
+
+	// New process
+	atomic.StoreUint64(&appChannel.sequence, 0)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("six")
+
+	Stop()
+
+	if !fileContains("./application.log", "INFO [six] seq=6", t) {
+		fmt.Println("Sequence not resumed", readFile("./application.log", t))
+		t.Fail()
+	}
+}
@@ -0,0 +1,157 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ClassRule classifies the requests matching any of its criteria, e.g. health checks, bots or
+// internal traffic, so that dashboards can exclude the non-human traffic (class=... in the
+// public access log).
+type ClassRule struct {
+	Class      string   // e.g. "health", "bot", "internal"
+	Paths      []string // URL paths, or path prefixes ending with *, e.g. "/healthz", "/internal/*"
+	UserAgents []string // Case insensitive substrings of the user agent, e.g. "bot", "kube-probe"
+	Networks   []string // CIDRs of the clients, e.g. "10.0.0.0/8"
+}
+
+type classRule struct {
+	ClassRule
+	userAgents []string // Lower case
+	networks   []*net.IPNet
+}
+
+var classRules []classRule
+var classLock = sync.RWMutex{}
+
+// Returns the usual rules: health (/health, /healthz, /livez, /readyz, /ping and probe agents),
+// bot (crawlers, scanners and HTTP libraries) and internal (private and loopback networks).
+func DefaultClassRules() []ClassRule {
+	return []ClassRule{
+		{Class: "health", Paths: []string{"/health", "/healthz", "/livez", "/readyz", "/ping"}, UserAgents: []string{"kube-probe", "elb-healthchecker", "googlehc"}},
+		{Class: "bot", UserAgents: []string{"bot", "crawler", "spider", "slurp", "zgrab", "masscan", "nmap", "curl/", "wget/", "python-requests", "go-http-client"}},
+		{Class: "internal", Networks: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8", "::1/128", "fc00::/7"}},
+	}
+}
+
+// Replaces the classification rules of the public access log entries. An entry gets the class of
+// the first matching rule, no class if none matches.
+func SetClassRules(rules ...ClassRule) error {
+
+	compiled := make([]classRule, 0, len(rules))
+
+	for _, r := range rules {
+		if r.Class == "" || strings.ContainsAny(r.Class, " \t=") {
+			return fmt.Errorf("invalid request class [%s]", r.Class)
+		}
+
+		c := classRule{ClassRule: r}
+
+		for _, ua := range r.UserAgents {
+			c.userAgents = append(c.userAgents, strings.ToLower(ua))
+		}
+
+		for _, cidr := range r.Networks {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return fmt.Errorf("invalid network [%s] of request class [%s]", cidr, r.Class)
+			}
+			c.networks = append(c.networks, network)
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	classLock.Lock()
+	classRules = compiled
+	classLock.Unlock()
+
+	return nil
+}
+
+// Returns the class of the request, empty if no rule matches.
+func classOf(path string, userAgent string, remoteAddr string) string {
+
+	classLock.RLock()
+	defer classLock.RUnlock()
+
+	if len(classRules) == 0 {
+		return ""
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	ip := clientIP(remoteAddr)
+
+	for _, r := range classRules {
+		if r.matches(path, userAgent, ip) {
+			return r.Class
+		}
+	}
+
+	return ""
+}
+
+func (r classRule) matches(path string, userAgent string, ip net.IP) bool {
+
+	for _, p := range r.Paths {
+		if p == path || strings.HasSuffix(p, "*") && strings.HasPrefix(path, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+
+	for _, ua := range r.userAgents {
+		if strings.Contains(userAgent, ua) {
+			return true
+		}
+	}
+
+	for _, n := range r.networks {
+		if ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the IP of the client from an X-Forwarded-For header (first address) or a remote address.
+func clientIP(addr string) net.IP {
+
+	if i := strings.Index(addr, ","); i >= 0 {
+		addr = addr[:i]
+	}
+
+	addr = strings.TrimSpace(addr)
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	return net.ParseIP(addr)
+}
@@ -0,0 +1,90 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassRules(t *testing.T) {
+
+	if SetClassRules(ClassRule{Class: "bad", Networks: []string{"10.0.0.0/33"}}) == nil || SetClassRules(ClassRule{}) == nil {
+		fmt.Println("Invalid class rules accepted")
+		t.Fail()
+	}
+
+	if err := SetClassRules(append([]ClassRule{{Class: "admin", Paths: []string{"/admin/*"}}}, DefaultClassRules()...)...); err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+	defer SetClassRules()
+
+	for _, c := range []struct{ path, agent, addr, class string }{
+		{"/healthz", "Mozilla/5.0", "8.8.8.8:1234", "health"},
+		{"/", "kube-probe/1.27", "8.8.8.8:1234", "health"},
+		{"/products", "Mozilla/5.0 (compatible; Googlebot/2.1)", "8.8.8.8:1234", "bot"},
+		{"/products", "Mozilla/5.0", "10.1.2.3, 8.8.8.8", "internal"},
+		{"/products", "Mozilla/5.0", "[::1]:80", "internal"},
+		{"/admin/users", "Mozilla/5.0", "10.1.2.3:80", "admin"},
+		{"/products", "Mozilla/5.0", "8.8.8.8:1234", ""},
+	} {
+		if class := classOf(c.path, c.agent, c.addr); class != c.class {
+			fmt.Println("Unexpected class", class, "for", c)
+			t.Fail()
+		}
+	}
+}
+
+func TestClassField(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	SetClassRules(DefaultClassRules()...)
+	defer SetClassRules()
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	req, _ := http.NewRequest("GET", "http://www.deal.com/healthz", nil)
+	req.RemoteAddr = "8.8.8.8:1234"
+	Public(*req, 200, 2, time.Millisecond)
+
+	Stop()
+
+	if !fileContains("./access.log", "class=health", t) {
+		fmt.Println("Missing class field", readFile("./access.log", t))
+		t.Fail()
+	}
+}
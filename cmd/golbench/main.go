@@ -0,0 +1,185 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Command golbench drives producers logging through gol for a fixed duration and reports the
+// throughput, the p99 latency of the logging calls, the drops and the CPU used, e.g.
+//
+//	golbench -producers 8 -duration 30s -fields 5 -sinks 2 -sink-delay 1ms -shedding
+//
+// The entries are identical from one run to the next, so runs on the same hardware with the
+// same flags are comparable.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexv99/gol"
+)
+
+// Sink simulating a remote service answering after a delay.
+type slowSink struct {
+	delay time.Duration
+}
+
+func (s *slowSink) WriteEntry(e gol.Entry) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func main() {
+
+	producers := flag.Int("producers", runtime.NumCPU(), "number of goroutines logging")
+	duration := flag.Duration("duration", 10*time.Second, "duration of the run")
+	rate := flag.Int("rate", 0, "entries per second per producer, 0 for as fast as possible")
+	fields := flag.Int("fields", 3, "number of fields per entry")
+	size := flag.Int("size", 64, "size of the messages in bytes")
+	sinks := flag.Int("sinks", 0, "number of sinks receiving the entries")
+	sinkDelay := flag.Duration("sink-delay", 0, "time taken by each sink per entry")
+	folder := flag.String("folder", "", "folder of the log files, a temporary folder if empty")
+	synchronous := flag.Bool("sync", false, "synchronous writes, see gol.SetSynchronous")
+	ordered := flag.Bool("ordered", false, "ordered batched writes, see gol.SetOrderedWrites")
+	mmap := flag.Bool("mmap", false, "writes through a memory mapping, see gol.SetMmapWrites")
+	shedding := flag.Bool("shedding", false, "sheds DEBUG and INFO entries under pressure, see gol.SetLevelShedding")
+	flag.Parse()
+
+	if *folder == "" {
+		tmp, err := ioutil.TempDir("", "golbench")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(tmp)
+		*folder = tmp
+	}
+
+	gol.SetAppLogFolder(*folder)
+	gol.SetPublicLogFolder(*folder)
+	gol.LogToStdout(false)
+	gol.SetSynchronous(*synchronous)
+	gol.SetOrderedWrites(*ordered)
+	gol.SetMmapWrites(*mmap)
+	gol.SetLevelShedding(*shedding)
+
+	for i := 0; i < *sinks; i++ {
+		gol.AddSink(&slowSink{delay: *sinkDelay}, gol.DEBUG)
+	}
+
+	if err := gol.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	message := make([]byte, *size)
+	for i := range message {
+		message[i] = 'a' + byte(i%26)
+	}
+
+	args := []interface{}{string(message)}
+	for i := 0; i < *fields; i++ {
+		args = append(args, "field"+strconv.Itoa(i), i)
+	}
+
+	cpuStart := cpuTime()
+	start := time.Now()
+	deadline := start.Add(*duration)
+
+	latencies := make([][]time.Duration, *producers)
+	var wg sync.WaitGroup
+
+	for p := 0; p < *producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+
+			var interval time.Duration
+			if *rate > 0 {
+				interval = time.Second / time.Duration(*rate)
+			}
+			next := time.Now()
+
+			for n := 0; ; n++ {
+				t := time.Now()
+				if t.After(deadline) {
+					return
+				}
+
+				gol.Info(args...)
+
+				// One call out of 16 is timed, keeping the measure cheap
+				if n%16 == 0 {
+					latencies[p] = append(latencies[p], time.Since(t))
+				}
+
+				if interval > 0 {
+					next = next.Add(interval)
+					time.Sleep(time.Until(next))
+				}
+			}
+		}(p)
+	}
+
+	wg.Wait()
+	produced := time.Since(start)
+
+	gol.Stop() // Flushes the queued entries
+	elapsed := time.Since(start)
+	cpu := cpuTime() - cpuStart
+
+	stats := gol.Stats()
+	written := stats.Entries["INFO"]
+
+	var all []time.Duration
+	for _, l := range latencies {
+		all = append(all, l...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	var p50, p99 time.Duration
+	if len(all) > 0 {
+		p50 = all[len(all)/2]
+		p99 = all[len(all)*99/100]
+	}
+
+	fmt.Printf("producers=%d duration=%s fields=%d size=%d sinks=%d sink_delay=%s sync=%t ordered=%t mmap=%t shedding=%t\n",
+		*producers, *duration, *fields, *size, *sinks, *sinkDelay, *synchronous, *ordered, *mmap, *shedding)
+	fmt.Printf("written=%d entries_per_sec=%.0f mb_per_sec=%.2f\n",
+		written, float64(written)/elapsed.Seconds(), float64(stats.Bytes)/elapsed.Seconds()/1024/1024)
+	fmt.Printf("enqueue_p50=%s enqueue_p99=%s drain=%s\n", p50, p99, (elapsed - produced).Round(time.Millisecond))
+	fmt.Printf("dropped=%d shed=%d cpu=%s cpu_per_entry=%s\n", stats.Dropped, stats.Shed, cpu.Round(time.Millisecond), perEntry(cpu, written))
+}
+
+func perEntry(cpu time.Duration, entries int64) time.Duration {
+	if entries == 0 {
+		return 0
+	}
+	return cpu / time.Duration(entries)
+}
@@ -0,0 +1,92 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Command golcap queries the binary access log capture segments written by gol.SetAccessCapture,
+// printing the matching entries in the public access log format, e.g.
+//
+//	golcap -folder /var/log -since 1h -status 500 -ip 203.0.113.7
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alexv99/gol"
+)
+
+func main() {
+
+	folder := flag.String("folder", ".", "folder of the capture segments")
+	since := flag.Duration("since", 0, "only the entries of the last duration, e.g. 1h")
+	status := flag.Int("status", 0, "only the entries with a status at or above")
+	ip := flag.String("ip", "", "only the entries of the client address")
+	grep := flag.String("grep", "", "only the entries with a URL containing the text")
+	class := flag.String("class", "", "only the entries of the request class, e.g. bot")
+	count := flag.Bool("count", false, "print the number of matching entries only")
+	flag.Parse()
+
+	r, err := gol.OpenCaptureReader(*folder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer r.Close()
+
+	var from time.Time
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	}
+
+	matches := 0
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if e.Time.Before(from) || e.Status < *status || !strings.Contains(e.URL, *grep) ||
+			*ip != "" && !strings.HasPrefix(e.RemoteAddr, *ip) || *class != "" && e.Class != *class {
+			continue
+		}
+
+		matches++
+
+		if !*count {
+			fmt.Print(e.String())
+		}
+	}
+
+	if *count {
+		fmt.Println(matches)
+	}
+}
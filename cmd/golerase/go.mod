@@ -0,0 +1,15 @@
+module github.com/alexv99/gol/cmd/golerase
+
+go 1.16
+
+require (
+	github.com/alexv99/gol v0.0.0-00010101000000-000000000000
+	github.com/alexv99/gol/codec/lz4 v0.0.0-00010101000000-000000000000
+	github.com/alexv99/gol/codec/zstd v0.0.0-00010101000000-000000000000
+)
+
+replace (
+	github.com/alexv99/gol => ../..
+	github.com/alexv99/gol/codec/lz4 => ../../codec/lz4
+	github.com/alexv99/gol/codec/zstd => ../../codec/zstd
+)
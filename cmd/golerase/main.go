@@ -0,0 +1,69 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Command golerase rewrites the archives of a gol log without the entries of a field value, or
+// with the value masked, to fulfill a right to erasure request, e.g.
+//
+//	golerase -folder /var/log -name application.log -key user_id -value 123
+//
+// The archives compressed with zstd or lz4 are supported along with gzip.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alexv99/gol"
+	_ "github.com/alexv99/gol/codec/lz4"
+	_ "github.com/alexv99/gol/codec/zstd"
+)
+
+func main() {
+
+	folder := flag.String("folder", "/var/log", "folder of the log files")
+	name := flag.String("name", "application.log", "name of the log, e.g. access.log")
+	key := flag.String("key", "", "key of the field identifying the entries to erase")
+	value := flag.String("value", "", "value of the field")
+	mask := flag.Bool("mask", false, "masks the value of the field instead of removing the entries")
+	flag.Parse()
+
+	if *key == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := gol.Erase(*folder, *name, gol.Erasure{Key: *key, Value: *value, Mask: *mask})
+
+	for _, f := range report.Files {
+		fmt.Println("rewritten", f)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d entries erased in %d archives\n", report.Entries, len(report.Files))
+}
@@ -0,0 +1,10 @@
+module github.com/alexv99/gol/codec/lz4
+
+go 1.16
+
+require (
+	github.com/alexv99/gol v0.0.0-00010101000000-000000000000
+	github.com/pierrec/lz4/v4 v4.1.17
+)
+
+replace github.com/alexv99/gol => ../..
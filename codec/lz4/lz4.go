@@ -0,0 +1,65 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package lz4 registers the lz4 codec of the archives, selected with
+// gol.SetArchiveCompression("lz4", 0) once imported:
+//
+//	import _ "github.com/alexv99/gol/codec/lz4"
+package lz4
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/alexv99/gol"
+	"github.com/pierrec/lz4/v4"
+)
+
+func init() {
+	gol.RegisterCodec("lz4", codec{})
+}
+
+type codec struct{}
+
+func (codec) Extension() string {
+	return ".lz4"
+}
+
+// The level is 1 (fastest) to 9, 0 for the default (fast mode).
+func (codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+
+	zw := lz4.NewWriter(w)
+
+	if level > 0 {
+		if err := zw.Apply(lz4.CompressionLevelOption(lz4.CompressionLevel(1 << (8 + level)))); err != nil {
+			return nil, err
+		}
+	}
+
+	return zw, nil
+}
+
+func (codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return ioutil.NopCloser(lz4.NewReader(r)), nil
+}
@@ -0,0 +1,10 @@
+module github.com/alexv99/gol/codec/zstd
+
+go 1.16
+
+require (
+	github.com/alexv99/gol v0.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.13.6
+)
+
+replace github.com/alexv99/gol => ../..
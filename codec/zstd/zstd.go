@@ -0,0 +1,66 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package zstd registers the zstd codec of the archives, selected with
+// gol.SetArchiveCompression("zstd", 3) once imported:
+//
+//	import _ "github.com/alexv99/gol/codec/zstd"
+package zstd
+
+import (
+	"io"
+
+	"github.com/alexv99/gol"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	gol.RegisterCodec("zstd", codec{})
+}
+
+type codec struct{}
+
+func (codec) Extension() string {
+	return ".zst"
+}
+
+// The level is a zstd level, 1 (fastest) to 22, 0 for the default (3).
+func (codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+
+	if level == 0 {
+		return zstd.NewWriter(w)
+	}
+
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+}
+
+func (codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return d.IOReadCloser(), nil
+}
@@ -0,0 +1,72 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package zstd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/alexv99/gol"
+)
+
+func TestRoundTrip(t *testing.T) {
+
+	if err := gol.SetArchiveCompression("zstd", 0); err != nil {
+		t.Fatal(err)
+	}
+	defer gol.SetArchiveCompression("", 0)
+
+	text := strings.Repeat("2017-03-01 10:00:00 INFO [hello] user=bob\n", 1000)
+
+	for _, level := range []int{0, 1, 9} {
+		var buf bytes.Buffer
+
+		w, err := codec{}.NewWriter(&buf, level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(text))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.Len() >= len(text)/10 {
+			t.Errorf("Level %d compressed %d bytes into %d", level, len(text), buf.Len())
+		}
+
+		r, err := codec{}.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+
+		if err != nil || string(b) != text {
+			t.Errorf("Level %d not decompressed: %v", level, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const commandFlushDelay = 100 * time.Millisecond // Idle time before logging an unterminated line
+
+// Logs the standard output and error of the command line by line at the level, with the fields
+// cmd (name of the executable) and stream (stdout or stderr). Must be called before cmd.Start
+// or cmd.Run. An unterminated last line is logged once the command has been idle for 100ms.
+func CommandLogger(cmd *exec.Cmd, level int) {
+
+	if !checkLevel(level) {
+		return
+	}
+
+	name := filepath.Base(cmd.Path)
+
+	cmd.Stdout = &commandWriter{level: level, fields: []Field{{Key: "cmd", Value: name}, {Key: "stream", Value: "stdout"}}}
+	cmd.Stderr = &commandWriter{level: level, fields: []Field{{Key: "cmd", Value: name}, {Key: "stream", Value: "stderr"}}}
+}
+
+// Logs the lines written by a child process.
+type commandWriter struct {
+	level   int
+	fields  []Field
+	partial string // Unterminated line
+	flush   *time.Timer
+	lock    sync.Mutex
+}
+
+func (w *commandWriter) Write(p []byte) (int, error) {
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.flush != nil {
+		w.flush.Stop()
+	}
+
+	lines := strings.Split(w.partial+string(p), "\n")
+	w.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		w.log(line)
+	}
+
+	if w.partial != "" {
+		w.flush = time.AfterFunc(commandFlushDelay, w.flushPartial)
+	}
+
+	return len(p), nil
+}
+
+func (w *commandWriter) flushPartial() {
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.partial != "" {
+		w.log(w.partial)
+		w.partial = ""
+	}
+}
+
+func (w *commandWriter) log(line string) {
+	appLog(w.level, appLogLevel(), w.fields, []interface{}{strings.TrimSuffix(line, "\r")})
+}
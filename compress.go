@@ -0,0 +1,383 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Codec compresses the archives of the log files, see SetArchiveCompression. The codecs other
+// than gzip are registered by importing their module, e.g. github.com/alexv99/gol/codec/zstd, so that
+// their dependencies are only required by the apps using them.
+type Codec interface {
+	Extension() string                                        // Suffix of the compressed archives, e.g. ".gz"
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error) // Level 0 for the default of the codec
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Extension() string {
+	return ".gz"
+}
+
+func (gzipCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+var codecs = map[string]Codec{"gzip": gzipCodec{}}
+var codecsLock = sync.RWMutex{}
+
+var archiveCodec Codec // nil for uncompressed archives
+var archiveLevel int
+var archiveChunkSize int64 // in bytes, 0 for a single compressed file per archive
+var archiveLock = sync.RWMutex{}
+var compressions sync.WaitGroup
+
+// Registers a codec under the name, to be selected with SetArchiveCompression.
+func RegisterCodec(name string, codec Codec) {
+
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	codecs[name] = codec
+}
+
+// Compresses the archives with the codec (gzip, or a registered one, e.g. zstd), at the level
+// of the codec or 0 for its default, after each rotation. An empty codec keeps them uncompressed.
+func SetArchiveCompression(codec string, level int) error {
+
+	if codec == "" {
+		archiveLock.Lock()
+		archiveCodec = nil
+		archiveLock.Unlock()
+		return nil
+	}
+
+	codecsLock.RLock()
+	c, ok := codecs[codec]
+	codecsLock.RUnlock()
+
+	if !ok {
+		return errors.New("unknown compression codec [" + codec + "], missing import of its sub-package?")
+	}
+
+	archiveLock.Lock()
+	archiveCodec, archiveLevel = c, level
+	archiveLock.Unlock()
+
+	return nil
+}
+
+// Returns the codec, level and chunk size of the archives, a nil codec if uncompressed.
+func archiveCompression() (Codec, int, int64) {
+
+	archiveLock.RLock()
+	defer archiveLock.RUnlock()
+
+	return archiveCodec, archiveLevel, archiveChunkSize
+}
+
+// Splits the compressed archives into chunks of at most mb MB, e.g. for the multipart limits of an
+// object store. The chunks are named date-N-name.partK.ext (K from 1), each one a complete stream
+// of whole lines, so a single line larger than the chunk size makes a larger chunk. 0 disables it.
+func SetArchiveChunkSize(mb int) {
+	archiveLock.Lock()
+	archiveChunkSize = int64(mb) * 1024 * 1024
+	archiveLock.Unlock()
+}
+
+var chunkPattern = regexp.MustCompile(`\.part\d+$`)
+
+// Returns the name of the archive of the file name, without its compression extension nor chunk number.
+func archiveName(fileName string) string {
+	return chunkPattern.ReplaceAllString(uncompressedName(fileName), "")
+}
+
+// Returns the codec of the compressed file name, nil if it isn't compressed.
+func codecOf(fileName string) Codec {
+
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	for _, c := range codecs {
+		if strings.HasSuffix(fileName, c.Extension()) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Returns the extensions of the registered codecs, sorted.
+func codecExtensions() []string {
+
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	var extensions []string
+	for _, c := range codecs {
+		extensions = append(extensions, c.Extension())
+	}
+	sort.Strings(extensions)
+
+	return extensions
+}
+
+// Returns the file name without its compression extension.
+func uncompressedName(fileName string) string {
+
+	if c := codecOf(fileName); c != nil {
+		return strings.TrimSuffix(fileName, c.Extension())
+	}
+
+	return fileName
+}
+
+// Compresses the uncompressed archives of the channel in the background, one pass at a time,
+// then updates its manifest. Returns false if the archives aren't compressed.
+func (c *channel) compressArchives() bool {
+
+	codec, level, chunkSize := archiveCompression()
+	if codec == nil {
+		return false
+	}
+
+	if !atomic.CompareAndSwapInt32(&c.compressing, 0, 1) {
+		return true
+	}
+
+	compressions.Add(1)
+
+	go func() {
+		defer compressions.Done()
+		defer atomic.StoreInt32(&c.compressing, 0)
+
+		files, err := c.archives()
+		if err != nil {
+			log.Println("ERROR: Compression unable to list archives", err)
+			return
+		}
+
+		c.lock.RLock()
+		folder := c.folder
+		c.lock.RUnlock()
+
+		for _, f := range files {
+			if codecOf(f.Name()) != nil {
+				continue
+			}
+			path := folder + "/" + f.Name()
+			if err := compressFile(path, codec, level, chunkSize); err != nil {
+				reportError(err)
+			}
+		}
+
+		c.updateManifest()
+	}()
+
+	return true
+}
+
+// Replaces the file by its compressed copy, or its compressed chunks if larger than the chunk
+// size, keeping its modification time for the purges.
+func compressFile(path string, codec Codec, level int, chunkSize int64) error {
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	// The temporary files aren't taken for archives until complete
+	var chunks []string
+	defer func() {
+		for _, chunk := range chunks {
+			os.Remove(chunk)
+		}
+	}()
+
+	r := bufio.NewReaderSize(src, 64*1024)
+
+	for {
+		tmp := path + ".part" + strconv.Itoa(len(chunks)+1) + codec.Extension() + ".tmp"
+		chunks = append(chunks, tmp)
+
+		more, err := compressChunk(tmp, r, codec, level, chunkSize)
+		if err != nil {
+			return err
+		}
+		os.Chtimes(tmp, info.ModTime(), info.ModTime())
+
+		if !more {
+			break
+		}
+	}
+
+	if len(chunks) == 1 {
+		if err := os.Rename(chunks[0], path+codec.Extension()); err != nil {
+			return err
+		}
+	} else {
+		for _, chunk := range chunks {
+			if err := os.Rename(chunk, strings.TrimSuffix(chunk, ".tmp")); err != nil {
+				return err
+			}
+		}
+	}
+
+	chunks = nil
+
+	return os.Remove(path)
+}
+
+// Output of a codec which can be flushed to measure the compressed size.
+type flusher interface {
+	Flush() error
+}
+
+// Counts the bytes written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// Compresses whole lines of r into the file until r ends (returning false) or the file would
+// exceed the chunk size (returning true), 0 for no limit.
+func compressChunk(path string, r *bufio.Reader, codec Codec, level int, chunkSize int64) (more bool, err error) {
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return false, err
+	}
+
+	out := &countingWriter{w: f}
+
+	w, err := codec.NewWriter(out, level)
+	if err != nil {
+		f.Close()
+		return false, err
+	}
+
+	// The output is measured every block, the next block and the end of the
+	// stream (smaller than a block, even incompressible) have to fit
+	const block = 64 * 1024
+	var pending int64
+
+	for {
+		line, readErr := r.ReadSlice('\n')
+		for readErr == bufio.ErrBufferFull {
+			// Line longer than the buffer, written in pieces
+			if _, err = w.Write(line); err != nil {
+				break
+			}
+			pending += int64(len(line))
+			line, readErr = r.ReadSlice('\n')
+		}
+		if err != nil {
+			break
+		}
+
+		if _, err = w.Write(line); err != nil {
+			break
+		}
+		pending += int64(len(line))
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			err = readErr
+			break
+		}
+
+		if fw, ok := w.(flusher); ok && chunkSize > 0 && pending >= block {
+			if err = fw.Flush(); err != nil {
+				break
+			}
+			pending = 0
+			if out.n+2*block > chunkSize {
+				more = true
+				break
+			}
+		}
+	}
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if more {
+		// Nothing left, no empty chunk
+		if _, peekErr := r.Peek(1); peekErr == io.EOF {
+			more = false
+		}
+	}
+
+	return more, err
+}
+
+// Returns a reader of the decompressed content of the file, according to its extension.
+func openArchive(f *os.File) (io.ReadCloser, error) {
+
+	if c := codecOf(f.Name()); c != nil {
+		return c.NewReader(f)
+	}
+
+	return ioutil.NopCloser(f), nil
+}
@@ -0,0 +1,190 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestArchiveCompression(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := SetArchiveCompression("gzip", 9); err != nil {
+		t.Fatal(err)
+	}
+	defer SetArchiveCompression("", 0)
+
+	// The file of a previous day is rotated by the daily rotation, then compressed
+	SetDailyRotation(true, time.UTC, 0)
+	defer SetDailyRotation(false, nil, 0)
+
+	old := time.Now().UTC().AddDate(0, 0, -2).Truncate(time.Second)
+	ioutil.WriteFile("./application.log", []byte("old entry\n"), 0644)
+	os.Chtimes("./application.log", old, old)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("new entry")
+
+	Stop()
+
+	archive := "./" + old.Format("2006-01-02") + "-0-application.log"
+
+	info, err := os.Stat(archive + ".gz")
+	if err != nil || fileExists(archive, t) {
+		fmt.Println("Archive not compressed", err)
+		t.FailNow()
+	}
+
+	if !info.ModTime().Equal(old) {
+		fmt.Println("Modification time of the archive not kept", info.ModTime())
+		t.Fail()
+	}
+
+	paths, err := logFiles(".", "application.log")
+	if err != nil || len(paths) != 2 {
+		fmt.Println("Unexpected log files", paths, err)
+		t.FailNow()
+	}
+
+	r := &lineReader{paths: paths}
+	defer r.closeFile()
+
+	if line, err := r.next(); err != nil || line != "old entry" {
+		fmt.Println("Compressed archive not read", line, err)
+		t.Fail()
+	}
+
+	archives, _ := appChannel.archives()
+	if len(archives) != 1 {
+		fmt.Println("Compressed archive not listed for the quota and purges", archives)
+		t.Fail()
+	}
+}
+
+func TestUnknownCodec(t *testing.T) {
+
+	err := SetArchiveCompression("brotli", 0)
+
+	if codec, _, _ := archiveCompression(); err == nil || codec != nil {
+		fmt.Println("Unknown codec accepted")
+		t.Fail()
+	}
+
+	c := DefaultConfig()
+	c.Compression.Codec = "brotli"
+
+	if c.Validate() == nil {
+		fmt.Println("Unknown codec accepted in the config")
+		t.Fail()
+	}
+}
+
+func TestArchiveChunks(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+
+	// Lines of random hexadecimal, compressed about 2 times
+	var text []byte
+	random := rand.New(rand.NewSource(1))
+	for len(text) < 3*1024*1024 {
+		line := make([]byte, 100)
+		random.Read(line)
+		text = append(text, hex.EncodeToString(line)+"\n"...)
+	}
+
+	archive := "./2017-03-01-0-application.log"
+	ioutil.WriteFile(archive, text, 0644)
+
+	if err := compressFile(archive, gzipCodec{}, 0, 512*1024); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, _ := logFiles(".", "application.log")
+	if len(paths) < 3 || fileExists(archive, t) {
+		fmt.Println("Archive not split", paths)
+		t.FailNow()
+	}
+
+	for i, path := range paths {
+		if path != archive+".part"+strconv.Itoa(i+1)+".gz" {
+			fmt.Println("Unexpected chunk", path)
+			t.Fail()
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() > 512*1024 {
+			fmt.Println("Chunk over the size", path)
+			t.Fail()
+		}
+	}
+
+	r := &lineReader{paths: paths}
+	defer r.closeFile()
+
+	var read []byte
+	for {
+		line, err := r.next()
+		if err != nil {
+			break
+		}
+		read = append(read, line+"\n"...)
+	}
+
+	if !bytes.Equal(read, text) {
+		fmt.Println("Chunks not read back in order")
+		t.Fail()
+	}
+
+	archives, _ := appChannel.archives()
+	if len(archives) != len(paths) {
+		fmt.Println("Chunks not listed for the quota and purges", len(archives))
+		t.Fail()
+	}
+
+	// Small archives aren't split
+	ioutil.WriteFile("./2017-03-02-0-application.log", []byte("entry\n"), 0644)
+
+	if err := compressFile("./2017-03-02-0-application.log", gzipCodec{}, 0, 512*1024); err != nil || !fileExists("./2017-03-02-0-application.log.gz", t) {
+		fmt.Println("Small archive split", err)
+		t.Fail()
+	}
+}
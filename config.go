@@ -0,0 +1,433 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Config holds the options of gol, e.g. loaded from a JSON or YAML file, and applied by Start.
+// Start from DefaultConfig, as the zero values are not valid.
+type Config struct {
+	Level                string            `json:"level" yaml:"level"` // App log level, e.g. INFO
+	App                  ChannelConfig     `json:"app" yaml:"app"`
+	Public               ChannelConfig     `json:"public" yaml:"public"`
+	Error                ErrorLogConfig    `json:"error" yaml:"error"`
+	Console              ConsoleConfig     `json:"console" yaml:"console"`
+	DailyRotation        RotationConfig    `json:"daily_rotation" yaml:"daily_rotation"`
+	Compression          CompressionConfig `json:"compression" yaml:"compression"`
+	ArchiveManifest      bool              `json:"archive_manifest" yaml:"archive_manifest"`           // Manifest of the archives, see SetArchiveManifest
+	ShippingConfirmation bool              `json:"shipping_confirmation" yaml:"shipping_confirmation"` // Only shipped archives are removed, see SetShippingConfirmation
+	PersonalData         PersonalConfig    `json:"personal_data" yaml:"personal_data"`                 // Companion log of the personal data fields
+	Template             string            `json:"template" yaml:"template"`                           // Layout of the app log entries, see SetAppLogTemplate
+	Syslog               SyslogFileConfig  `json:"syslog" yaml:"syslog"`                               // Syslog line format of the app log file
+	LineNumbers          bool              `json:"line_numbers" yaml:"line_numbers"`
+	LineNumberLevels     []string          `json:"line_number_levels" yaml:"line_number_levels"` // Levels with line numbers if not empty, e.g. [WARN, ERROR, FATAL]
+	FunctionNames        bool              `json:"function_names" yaml:"function_names"`
+	SequenceNumbers      bool              `json:"sequence_numbers" yaml:"sequence_numbers"`
+	SequenceCheckpoints  time.Duration     `json:"sequence_checkpoints" yaml:"sequence_checkpoints"` // Interval of the sequence checkpoints, 0 for none
+	Synchronous          bool              `json:"synchronous" yaml:"synchronous"`
+	ShutdownReport       bool              `json:"shutdown_report" yaml:"shutdown_report"`
+	WriteBudget          int64             `json:"write_budget" yaml:"write_budget"`           // Bytes written per second to the log files, 0 for no limit
+	Anonymization        AnonymizeConfig   `json:"anonymization" yaml:"anonymization"`         // GDPR anonymization of the public access log
+	SampleRate           float64           `json:"sample_rate" yaml:"sample_rate"`             // Fraction of the public access log entries kept
+	MinFreeDisk          int64             `json:"min_free_disk" yaml:"min_free_disk"`         // in KB, 0 disables the watchdog
+	PurgeInterval        time.Duration     `json:"purge_interval" yaml:"purge_interval"`       // Time between two purges
+	PurgeJitter          time.Duration     `json:"purge_jitter" yaml:"purge_jitter"`           // Random extra time between two purges
+	RequestIDHeader      string            `json:"request_id_header" yaml:"request_id_header"` // Empty to not send the request ID
+}
+
+// ChannelConfig holds the options of a log file.
+type ChannelConfig struct {
+	Folder    string    `json:"folder" yaml:"folder"`
+	Name      string    `json:"name" yaml:"name"`         // File name, may contain the %pid% and %hostname% placeholders
+	MaxSize   int64     `json:"max_size" yaml:"max_size"` // in KB, the file is rotated once reached
+	MaxAge    int       `json:"max_age" yaml:"max_age"`   // in days, older files are purged
+	Quota     int64     `json:"quota" yaml:"quota"`       // in KB, 0 for no quota
+	Retention Retention `json:"retention" yaml:"retention"`
+}
+
+// SyslogFileConfig holds the syslog line format of the app log file, see SetAppLogSyslogFormat.
+type SyslogFileConfig struct {
+	Format   string `json:"format" yaml:"format"` // rfc3164, rfc5424 or empty for the gol layout
+	Facility int    `json:"facility" yaml:"facility"`
+	Tag      string `json:"tag" yaml:"tag"` // Default the name of the executable
+}
+
+// AnonymizeConfig holds the anonymization of the public access log, see SetAccessAnonymization.
+type AnonymizeConfig struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled"`
+	SaltRotation time.Duration `json:"salt_rotation" yaml:"salt_rotation"` // Default 24h
+	UserFields   []string      `json:"user_fields" yaml:"user_fields"`     // Fields with user identifiers, hashed
+}
+
+// PersonalConfig holds the options of the personal data log, see SetPersonalDataLog.
+type PersonalConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	MaxAge  int  `json:"max_age" yaml:"max_age"` // in days, shorter than the app log one
+}
+
+// RotationConfig holds the options of the daily rotation, see SetDailyRotation.
+type RotationConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Zone    string `json:"zone" yaml:"zone"` // UTC, Local (default) or an IANA zone, e.g. Europe/Paris
+	Hour    int    `json:"hour" yaml:"hour"` // Hour of the rotation in the zone, 0 to 23
+}
+
+// CompressionConfig holds the compression of the archives, see SetArchiveCompression.
+type CompressionConfig struct {
+	Codec     string `json:"codec" yaml:"codec"`           // gzip, or a codec registered by its sub-package (zstd, lz4), empty for none
+	Level     int    `json:"level" yaml:"level"`           // Level of the codec, 0 for its default
+	ChunkSize int    `json:"chunk_size" yaml:"chunk_size"` // in MB, the compressed archives are split in chunks, 0 for none
+}
+
+// ErrorLogConfig holds the options of the error log, see SetErrorLog.
+type ErrorLogConfig struct {
+	Enabled       bool `json:"enabled" yaml:"enabled"`
+	ChannelConfig `yaml:",inline"`
+}
+
+// ConsoleConfig holds the options of the standard output, see LogToStdout.
+type ConsoleConfig struct {
+	Enabled         bool   `json:"enabled" yaml:"enabled"`
+	Level           string `json:"level" yaml:"level"` // Empty to follow the app log level
+	JournalPrefixes bool   `json:"journal_prefixes" yaml:"journal_prefixes"`
+	Format          string `json:"format" yaml:"format"`             // text (default) or json
+	Colors          bool   `json:"colors" yaml:"colors"`             // Colors the levels of the text entries
+	ShortLevels     bool   `json:"short_levels" yaml:"short_levels"` // 3 letter levels, e.g. INF
+}
+
+// Returns the default options of gol.
+func DefaultConfig() Config {
+	return Config{
+		Level:           "INFO",
+		App:             ChannelConfig{Folder: "/var/log", Name: "application.log", MaxSize: 1024, MaxAge: 10},
+		Public:          ChannelConfig{Folder: "/var/log", Name: "access.log", MaxSize: 1024, MaxAge: 10},
+		Error:           ErrorLogConfig{ChannelConfig: ChannelConfig{Folder: "/var/log", Name: "error.log", MaxSize: 1024, MaxAge: 10}},
+		Console:         ConsoleConfig{Enabled: true},
+		LineNumbers:     true,
+		SampleRate:      1,
+		PurgeInterval:   1 * time.Minute,
+		RequestIDHeader: "X-Request-ID",
+	}
+}
+
+// ConfigProblem is an invalid option of a configuration.
+type ConfigProblem struct {
+	Option  string // Key of the option, e.g. app.max_size, or environment variable
+	Message string
+}
+
+// ErrInvalidConfig is the error of an invalid configuration, returned by Validate and Start.
+type ErrInvalidConfig struct {
+	Problems []ConfigProblem
+}
+
+func (e *ErrInvalidConfig) Error() string {
+
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.Message
+	}
+
+	return "invalid gol configuration: " + strings.Join(messages, ", ")
+}
+
+// Returns an *ErrInvalidConfig describing all the invalid options, nil if the configuration is valid.
+func (c Config) Validate() error {
+
+	var problems []ConfigProblem
+
+	if level, ok := parseLevel(c.Level); !ok || level == FATAL {
+		problems = append(problems, ConfigProblem{Option: "level", Message: "invalid level [" + c.Level + "]"})
+	}
+
+	for _, name := range c.LineNumberLevels {
+		if _, ok := parseLevel(name); !ok {
+			problems = append(problems, ConfigProblem{Option: "line_number_levels", Message: "invalid line number level [" + name + "]"})
+		}
+	}
+
+	if c.Console.Level != "" {
+		if level, ok := parseLevel(c.Console.Level); !ok || level == FATAL {
+			problems = append(problems, ConfigProblem{Option: "console.level", Message: "invalid console level [" + c.Console.Level + "]"})
+		}
+	}
+
+	if f := c.Console.Format; f != "" && f != TextFormat && f != JSONFormat {
+		problems = append(problems, ConfigProblem{Option: "console.format", Message: "invalid console format [" + f + "]"})
+	}
+
+	if c.PersonalData.Enabled && c.PersonalData.MaxAge <= 0 {
+		problems = append(problems, ConfigProblem{Option: "personal_data.max_age", Message: "personal data max age must be positive"})
+	}
+
+	if c.Template != "" {
+		if _, err := template.New("app").Funcs(templateFuncs).Parse(c.Template); err != nil {
+			problems = append(problems, ConfigProblem{Option: "template", Message: "invalid template: " + err.Error()})
+		}
+	}
+
+	if f := c.Syslog.Format; f != "" && f != RFC3164 && f != RFC5424 {
+		problems = append(problems, ConfigProblem{Option: "syslog.format", Message: "invalid syslog format [" + f + "]"})
+	}
+
+	if c.Syslog.Facility < 0 || c.Syslog.Facility > 23 {
+		problems = append(problems, ConfigProblem{Option: "syslog.facility", Message: "invalid syslog facility [" + strconv.Itoa(c.Syslog.Facility) + "]"})
+	}
+
+	if _, err := time.LoadLocation(c.DailyRotation.Zone); err != nil {
+		problems = append(problems, ConfigProblem{Option: "daily_rotation.zone", Message: "invalid daily rotation zone [" + c.DailyRotation.Zone + "]"})
+	}
+
+	if c.DailyRotation.Hour < 0 || c.DailyRotation.Hour > 23 {
+		problems = append(problems, ConfigProblem{Option: "daily_rotation.hour", Message: "daily rotation hour must be between 0 and 23"})
+	}
+
+	if c.Compression.Codec != "" {
+		codecsLock.RLock()
+		_, ok := codecs[c.Compression.Codec]
+		codecsLock.RUnlock()
+		if !ok {
+			problems = append(problems, ConfigProblem{Option: "compression.codec", Message: "unknown compression codec [" + c.Compression.Codec + "]"})
+		}
+	}
+
+	if c.Compression.ChunkSize < 0 {
+		problems = append(problems, ConfigProblem{Option: "compression.chunk_size", Message: "compression chunk size must be positive"})
+	}
+
+	problems = append(problems, c.App.problems("app")...)
+	problems = append(problems, c.Public.problems("public")...)
+
+	if c.Error.Enabled {
+		problems = append(problems, c.Error.problems("error")...)
+	}
+
+	if c.WriteBudget < 0 {
+		problems = append(problems, ConfigProblem{Option: "write_budget", Message: "write budget must be positive"})
+	}
+
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		problems = append(problems, ConfigProblem{Option: "sample_rate", Message: "sample rate must be between 0 and 1"})
+	}
+
+	if c.MinFreeDisk < 0 {
+		problems = append(problems, ConfigProblem{Option: "min_free_disk", Message: "min free disk must not be negative"})
+	}
+
+	if c.PurgeInterval <= 0 || c.PurgeJitter < 0 {
+		problems = append(problems, ConfigProblem{Option: "purge_interval", Message: "purge interval must be positive and purge jitter must not be negative"})
+	}
+
+	if len(problems) > 0 {
+		return &ErrInvalidConfig{Problems: problems}
+	}
+
+	return nil
+}
+
+func (c ChannelConfig) problems(name string) (problems []ConfigProblem) {
+
+	if c.Folder == "" {
+		problems = append(problems, ConfigProblem{Option: name + ".folder", Message: name + " folder is empty"})
+	}
+
+	if c.Name == "" || strings.ContainsAny(c.Name, "/\\") {
+		problems = append(problems, ConfigProblem{Option: name + ".name", Message: name + " file name is empty or contains a path separator"})
+	}
+
+	if c.MaxSize <= 0 {
+		problems = append(problems, ConfigProblem{Option: name + ".max_size", Message: name + " max size must be positive"})
+	}
+
+	if c.MaxAge <= 0 {
+		problems = append(problems, ConfigProblem{Option: name + ".max_age", Message: name + " max age must be positive"})
+	}
+
+	if c.Quota < 0 {
+		problems = append(problems, ConfigProblem{Option: name + ".quota", Message: name + " quota must not be negative"})
+	}
+
+	r := c.Retention
+	if r.All < 0 || r.Daily < 0 || r.Weekly < 0 || r.Monthly < 0 {
+		problems = append(problems, ConfigProblem{Option: name + ".retention", Message: name + " retention must not be negative"})
+	}
+
+	return problems
+}
+
+func (c ChannelConfig) apply(ch *channel) {
+	ch.folder = c.Folder
+	ch.name = expandFileName(c.Name)
+	ch.maxSize = c.MaxSize
+	ch.maxAge = c.MaxAge
+	ch.quota = c.Quota
+	ch.retention = c.Retention
+}
+
+// Applies the configuration, which must be valid.
+func (c Config) apply() {
+
+	c.App.apply(appChannel)
+	c.Public.apply(publicChannel)
+	c.Error.apply(errorChannel)
+	SetErrorLog(c.Error.Enabled)
+	SetPersonalDataLog(c.PersonalData.Enabled, c.PersonalData.MaxAge)
+
+	zone, _ := time.LoadLocation(c.DailyRotation.Zone)
+	if c.DailyRotation.Zone == "" {
+		zone = time.Local
+	}
+	SetDailyRotation(c.DailyRotation.Enabled, zone, c.DailyRotation.Hour)
+	SetArchiveCompression(c.Compression.Codec, c.Compression.Level)
+	SetArchiveChunkSize(c.Compression.ChunkSize)
+	SetArchiveManifest(c.ArchiveManifest)
+	SetShippingConfirmation(c.ShippingConfirmation)
+	SetAccessAnonymization(c.Anonymization.Enabled, c.Anonymization.SaltRotation, c.Anonymization.UserFields...)
+	SetAppLogTemplate(c.Template)
+	SetAppLogSyslogFormat(c.Syslog.Format, c.Syslog.Facility, c.Syslog.Tag)
+
+	LogToStdout(c.Console.Enabled)
+	SetJournalPrefixes(c.Console.JournalPrefixes)
+
+	SetSequenceNumbers(c.SequenceNumbers)
+	SetSequenceCheckpoints(c.SequenceCheckpoints)
+	SetSynchronous(c.Synchronous)
+	SetShutdownReport(c.ShutdownReport)
+	SetRequestIDHeader(c.RequestIDHeader)
+
+	c.applyRuntime()
+}
+
+// Returns an *ErrInvalidConfig if the settings applied, e.g. by the setters, can't be started
+// with: invalid level, sizes, ages or quotas, or log folders which can't be created or written, the
+// personal data and routed files included. The folders on a read-only filesystem are returned
+// instead, see SetReadOnlyFallback.
+func checkSettings() (readOnly []string, err error) {
+
+	var problems []ConfigProblem
+
+	if !isLevel(appLogLevel()) || appLogLevel() == FATAL {
+		problems = append(problems, ConfigProblem{Option: "level", Message: "invalid level " + strconv.Itoa(appLogLevel())})
+	}
+
+	// Settings of a log file as opened by Start
+	type settings struct {
+		name    string
+		folder  string
+		maxSize int64
+		maxAge  int
+		quota   int64
+	}
+
+	snapshot := func(name string, c *channel) settings {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		return settings{name: name, folder: c.folder, maxSize: c.maxSize, maxAge: c.maxAge, quota: c.quota}
+	}
+
+	app := snapshot("app", appChannel)
+	files := []settings{app, snapshot("public", publicChannel)}
+
+	if atomic.LoadInt32(&errorLogEnabled) == 1 {
+		files = append(files, snapshot("error", errorChannel))
+	}
+
+	// The personal data and routed files are written in the app log folder
+	if atomic.LoadInt32(&personalLogEnabled) == 1 {
+		personal := snapshot("personal_data", personalChannel)
+		personal.folder, personal.maxSize, personal.quota = app.folder, app.maxSize, 0
+		files = append(files, personal)
+	}
+
+	routeLock.RLock()
+	for _, r := range routes {
+		route := app
+		route.name, route.quota = "routes."+r.File, 0
+		files = append(files, route)
+	}
+	routeLock.RUnlock()
+
+	for _, f := range files {
+		name := f.name
+
+		if f.maxSize <= 0 {
+			problems = append(problems, ConfigProblem{Option: name + ".max_size", Message: name + " max size must be positive"})
+		}
+
+		if f.maxAge <= 0 {
+			problems = append(problems, ConfigProblem{Option: name + ".max_age", Message: name + " max age must be positive"})
+		}
+
+		if f.quota < 0 {
+			problems = append(problems, ConfigProblem{Option: name + ".quota", Message: name + " quota must not be negative"})
+		}
+
+		if err := folderCheck(f.folder); err != nil {
+			if fallbackEnabled() && isReadOnly(err) {
+				readOnly = append(readOnly, f.folder)
+				continue
+			}
+			problems = append(problems, ConfigProblem{Option: name + ".folder", Message: name + " folder [" + f.folder + "] not writable: " + err.Error()})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ErrInvalidConfig{Problems: problems}
+	}
+
+	return readOnly, nil
+}
+
+var folderCheck = checkFolder
+
+// Returns an error if the folder can't be created or a file written in it.
+func checkFolder(folder string) error {
+
+	if folder == "" {
+		return errors.New("empty folder")
+	}
+
+	if err := os.MkdirAll(folder, 0744); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(folder, ".gol-check")
+	if err != nil {
+		return err
+	}
+
+	f.Close()
+
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,10 @@
+module github.com/alexv99/gol/config/toml
+
+go 1.16
+
+replace github.com/alexv99/gol => ../..
+
+require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/alexv99/gol v0.0.0-00010101000000-000000000000
+)
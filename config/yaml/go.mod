@@ -0,0 +1,10 @@
+module github.com/alexv99/gol/config/yaml
+
+go 1.16
+
+replace github.com/alexv99/gol => ../..
+
+require (
+	github.com/alexv99/gol v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
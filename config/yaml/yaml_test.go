@@ -0,0 +1,65 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package yaml
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexv99/gol"
+)
+
+func TestLoadConfig(t *testing.T) {
+
+	path := "gol-config.yaml"
+	defer os.Remove(path)
+
+	ioutil.WriteFile(path, []byte(`
+level: DEBUG
+app: {folder: ./loaded, name: app.log, max_size: 2048, max_age: 3}
+error: {max_age: 2}
+console: {enabled: false}
+purge_interval: 5m
+`), 0644)
+
+	c, err := gol.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Level != "DEBUG" || c.App.Folder != "./loaded" || c.App.MaxSize != 2048 || c.Error.MaxAge != 2 ||
+		c.Console.Enabled || c.PurgeInterval != 5*time.Minute || c.Public.Name != "access.log" {
+		t.Errorf("Configuration not loaded %+v", c)
+	}
+
+	ioutil.WriteFile(path, []byte("levle: DEBUG\n"), 0644)
+
+	if _, err := gol.LoadConfig(path); err == nil || !strings.Contains(err.Error(), "levle") {
+		t.Errorf("Unknown key accepted %v", err)
+	}
+}
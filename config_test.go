@@ -0,0 +1,195 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+
+	if err := DefaultConfig().Validate(); err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	c := DefaultConfig()
+	c.Level = "LOUD"
+	c.App.Folder = ""
+	c.Public.MaxSize = 0
+	c.SampleRate = 2
+
+	err := c.Validate()
+
+	if err == nil || strings.Count(err.Error(), ", ") != 3 || !strings.Contains(err.Error(), "invalid level [LOUD]") {
+		fmt.Println("Unexpected validation", err)
+		t.Fail()
+	}
+
+	if started := Start(c); started == nil || started.Error() != err.Error() {
+		fmt.Println("Invalid configuration not rejected by Start")
+		t.Fail()
+	}
+}
+
+func TestStartWithConfig(t *testing.T) {
+	removeLogFiles(".")
+
+	c := DefaultConfig()
+
+	err := json.Unmarshal([]byte(`{
+		"level": "warn",
+		"app": {"folder": ".", "max_size": 1024, "retention": {"daily": 7}},
+		"public": {"folder": ".", "max_size": 1024},
+		"error": {"enabled": true, "folder": ".", "max_size": 10},
+		"console": {"enabled": false}
+	}`), &c)
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	defer SetAppLogLevel(INFO)
+	defer SetErrorLog(false)
+	defer SetAppLogRetention(Retention{})
+
+	err = Start(c)
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("not logged")
+	Error("logged")
+
+	Stop()
+
+	if appLogLevel() != WARN || stdoutEnabled() || appChannel.retention.Daily != 7 || errorChannel.maxSize != 10 {
+		fmt.Println("Configuration not applied")
+		t.Fail()
+	}
+
+	if !fileContains("./error.log", "logged", t) || strings.Contains(readFile("./application.log", t), "not logged") {
+		fmt.Println("Unexpected entries")
+		t.Fail()
+	}
+}
+
+func TestStartFailsFast(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder("./gol_test.go/logs") // Under a file
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(0)
+
+	defer SetAppLogFolder(".")
+	defer SetAppLogMaxSize(1024)
+
+	err, ok := Start().(*ErrInvalidConfig)
+	if !ok {
+		Stop()
+		t.Fatal("Invalid settings not rejected by Start")
+	}
+
+	options := map[string]bool{}
+	for _, p := range err.Problems {
+		options[p.Option] = true
+	}
+
+	if len(err.Problems) != 2 || !options["app.folder"] || !options["app.max_size"] {
+		fmt.Printf("Unexpected problems %+v\n", err.Problems)
+		t.Fail()
+	}
+
+	c := DefaultConfig()
+	c.Level = "LOUD"
+
+	if err, ok := c.Validate().(*ErrInvalidConfig); !ok || err.Problems[0].Option != "level" {
+		fmt.Println("Invalid option not detailed", err)
+		t.Fail()
+	}
+}
+
+func TestStartChecksEveryFile(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder("./gol_test.go/logs") // Under a file
+	SetPublicLogFolder(".")
+	SetAppLogMaxAge(0)
+	SetPublicLogQuota(-1)
+	SetPersonalDataLog(true, 0)
+	SetRoutes(Route{Package: "github.com/alexv99/gol/db", File: "db.log"})
+
+	defer SetAppLogFolder(".")
+	defer SetAppLogMaxAge(10)
+	defer SetPublicLogQuota(0)
+	defer SetPersonalDataLog(false, 1)
+	defer SetRoutes()
+
+	err, ok := Start().(*ErrInvalidConfig)
+	if !ok {
+		Stop()
+		t.Fatal("Invalid settings not rejected by Start")
+	}
+
+	options := map[string]bool{}
+	for _, p := range err.Problems {
+		options[p.Option] = true
+	}
+
+	for _, option := range []string{"app.folder", "app.max_age", "public.quota", "personal_data.folder", "personal_data.max_age",
+		"routes.db.log.folder", "routes.db.log.max_age"} {
+		if !options[option] {
+			fmt.Printf("Problem of %s not reported %+v\n", option, err.Problems)
+			t.Fail()
+		}
+	}
+
+	if options["app.quota"] || options["public.max_age"] {
+		fmt.Printf("Unexpected problems %+v\n", err.Problems)
+		t.Fail()
+	}
+
+	c := DefaultConfig()
+	c.App.MaxAge = 0
+	c.Public.Quota = -1
+
+	problems := map[string]bool{}
+	if err, ok := c.Validate().(*ErrInvalidConfig); ok {
+		for _, p := range err.Problems {
+			problems[p.Option] = true
+		}
+	}
+
+	if len(problems) != 2 || !problems["app.max_age"] || !problems["public.quota"] {
+		fmt.Println("Max age and quota not validated separately", problems)
+		t.Fail()
+	}
+}
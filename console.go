@@ -0,0 +1,199 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Formats of the app log entries printed to stdout.
+const (
+	TextFormat = "text" // As in the app log file
+	JSONFormat = "json" // One JSON object per line, with the time, level, msg and fields keys
+)
+
+var stdoutFormat atomic.Value // TextFormat or JSONFormat, see consoleFormat
+var stdoutColors int32        // 1 to color the levels
+var shortLevels int32         // 1 to print 3 letter levels
+
+var shortLevelNames = map[int]string{
+	DEBUG: "DBG",
+	INFO:  "INF",
+	WARN:  "WRN",
+	ERROR: "ERR",
+	FATAL: "FTL",
+}
+
+var levelColors = map[int]string{
+	DEBUG: "\x1b[90m",
+	INFO:  "\x1b[32m",
+	WARN:  "\x1b[33m",
+	ERROR: "\x1b[31m",
+	FATAL: "\x1b[1;31m",
+}
+
+// Sets the format of the app log entries printed to stdout, TextFormat (default) or JSONFormat.
+// Unknown formats are ignored.
+func SetStdoutFormat(format string) {
+	if format == TextFormat || format == JSONFormat {
+		stdoutFormat.Store(format)
+	}
+}
+
+// Returns the format of the app log entries printed to stdout.
+func consoleFormat() string {
+	if format, ok := stdoutFormat.Load().(string); ok {
+		return format
+	}
+	return TextFormat
+}
+
+// Colors the level of the text entries printed to stdout, e.g. when it is a terminal.
+func SetStdoutColors(enabled bool) {
+	atomic.StoreInt32(&stdoutColors, toggle(enabled))
+}
+
+// Prints the levels of the text entries on stdout as fixed width 3 letter tokens (DBG, INF, WRN,
+// ERR, FTL, the first 3 letters of the custom levels), so that the messages are aligned.
+func SetShortLevels(enabled bool) {
+	atomic.StoreInt32(&shortLevels, toggle(enabled))
+}
+
+// Returns the 3 letter token of the level.
+func shortLevelName(level int) string {
+
+	if name, ok := shortLevelNames[level]; ok {
+		return name
+	}
+
+	name := levelName(level) + "   "
+
+	return name[:3]
+}
+
+// Returns the entry formatted for stdout.
+func stdoutText(e *Entry) string {
+
+	if consoleFormat() == JSONFormat {
+		return formatJSON(e)
+	}
+
+	colors := atomic.LoadInt32(&stdoutColors) == 1
+	short := atomic.LoadInt32(&shortLevels) == 1
+
+	if !colors && !short {
+		return e.String()
+	}
+
+	name := levelName(e.Level)
+	if short {
+		name = shortLevelName(e.Level)
+	}
+	if colors {
+		name = levelColors[e.Level] + name + "\x1b[0m"
+	}
+
+	return strings.Replace(e.String(), levelPrefix(e.Level), " "+name+" [", 1)
+}
+
+// Formats the entry as a JSON object on one line, e.g.
+// {"time":"2017-03-01T10:00:00.000+01:00","level":"INFO","msg":"hello","user":"bob"}
+func formatJSON(e *Entry) string {
+
+	buf := make([]byte, 0, 256)
+
+	buf = append(buf, `{"time":`...)
+	buf = strconv.AppendQuote(buf, e.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendQuote(buf, levelName(e.Level))
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, e.Message)
+
+	for _, f := range append(e.Fields[:len(e.Fields):len(e.Fields)], loadGlobals().fields...) {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, f.Value)
+	}
+
+	if e.Sequence > 0 {
+		buf = append(buf, `,"seq":`...)
+		buf = strconv.AppendUint(buf, e.Sequence, 10)
+	}
+
+	if e.File != "" {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONString(buf, e.File+":"+strconv.Itoa(e.Line))
+	}
+
+	if e.Function != "" {
+		buf = append(buf, `,"func":`...)
+		buf = appendJSONString(buf, e.Function)
+	}
+
+	return string(append(buf, "}\n"...))
+}
+
+func appendJSONValue(buf []byte, value interface{}) []byte {
+
+	switch v := value.(type) {
+	case jsonValue:
+		return append(buf, v.render()...)
+	case rawValue:
+		return appendJSONString(buf, v.render())
+	case string:
+		return appendJSONString(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	case error:
+		return appendJSONString(buf, v.Error())
+	case fmt.Stringer:
+		return appendJSONString(buf, v.String())
+	}
+
+	if b, err := json.Marshal(value); err == nil {
+		return append(buf, b...)
+	}
+
+	return appendJSONString(buf, fmt.Sprint(value))
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
@@ -0,0 +1,162 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+var crashReports = false
+var crashEntries []string // Ring buffer of the last app log entries
+var crashNext int
+var crashLock = sync.Mutex{}
+
+// Enables writing a crash report file in the app log folder on Fatal and RecoverPanic. The report
+// contains the message, the stacks of all the goroutines, the build info and the last app log
+// entries, up to the number of entries (none if not positive).
+func SetCrashReports(enabled bool, entries int) {
+
+	crashLock.Lock()
+	defer crashLock.Unlock()
+
+	if entries < 0 {
+		entries = 0
+	}
+
+	crashReports = enabled
+	crashEntries = make([]string, 0, entries)
+	crashNext = 0
+}
+
+// Writes a crash report and panics again if the calling goroutine is panicking, use with defer:
+//
+//	defer gol.RecoverPanic()
+func RecoverPanic() {
+
+	if r := recover(); r != nil {
+		message := fmt.Sprint("panic: ", r)
+
+		if e := decorateAppLogEntry(FATAL, FATAL, nil, []interface{}{message}, 2); e != nil {
+			writeRunning(e)
+		}
+
+		writeCrashReport(message)
+
+		panic(r)
+	}
+}
+
+// Keeps the entry in the ring buffer of the crash reports.
+func recordCrashEntry(e *Entry) {
+
+	crashLock.Lock()
+	defer crashLock.Unlock()
+
+	if !crashReports || cap(crashEntries) == 0 {
+		return
+	}
+
+	if len(crashEntries) < cap(crashEntries) {
+		crashEntries = append(crashEntries, e.String())
+	} else {
+		crashEntries[crashNext] = e.String()
+		crashNext = (crashNext + 1) % len(crashEntries)
+	}
+}
+
+// Infix of the crash reports, e.g. application.log.crash-2017-03-01-100000, which doesn't match
+// the archives so that the quota, the retention and the watchdog never delete them.
+const crashSuffix = ".crash-"
+
+func writeCrashReport(message string) {
+
+	crashLock.Lock()
+	defer crashLock.Unlock()
+
+	if !crashReports {
+		return
+	}
+
+	appChannel.lock.RLock()
+	folder, name := appChannel.folder, appChannel.name
+	appChannel.lock.RUnlock()
+
+	now := time.Now()
+	path := folder + "/" + name + crashSuffix + now.Format("2006-01-02-150405")
+
+	report := "Crash report " + now.Format("2006-01-02 15:04:05") + "\n\n"
+	report += "Message: " + strings.TrimSpace(message) + "\n\n"
+	report += "Build info:\n" + buildInfo() + "\n"
+	report += "Last entries:\n"
+
+	for i := range crashEntries {
+		report += crashEntries[(crashNext+i)%len(crashEntries)]
+	}
+
+	report += "\nGoroutines:\n" + goroutineStacks()
+
+	err := os.WriteFile(path, []byte(report), os.FileMode(0644))
+	if err != nil {
+		log.Println("ERROR - Unable to write crash report ["+path+"]", err)
+	}
+}
+
+func buildInfo() string {
+
+	info := runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH + "\n"
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info += bi.Main.Path + " " + bi.Main.Version + "\n"
+
+	for _, dep := range bi.Deps {
+		info += "dep " + dep.Path + " " + dep.Version + "\n"
+	}
+
+	return info
+}
+
+func goroutineStacks() string {
+
+	buf := make([]byte, 64*1024)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
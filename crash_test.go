@@ -0,0 +1,169 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCrashReport(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetCrashReports(true, 10)
+	defer SetCrashReports(false, 0)
+
+	SetFatalHandler(func(message string) {})
+	defer SetFatalHandler(nil)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(INFO)
+
+	Info("before crash")
+
+	if !fileContains("./application.log", "before crash", t) {
+		t.FailNow()
+	}
+
+	Fatal("crash1")
+
+	report := crashReport(t)
+
+	for _, s := range []string{"crash1", "before crash", "goroutine", runtime.Version()} {
+		if !strings.Contains(report, s) {
+			fmt.Println("Missing [" + s + "] from crash report")
+			t.Fail()
+		}
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	removeLogFiles(".")
+
+	SetCrashReports(true, 10)
+	defer SetCrashReports(false, 0)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "panic1" {
+				fmt.Println("Panic not propagated")
+				t.Fail()
+			}
+		}()
+		defer RecoverPanic()
+
+		panic("panic1")
+	}()
+
+	if !strings.Contains(crashReport(t), "panic: panic1") {
+		fmt.Println("Missing panic from crash report")
+		t.Fail()
+	}
+}
+
+func crashReport(t *testing.T) string {
+
+	files, err := ioutil.ReadDir(".")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "application.log"+crashSuffix) {
+			b, err := ioutil.ReadFile(f.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(b)
+		}
+	}
+
+	t.Fatal("Missing crash report")
+
+	return ""
+}
+
+func TestCrashReportKept(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetCrashReports(true, 10)
+	defer SetCrashReports(false, 0)
+
+	writeCrashReport("crash2")
+
+	createOldFile("./2017-03-01-1-application.log", 2, t)
+	SetAppLogQuota(1)
+	defer SetAppLogQuota(0)
+
+	appChannel.enforceQuota()
+
+	archives, _ := appChannel.archives()
+	for _, f := range archives {
+		if strings.Contains(f.Name(), crashSuffix) {
+			fmt.Println("Crash report taken for an archive", f.Name())
+			t.Fail()
+		}
+	}
+
+	if !strings.Contains(crashReport(t), "crash2") {
+		fmt.Println("Crash report deleted by the quota")
+		t.Fail()
+	}
+}
+
+func TestCrashReportNegativeEntries(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetCrashReports(true, -1)
+	defer SetCrashReports(false, 0)
+
+	recordCrashEntry(&Entry{Level: INFO, Message: "not kept"})
+	writeCrashReport("crash3")
+
+	report := crashReport(t)
+
+	if !strings.Contains(report, "crash3") || strings.Contains(report, "not kept") {
+		fmt.Println("Unexpected crash report", report)
+		t.Fail()
+	}
+}
@@ -0,0 +1,97 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const runtimeCrashSuffix = ".runtime-crash" // Doesn't match the archives, see crashSuffix
+
+var crashOutputFile *os.File
+var crashOutputLock = sync.Mutex{}
+
+// Copies the output of the fatal runtime errors (unrecovered panics, out of memory, stack
+// overflows, concurrent map writes, SIGQUIT goroutine dumps...) into the <app log name>.runtime-crash
+// file of the app log folder (never purged), in addition to stderr, so that the crashes that gol can't
+// log are kept with the logs. Each process appends a header line with its pid before its crash
+// output. Requires Go 1.23 (runtime/debug.SetCrashOutput), returns an error otherwise.
+func SetCrashOutput(enabled bool) error {
+
+	crashOutputLock.Lock()
+	defer crashOutputLock.Unlock()
+
+	if !enabled {
+		if crashOutputFile == nil {
+			return nil
+		}
+		err := setRuntimeCrashOutput(nil)
+		crashOutputFile.Close()
+		crashOutputFile = nil
+		return err
+	}
+
+	if crashOutputFile != nil {
+		return nil
+	}
+
+	if err := setRuntimeCrashOutput(nil); err != nil {
+		return err // Not supported
+	}
+
+	path := crashOutputPath()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	header := "--- pid " + strconv.Itoa(os.Getpid()) + " started " + time.Now().Format("2006-01-02 15:04:05") + "\n"
+
+	if _, err := f.WriteString(header); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := setRuntimeCrashOutput(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	crashOutputFile = f
+
+	return nil
+}
+
+func crashOutputPath() string {
+
+	appChannel.lock.RLock()
+	defer appChannel.lock.RUnlock()
+
+	return appChannel.folder + "/" + appChannel.name + runtimeCrashSuffix
+}
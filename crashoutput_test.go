@@ -0,0 +1,71 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build go1.23
+// +build go1.23
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCrashOutput(t *testing.T) {
+
+	if os.Getenv("GOL_CRASH_CHILD") != "" {
+		SetAppLogFolder(".")
+		if err := SetCrashOutput(true); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		var m map[string]int
+		m["boom"]++ // Unrecovered panic
+	}
+
+	removeLogFiles(".")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashOutput$")
+	cmd.Env = append(os.Environ(), "GOL_CRASH_CHILD=1")
+
+	if err := cmd.Run(); err == nil {
+		fmt.Println("Child process not crashed")
+		t.FailNow()
+	}
+
+	report := readFile("./application.log"+runtimeCrashSuffix, t)
+
+	if !strings.HasPrefix(report, "--- pid ") || !strings.Contains(report, "assignment to entry in nil map") ||
+		!strings.Contains(report, "TestCrashOutput") {
+		fmt.Println("Unexpected crash output", report)
+		t.Fail()
+	}
+
+	if SetCrashOutput(false) != nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,69 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"testing"
+)
+
+func BenchmarkDecorateAppLogEntry(b *testing.B) {
+
+	v := []interface{}{"user logged in"}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		decorateAppLogEntry(INFO, INFO, nil, v, 1)
+	}
+}
+
+func BenchmarkDecorateAppLogEntryWithFields(b *testing.B) {
+
+	fields := []Field{{Key: "user", Value: "bob"}, {Key: "attempts", Value: 3}, {Key: "path", Value: "/a b"}}
+	v := []interface{}{"user logged in"}
+
+	SetGlobalFields(map[string]string{"app": "checkout", "env": "prod"})
+	defer SetGlobalFields(nil)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		decorateAppLogEntry(INFO, INFO, fields, v, 1)
+	}
+}
+
+func BenchmarkDecorateAppLogEntryWithoutLineNumbers(b *testing.B) {
+
+	v := []interface{}{"user logged in"}
+
+	ShowLineNumbers(false)
+	defer ShowLineNumbers(true)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		decorateAppLogEntry(INFO, INFO, nil, v, 1)
+	}
+}
@@ -0,0 +1,110 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"context"
+	"sync"
+)
+
+type diagnosticKey struct{}
+
+// DiagnosticContext holds the fields added to every entry logged with its context through
+// DebugContext, InfoContext, WarnContext and ErrorContext, like the MDC of log4j. Its fields can
+// change while the request or job runs, e.g. dc.Put("step", "validate"). It is safe for
+// concurrent use.
+type DiagnosticContext struct {
+	lock   sync.RWMutex
+	fields []Field
+}
+
+// Returns a context carrying a new diagnostic context, starting with the fields of the
+// diagnostic context of ctx if any.
+func NewDiagnosticContext(ctx context.Context) (context.Context, *DiagnosticContext) {
+
+	dc := &DiagnosticContext{}
+
+	if parent := DiagnosticContextFrom(ctx); parent != nil {
+		dc.fields = parent.Fields()
+	}
+
+	return context.WithValue(ctx, diagnosticKey{}, dc), dc
+}
+
+// Returns the diagnostic context carried by the context, nil if none.
+func DiagnosticContextFrom(ctx context.Context) *DiagnosticContext {
+
+	if ctx == nil {
+		return nil
+	}
+
+	dc, _ := ctx.Value(diagnosticKey{}).(*DiagnosticContext)
+
+	return dc
+}
+
+// Sets the field, replacing its previous value.
+func (dc *DiagnosticContext) Put(key string, value interface{}) {
+
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	for i, f := range dc.fields {
+		if f.Key == key {
+			// Copied, the entries already logged keep the previous fields
+			fields := append([]Field(nil), dc.fields...)
+			fields[i].Value = value
+			dc.fields = fields
+			return
+		}
+	}
+
+	dc.fields = append(dc.fields[:len(dc.fields):len(dc.fields)], Field{Key: key, Value: value})
+}
+
+// Removes the field.
+func (dc *DiagnosticContext) Remove(key string) {
+
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	fields := make([]Field, 0, len(dc.fields))
+	for _, f := range dc.fields {
+		if f.Key != key {
+			fields = append(fields, f)
+		}
+	}
+
+	dc.fields = fields
+}
+
+// Returns the fields, in the order they were first put.
+func (dc *DiagnosticContext) Fields() []Field {
+
+	dc.lock.RLock()
+	defer dc.lock.RUnlock()
+
+	return dc.fields[:len(dc.fields):len(dc.fields)]
+}
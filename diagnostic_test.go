@@ -0,0 +1,78 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestDiagnosticContext(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, dc := NewDiagnosticContext(context.Background())
+	dc.Put("job", "import-42")
+	dc.Put("step", "download")
+
+	InfoContext(ctx, "started")
+
+	dc.Put("step", "validate")
+	WarnContext(ctx, "invalid row")
+
+	// A child context starts with the fields of its parent
+	child, sub := NewDiagnosticContext(ctx)
+	sub.Put("row", 7)
+	dc.Remove("step")
+
+	ErrorContext(child, "rejected")
+	InfoContext(ctx, "done")
+
+	Stop()
+
+	path := "./application.log"
+
+	for _, s := range []string{
+		"[started] job=import-42 step=download",
+		"[invalid row] job=import-42 step=validate",
+		"[rejected] job=import-42 step=validate row=7",
+		"[done] job=import-42 at",
+	} {
+		if !fileContains(path, s, t) {
+			fmt.Println("Entry not found", s)
+			t.Fail()
+		}
+	}
+}
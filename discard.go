@@ -0,0 +1,71 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import "sync/atomic"
+
+var discardWrites int32 // 1 to count the writes without doing them
+
+// Counts the entries and bytes of the log files (see Stats) without writing them, e.g. for
+// load tests measuring the throughput of an app with its logging but without disk I/O.
+func SetDiscardWrites(enabled bool) {
+	atomic.StoreInt32(&discardWrites, toggle(enabled))
+}
+
+// DiscardSink is a sink counting the entries and bytes it receives per level, without any I/O.
+type DiscardSink struct {
+	entries [levelSlots]int64
+	bytes   [levelSlots]int64
+}
+
+func (s *DiscardSink) WriteEntry(e Entry) error {
+
+	if e.Level >= 0 && e.Level < levelSlots {
+		atomic.AddInt64(&s.entries[e.Level], 1)
+		atomic.AddInt64(&s.bytes[e.Level], int64(len(e.String())))
+	}
+
+	return nil
+}
+
+// Returns the number of entries of the level received.
+func (s *DiscardSink) Entries(level int) int64 {
+
+	if level < 0 || level >= levelSlots {
+		return 0
+	}
+
+	return atomic.LoadInt64(&s.entries[level])
+}
+
+// Returns the number of bytes of the entries of the level received, as formatted in the app log.
+func (s *DiscardSink) Bytes(level int) int64 {
+
+	if level < 0 || level >= levelSlots {
+		return 0
+	}
+
+	return atomic.LoadInt64(&s.bytes[level])
+}
@@ -0,0 +1,82 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDiscard(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	SetDiscardWrites(true)
+
+	defer SetSynchronous(false)
+	defer SetDiscardWrites(false)
+
+	sink := &DiscardSink{}
+	AddSink(sink, DEBUG)
+	defer RemoveSink(sink)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("one")
+	Info("two")
+	Warn("three")
+	Debug("sink only")
+	Public(http.Request{Method: "GET", URL: &url.URL{Path: "/"}, Header: http.Header{}}, 200, 10, time.Millisecond)
+
+	stats := Stats()
+
+	Stop()
+
+	if sink.Entries(INFO) != 2 || sink.Entries(WARN) != 1 || sink.Entries(DEBUG) != 1 || sink.Bytes(INFO) == 0 {
+		fmt.Println("Unexpected sink counts", sink.Entries(INFO), sink.Entries(WARN), sink.Entries(DEBUG), sink.Bytes(INFO))
+		t.Fail()
+	}
+
+	if stats.Entries["INFO"] != 2 || stats.Public != 1 || stats.Bytes == 0 {
+		fmt.Printf("Writes not accounted %+v\n", stats)
+		t.Fail()
+	}
+
+	for _, path := range []string{"./application.log", "./access.log"} {
+		if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+			fmt.Println("File written", path)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Returns the options of the GOL_* environment variables, see Start.
+func envOptions(getenv func(string) string) (opts []Option) {
+
+	if value := getenv("GOL_LEVEL"); value != "" {
+		if level, ok := parseEnvLevel(value); ok {
+			opts = append(opts, WithLevel(level))
+		} else {
+			opts = append(opts, invalidEnv("GOL_LEVEL", value))
+		}
+	}
+
+	if value := getenv("GOL_APP_LOG_DIR"); value != "" {
+		opts = append(opts, WithAppLogFolder(value))
+	}
+
+	if value := getenv("GOL_PUBLIC_LOG_DIR"); value != "" {
+		opts = append(opts, WithPublicLogFolder(value))
+	}
+
+	if value := getenv("GOL_MAX_SIZE"); value != "" {
+		if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+			opts = append(opts, WithMaxSize(size))
+		} else {
+			opts = append(opts, invalidEnv("GOL_MAX_SIZE", value))
+		}
+	}
+
+	if value := getenv("GOL_MAX_AGE"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil {
+			opts = append(opts, WithMaxAge(days))
+		} else {
+			opts = append(opts, invalidEnv("GOL_MAX_AGE", value))
+		}
+	}
+
+	if value := getenv("GOL_STDOUT"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			opts = append(opts, WithStdout(enabled))
+		} else {
+			opts = append(opts, invalidEnv("GOL_STDOUT", value))
+		}
+	}
+
+	if value := getenv("GOL_STDOUT_FORMAT"); value != "" {
+		if format := strings.ToLower(value); format == TextFormat || format == JSONFormat {
+			opts = append(opts, option{set: func() { SetStdoutFormat(format) }})
+		} else {
+			opts = append(opts, invalidEnv("GOL_STDOUT_FORMAT", value))
+		}
+	}
+
+	return opts
+}
+
+// Returns an option failing the validation of Start.
+func invalidEnv(name string, value string) Option {
+	return option{check: func() error {
+		return invalidOption(name, "invalid "+name+" ["+value+"]")
+	}}
+}
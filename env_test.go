@@ -0,0 +1,83 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestEnvironmentConfig(t *testing.T) {
+	removeLogFiles(".")
+	os.RemoveAll("./env")
+	defer os.RemoveAll("./env")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetSynchronous(true)
+
+	env := map[string]string{
+		"GOL_LEVEL":       "debug",
+		"GOL_APP_LOG_DIR": "./env",
+		"GOL_MAX_SIZE":    "2048",
+		"GOL_STDOUT":      "false",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	defer func() {
+		SetAppLogFolder(".")
+		SetAppLogLevel(INFO)
+		SetAppLogMaxSize(1024)
+		SetPublicLogMaxSize(1024)
+		SetSynchronous(false)
+	}()
+
+	// The environment overrides the options
+	if err := Start(WithLevel(ERROR), WithStdout(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	Debug("configured by the environment")
+
+	Stop()
+
+	if !fileContains("./env/application.log", "DEBUG [configured by the environment]", t) || stdoutEnabled() || appChannel.maxSize != 2048 {
+		fmt.Println("Environment not applied")
+		t.Fail()
+	}
+
+	os.Setenv("GOL_MAX_AGE", "a week")
+	defer os.Unsetenv("GOL_MAX_AGE")
+
+	if err, ok := Start().(*ErrInvalidConfig); !ok || len(err.Problems) != 1 || err.Problems[0].Option != "GOL_MAX_AGE" {
+		fmt.Println("Invalid environment variable accepted", err)
+		t.Fail()
+		Stop()
+	}
+}
@@ -0,0 +1,241 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+)
+
+// Erasure is a right to erasure request: the entries of a log with a field, e.g. user_id=123.
+type Erasure struct {
+	Key   string
+	Value string
+	Mask  bool // Replaces the value of the field with "erased" instead of removing the entries
+}
+
+// ErasureReport describes the archives rewritten by Erase.
+type ErasureReport struct {
+	Files   []string // Paths of the rewritten archives
+	Entries int      // Number of entries removed or masked
+}
+
+const erasedValue = "erased"
+
+var entryStartPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} `)
+
+// Rewrites the archives of the log name in the folder (app or public access log, compressed or
+// not) without the entries matching the erasure, or with their field masked. Each archive is
+// rewritten into a temporary file, with its codec, read back and checked against what was written
+// before it replaces the original, keeping its modification time. The manifest of the archives,
+// if any, is updated. The current file is not rewritten: the erasure must be repeated once it is
+// archived.
+func Erase(folder string, name string, erasure Erasure) (ErasureReport, error) {
+
+	var report ErasureReport
+
+	if erasure.Key == "" {
+		return report, errors.New("erasure without a key")
+	}
+
+	paths, err := logFiles(folder, name)
+	if err != nil {
+		return report, err
+	}
+
+	token := string(appendField(nil, Field{Key: erasure.Key, Value: erasure.Value}))
+	match := regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(token) + `(\s|$)`)
+	masked := "${1}" + string(appendField(nil, Field{Key: erasure.Key, Value: erasedValue})) + "${2}"
+
+	for _, path := range paths {
+		if path == folder+"/"+name {
+			continue // Current file
+		}
+
+		n, err := eraseArchive(path, match, masked, erasure.Mask)
+		if err != nil {
+			return report, err
+		}
+
+		if n > 0 {
+			report.Files = append(report.Files, path)
+			report.Entries += n
+		}
+	}
+
+	if _, err := os.Stat(folder + "/" + name + manifestSuffix); err == nil && len(report.Files) > 0 {
+		(&channel{folder: folder, name: name}).writeManifest()
+	}
+
+	return report, nil
+}
+
+// Rewrites the archive without the matching entries, or with their field masked. Returns
+// the number of matching entries, 0 if the archive was left untouched.
+func eraseArchive(path string, match *regexp.Regexp, masked string, mask bool) (int, error) {
+
+	codec := codecOf(path)
+
+	content, err := readArchive(path, codec)
+	if err != nil {
+		return 0, err
+	}
+
+	var kept bytes.Buffer
+	erased := 0
+
+	for _, entry := range splitEntries(content) {
+		if !match.Match(entry) {
+			kept.Write(entry)
+			continue
+		}
+
+		erased++
+		if mask {
+			kept.Write(match.ReplaceAll(entry, []byte(masked)))
+		}
+	}
+
+	if erased == 0 {
+		return 0, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp := path + ".tmp"
+	defer os.Remove(tmp)
+
+	if err := writeArchive(tmp, codec, kept.Bytes()); err != nil {
+		return 0, err
+	}
+
+	// Verified before the original is replaced
+	written, err := readArchive(tmp, codec)
+	if err != nil {
+		return 0, err
+	}
+
+	if sha256.Sum256(written) != sha256.Sum256(kept.Bytes()) || match.Match(written) {
+		return 0, errors.New("erasure of [" + path + "] not verified, archive left untouched")
+	}
+
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+
+	return erased, os.Rename(tmp, path)
+}
+
+// Returns the entries of the content, with their line breaks. A schema header is an entry.
+func splitEntries(content []byte) [][]byte {
+
+	var entries [][]byte
+	start := 0
+
+	for i := 0; i < len(content); {
+		end := bytes.IndexByte(content[i:], '\n') + 1
+		if end == 0 {
+			end = len(content) - i
+		}
+
+		line := content[i : i+end]
+		if i > start && (entryStartPattern.Match(line) || bytes.HasPrefix(line, []byte(schemaHeaderPrefix))) {
+			entries = append(entries, content[start:i])
+			start = i
+		}
+
+		i += end
+	}
+
+	if start < len(content) {
+		entries = append(entries, content[start:])
+	}
+
+	return entries
+}
+
+// Returns the uncompressed content of the archive, compressed with the codec if not nil.
+func readArchive(path string, codec Codec) ([]byte, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+
+	if codec != nil {
+		dec, err := codec.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		r = dec
+	}
+
+	return ioutil.ReadAll(r)
+}
+
+// Writes the content in the archive, compressed with the codec if not nil.
+func writeArchive(path string, codec Codec, content []byte) error {
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	if codec == nil {
+		w.Write(content)
+	} else {
+		_, level, _ := archiveCompression()
+		enc, err := codec.NewWriter(w, level)
+		if err != nil {
+			return err
+		}
+		if _, err := enc.Write(content); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
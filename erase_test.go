@@ -0,0 +1,92 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestErase(t *testing.T) {
+	removeLogFiles(".")
+
+	entries := "2026-01-02 10:00:00 INFO [login] user_id=123 at main.go:10\n" +
+		"2026-01-02 10:00:01 ERROR [failed\n    with details] user_id=123\n" +
+		"2026-01-02 10:00:02 INFO [login] user_id=1234\n"
+
+	ioutil.WriteFile("./2026-01-02-1-application.log", []byte(entries), 0644)
+	ioutil.WriteFile("./2026-01-03-1-application.log", []byte(entries), 0644)
+	ioutil.WriteFile("./2026-01-04-1-application.log", []byte("2026-01-04 10:00:00 INFO [other] user_id=9\n"), 0644)
+	ioutil.WriteFile("./application.log", []byte(entries), 0644)
+
+	if err := compressFile("./2026-01-03-1-application.log", gzipCodec{}, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Erase(".", "application.log", Erasure{Key: "user_id", Value: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Entries != 4 || len(report.Files) != 2 {
+		fmt.Printf("Unexpected report %+v\n", report)
+		t.Fail()
+	}
+
+	expected := "2026-01-02 10:00:02 INFO [login] user_id=1234\n"
+
+	if text := readFile("./2026-01-02-1-application.log", t); text != expected {
+		fmt.Printf("Entries not erased %q\n", text)
+		t.Fail()
+	}
+
+	if content, err := readArchive("./2026-01-03-1-application.log.gz", gzipCodec{}); err != nil || string(content) != expected {
+		fmt.Printf("Entries not erased from the compressed archive %q %v\n", content, err)
+		t.Fail()
+	}
+
+	if !fileContains("./application.log", "user_id=123 ", t) {
+		fmt.Println("Current file rewritten")
+		t.Fail()
+	}
+
+	// Masking
+	ioutil.WriteFile("./2026-01-02-1-application.log", []byte(entries), 0644)
+
+	report, err = Erase(".", "application.log", Erasure{Key: "user_id", Value: "123", Mask: true})
+	if err != nil || report.Entries != 2 {
+		fmt.Printf("Unexpected report %+v %v\n", report, err)
+		t.Fail()
+	}
+
+	if text := readFile("./2026-01-02-1-application.log", t); text != strings.NewReplacer("user_id=123 ", "user_id=erased ", "user_id=123\n", "user_id=erased\n").Replace(entries) {
+		fmt.Printf("Entries not masked %q\n", text)
+		t.Fail()
+	}
+
+	removeLogFiles(".")
+}
@@ -0,0 +1,68 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync/atomic"
+)
+
+var errorChannel = &channel{folder: "/var/log", name: "error.log", maxSize: 1024, maxAge: 10}
+
+var errorLogEnabled int32 // Set by SetErrorLog, applied by Start
+var errorLogActive int32  // 1 if the ERROR and FATAL entries are also written in the error log
+
+// Additionally writes the ERROR and FATAL entries of the app log in a separate error log,
+// rotated and purged on its own. Applied by the next Start.
+func SetErrorLog(enabled bool) {
+	atomic.StoreInt32(&errorLogEnabled, toggle(enabled))
+}
+
+func SetErrorLogFolder(path string) {
+	errorChannel.update(func() { errorChannel.folder = path })
+}
+
+func SetErrorLogMaxSize(size int64) {
+	errorChannel.update(func() { errorChannel.maxSize = size })
+}
+
+func SetErrorLogMaxAge(age int) {
+	errorChannel.update(func() { errorChannel.maxAge = age })
+}
+
+// Returns the channels of the log files written by the running logger.
+func logChannels() []*channel {
+
+	channels := []*channel{appChannel, publicChannel}
+
+	if atomic.LoadInt32(&errorLogActive) == 1 {
+		channels = append(channels, errorChannel)
+	}
+
+	if atomic.LoadInt32(&personalLogActive) == 1 {
+		channels = append(channels, personalChannel)
+	}
+
+	return append(channels, routedChannels()...)
+}
@@ -0,0 +1,72 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorLog(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetErrorLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetErrorLog(true)
+	defer SetErrorLog(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("info not in error log")
+	Warn("warn not in error log")
+	Error("error in error log")
+
+	Stop()
+
+	if !fileContains("./application.log", "info not in error log", t) ||
+		!fileContains("./application.log", "error in error log", t) {
+		fmt.Println("Missing entries in the app log")
+		t.Fail()
+	}
+
+	if !fileContains("./error.log", "error in error log", t) {
+		fmt.Println("Missing entry in the error log")
+		t.Fail()
+	}
+
+	if content := readFile("./error.log", t); strings.Contains(content, "not in error log") {
+		fmt.Println("Unexpected entries in the error log", content)
+		t.Fail()
+	}
+}
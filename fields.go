@@ -0,0 +1,227 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var fieldMaxItems int32 = 20 // Maximum number of items rendered for a slice or map field
+var fieldMaxDepth int32 = 3  // Maximum nesting rendered for a slice or map field
+var jsonMaxSize int32 = 4096 // Maximum number of bytes rendered for a JSON field
+
+// Rendered as is by formatField, without quoting.
+type rawValue interface {
+	render() string
+}
+
+// Slice, array or map rendered as a structured array or object.
+type collection struct {
+	v interface{}
+}
+
+// Returns a field rendering a slice, array or map as a structured array (or object for maps),
+// e.g. ids=[1,2,3], capped by SetFieldLimits.
+func Slice(key string, v interface{}) Field {
+	return Field{Key: key, Value: collection{v: v}}
+}
+
+// Returns a field rendering the errors as a structured array, e.g. errs=["timeout","EOF"].
+func Errors(key string, errs []error) Field {
+	return Field{Key: key, Value: collection{v: errs}}
+}
+
+// Object serialized as JSON when rendered.
+type jsonValue struct {
+	v interface{}
+}
+
+// Returns a field serializing the object as JSON, only if the entry is actually logged. The JSON
+// beyond the size set with SetJSONMaxSize is truncated (and the value quoted).
+func JSON(key string, v interface{}) Field {
+	return Field{Key: key, Value: jsonValue{v: v}}
+}
+
+// Sets the maximum number of bytes rendered for JSON fields, ignored if not positive.
+func SetJSONMaxSize(size int) {
+	if size > 0 {
+		atomic.StoreInt32(&jsonMaxSize, int32(size))
+	}
+}
+
+func (j jsonValue) render() string {
+
+	b, err := json.Marshal(j.v)
+	if err != nil {
+		return strconv.Quote("!error: " + err.Error())
+	}
+
+	if max := int(atomic.LoadInt32(&jsonMaxSize)); len(b) > max {
+		return strconv.Quote(string(b[:max]) + "...(+" + strconv.Itoa(len(b)-max) + " bytes)")
+	}
+
+	return string(b)
+}
+
+func (j jsonValue) String() string {
+	return j.render()
+}
+
+// Sets the maximum number of items and the maximum nesting depth rendered for slice and map fields,
+// a limit which isn't positive being ignored.
+func SetFieldLimits(maxItems int, maxDepth int) {
+	if maxItems > 0 {
+		atomic.StoreInt32(&fieldMaxItems, int32(maxItems))
+	}
+	if maxDepth > 0 {
+		atomic.StoreInt32(&fieldMaxDepth, int32(maxDepth))
+	}
+}
+
+func (c collection) render() string {
+	var b strings.Builder
+	renderValue(&b, reflect.ValueOf(c.v), 0)
+	return b.String()
+}
+
+func (c collection) String() string {
+	return c.render()
+}
+
+func renderValue(b *strings.Builder, v reflect.Value, depth int) {
+
+	if !v.IsValid() {
+		b.WriteString("null")
+		return
+	}
+
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			b.WriteString("null")
+			return
+		}
+		if err, ok := v.Interface().(error); ok {
+			b.WriteString(strconv.Quote(err.Error()))
+			return
+		}
+		renderValue(b, v.Elem(), depth)
+		return
+	}
+
+	maxItems, maxDepth := int(atomic.LoadInt32(&fieldMaxItems)), int(atomic.LoadInt32(&fieldMaxDepth))
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if depth >= maxDepth {
+			b.WriteString(`"..."`)
+			return
+		}
+		b.WriteString("[")
+		for i := 0; i < v.Len() && i < maxItems; i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			renderValue(b, v.Index(i), depth+1)
+		}
+		writeMore(b, v.Len(), maxItems)
+		b.WriteString("]")
+
+	case reflect.Map:
+		if depth >= maxDepth {
+			b.WriteString(`"..."`)
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		b.WriteString("{")
+		for i := 0; i < len(keys) && i < maxItems; i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(strconv.Quote(fmt.Sprint(keys[i].Interface())) + ":")
+			renderValue(b, v.MapIndex(keys[i]), depth+1)
+		}
+		writeMore(b, len(keys), maxItems)
+		b.WriteString("}")
+
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		b.WriteString(fmt.Sprint(v.Interface()))
+
+	default:
+		if v.CanInterface() {
+			b.WriteString(strconv.Quote(fmt.Sprint(v.Interface())))
+		} else {
+			b.WriteString(strconv.Quote(v.String()))
+		}
+	}
+}
+
+// Writes the number of items left out of a collection.
+func writeMore(b *strings.Builder, length int, maxItems int) {
+
+	if length > maxItems {
+		b.WriteString(",")
+		b.WriteString(strconv.Quote("+" + strconv.Itoa(length-maxItems) + " more"))
+	}
+}
+
+// Moves the fields passed as log arguments to the entry fields.
+func splitFields(v []interface{}, fields []Field) ([]interface{}, []Field) {
+
+	found := false
+	for _, arg := range v {
+		if _, ok := arg.(Field); ok {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return v, fields
+	}
+
+	args := make([]interface{}, 0, len(v))
+	all := make([]Field, len(fields), len(fields)+len(v))
+	copy(all, fields)
+
+	for _, arg := range v {
+		if f, ok := arg.(Field); ok {
+			all = append(all, f)
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	return args, all
+}
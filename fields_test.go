@@ -0,0 +1,100 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCollectionFields(t *testing.T) {
+
+	SetFieldLimits(3, 2)
+	defer SetFieldLimits(20, 3)
+	SetFieldLimits(0, -1) // Ignored
+
+	tests := []struct {
+		field    Field
+		expected string
+	}{
+		{Errors("errs", []error{errors.New("timeout"), nil}), `errs=["timeout",null]`},
+		{Slice("ids", []int{1, 2, 3, 4, 5}), `ids=[1,2,3,"+2 more"]`},
+		{Slice("tags", map[string]bool{"b": true, "a": false}), `tags={"a":false,"b":true}`},
+		{Slice("nested", [][]string{{"a"}, {"b c"}}), `nested=[["a"],["b c"]]`},
+		{Slice("deep", [][][]int{{{1}}}), `deep=[["..."]]`},
+	}
+
+	for _, test := range tests {
+		if s := formatField(test.field); s != test.expected {
+			fmt.Println("Expected " + test.expected + " got " + s)
+			t.Fail()
+		}
+	}
+}
+
+type expensive struct {
+	calls *int
+}
+
+func (e expensive) MarshalJSON() ([]byte, error) {
+	*e.calls++
+	return []byte(`{"name":"gol","size":1234567890}`), nil
+}
+
+func TestJSONField(t *testing.T) {
+
+	if s := formatField(JSON("payload", map[string]int{"a": 1})); s != `payload={"a":1}` {
+		fmt.Println("Unexpected JSON field " + s)
+		t.Fail()
+	}
+
+	SetJSONMaxSize(10)
+	defer SetJSONMaxSize(4096)
+	SetJSONMaxSize(-1) // Ignored
+
+	calls := 0
+	obj := expensive{calls: &calls}
+
+	if s := formatField(JSON("payload", obj)); s != `payload="{\"name\":\"g...(+22 bytes)"` {
+		fmt.Println("Unexpected truncated JSON field " + s)
+		t.Fail()
+	}
+
+	if decorateAppLogEntry(DEBUG, INFO, nil, []interface{}{JSON("payload", obj)}, 2) != nil || calls != 1 {
+		fmt.Println("JSON field serialized for a filtered entry")
+		t.Fail()
+	}
+}
+
+func TestFieldArguments(t *testing.T) {
+
+	e := decorateAppLogEntry(INFO, INFO, []Field{{Key: "a", Value: 1}}, []interface{}{"saving", Errors("errs", nil), "user"}, 2)
+
+	if e.Message != "saving user" || len(e.Fields) != 2 || e.Fields[1].Key != "errs" {
+		fmt.Println("Fields not extracted from the arguments: " + e.String())
+		t.Fail()
+	}
+}
@@ -0,0 +1,95 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// FIFOSink is a sink writing the app log entries to a named pipe (see mkfifo) without ever
+// blocking: the entries are dropped while no process reads the pipe, or when the pipe is full.
+type FIFOSink struct {
+	path    string
+	fd      int // -1 until a reader opens the pipe
+	lock    sync.Mutex
+	dropped int64
+}
+
+// Returns a sink writing the entries to the named pipe, which must exist.
+func NewFIFOSink(path string) (*FIFOSink, error) {
+
+	if err := checkFIFO(path); err != nil {
+		return nil, err
+	}
+
+	return &FIFOSink{path: path, fd: -1}, nil
+}
+
+func (s *FIFOSink) WriteEntry(e Entry) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fd == -1 {
+		fd, err := openFIFO(s.path)
+		if err != nil {
+			atomic.AddInt64(&s.dropped, 1) // No reader yet
+			return nil
+		}
+		s.fd = fd
+	}
+
+	if err := writeFIFO(s.fd, []byte(e.String())); err != nil {
+		atomic.AddInt64(&s.dropped, 1)
+		if !fifoFull(err) {
+			closeFIFO(s.fd) // Reader gone, reopened on the next entry
+			s.fd = -1
+		}
+	}
+
+	return nil
+}
+
+// Returns the number of entries dropped, for lack of a reader or of room in the pipe.
+func (s *FIFOSink) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Closes the pipe.
+func (s *FIFOSink) Close() error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fd == -1 {
+		return nil
+	}
+
+	err := closeFIFO(s.fd)
+	s.fd = -1
+
+	return err
+}
@@ -0,0 +1,88 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestFIFOSink(t *testing.T) {
+
+	path := "./gol-test.fifo"
+	os.Remove(path)
+	defer os.Remove(path)
+
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skip("mkfifo unsupported", err)
+	}
+
+	if _, err := NewFIFOSink("./gol_test.go"); err == nil {
+		fmt.Println("Regular file accepted as a named pipe")
+		t.Fail()
+	}
+
+	sink, err := NewFIFOSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	sink.WriteEntry(Entry{text: "no reader\n"})
+
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := os.NewFile(uintptr(fd), path)
+
+	sink.WriteEntry(Entry{text: "read\n"})
+
+	buf := make([]byte, 100)
+	n, _ := reader.Read(buf)
+
+	if string(buf[:n]) != "read\n" {
+		fmt.Printf("Unexpected pipe content %q\n", buf[:n])
+		t.Fail()
+	}
+
+	// Pipe full
+	sink.WriteEntry(Entry{text: strings.Repeat("x", 1<<20) + "\n"})
+
+	// Reader gone
+	reader.Close()
+	sink.WriteEntry(Entry{text: "reader gone\n"})
+
+	if sink.Dropped() != 3 {
+		fmt.Println("Unexpected number of dropped entries", sink.Dropped())
+		t.Fail()
+	}
+}
@@ -0,0 +1,74 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package gol
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Returns an error if the path is not a named pipe.
+func checkFIFO(path string) error {
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return errors.New("[" + path + "] is not a named pipe")
+	}
+
+	return nil
+}
+
+// Opens the pipe for non blocking writes, failing while it has no reader.
+func openFIFO(path string) (int, error) {
+	return syscall.Open(path, syscall.O_WRONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+}
+
+// Writes the message at once, or fails if the pipe is full. Messages up to PIPE_BUF bytes
+// are never interleaved nor partially written.
+func writeFIFO(fd int, b []byte) error {
+
+	n, err := syscall.Write(fd, b)
+	if err == nil && n < len(b) {
+		return syscall.EAGAIN
+	}
+
+	return err
+}
+
+func fifoFull(err error) bool {
+	return err == syscall.EAGAIN
+}
+
+func closeFIFO(fd int) error {
+	return syscall.Close(fd)
+}
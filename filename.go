@@ -0,0 +1,66 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Sets the name of the app log file (default application.log). The placeholders %pid% and
+// %hostname% are replaced, e.g. application-%pid%.log, so that the instances sharing a host or a
+// volume write and rotate their own files. Each instance only purges the archives of its own name.
+func SetAppLogFileName(name string) {
+	appChannel.update(func() { appChannel.name = expandFileName(name) })
+}
+
+// Sets the name of the public access log file (default access.log), see SetAppLogFileName.
+func SetPublicLogFileName(name string) {
+	publicChannel.update(func() { publicChannel.name = expandFileName(name) })
+}
+
+// Sets the name of the error log file (default error.log), see SetAppLogFileName.
+func SetErrorLogFileName(name string) {
+	errorChannel.update(func() { errorChannel.name = expandFileName(name) })
+}
+
+// Replaces the %pid% and %hostname% placeholders of the file name.
+func expandFileName(name string) string {
+
+	if !strings.Contains(name, "%") {
+		return name
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	}
+
+	return strings.NewReplacer(
+		"%pid%", strconv.Itoa(os.Getpid()),
+		"%hostname%", strings.ReplaceAll(hostname, "/", "_"),
+	).Replace(name)
+}
@@ -0,0 +1,182 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build go1.18
+// +build go1.18
+
+package gol
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+)
+
+// Run with e.g. go test -run=^$ -fuzz=FuzzParseLogLine
+
+func FuzzParseLogLine(f *testing.F) {
+
+	f.Add("2017-03-01 10:00:00 INFO [hello] user=bob seq=3 at gol.go:12 in main.main")
+	f.Add(`2017-03-01 10:00:00 WARN [a ] b] q="x \"y\"" data={"a":[1,2]}`)
+	f.Add("2017-03-01 10:00:00 ERROR [multi\nline] k=v")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		e, err := ParseLogLine(s)
+		if err != nil {
+			return
+		}
+		for _, field := range e.Fields {
+			if _, ok := field.Value.(string); !ok {
+				t.Fatalf("Field %v of [%s] isn't a string", field, s)
+			}
+		}
+	})
+}
+
+func FuzzAppLogRoundTrip(f *testing.F) {
+
+	f.Add("hello", "user", "bob")
+	f.Add("a ] b", "q", `x "y" = z`)
+	f.Add("x", "k", "a]")
+	f.Add("x", "k", "[{")
+
+	f.Fuzz(func(t *testing.T, message string, key string, value string) {
+
+		// Messages end with the first "]" followed by fields, keys are plain words
+		if strings.ContainsAny(message, "]\r\n") || key == "" || strings.ContainsAny(key, " \t\r\n\"=[]{}") {
+			return
+		}
+
+		e := decorateAppLogEntry(INFO, INFO, []Field{{Key: key, Value: value}}, []interface{}{message}, 1)
+
+		parsed, err := ParseLogLine(e.String())
+		if err != nil {
+			t.Fatalf("Unable to parse [%s]: %v", e.String(), err)
+		}
+
+		if parsed.Message != message || len(parsed.Fields) != 1 || parsed.Fields[0].Value != value {
+			t.Fatalf("Round trip of [%s] returned %q %v", e.String(), parsed.Message, parsed.Fields)
+		}
+	})
+}
+
+func FuzzParseAccessLine(f *testing.F) {
+
+	f.Add("2017-03-01 10:00:00 GET /a HTTP/1.1 from [1.2.3.4] with agent [curl] in 12ms => 200 with 5 bytes route=/a sampled at 50% seq=4")
+	f.Add("2017-03-01 10:00:00 GET /a HTTP/1.1 from [] with agent [] in 3μs => 500 with 0 bytes had_errors=true errors=2 sampled at")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		ParseAccessLine(s)
+	})
+}
+
+func FuzzAccessLogRoundTrip(f *testing.F) {
+
+	f.Add("/a?b=c", "1.2.3.4", "Mozilla/5.0 (X11)", int64(1500000), 200)
+	f.Add("/", "", "] with agent [", int64(0), 0)
+
+	f.Fuzz(func(t *testing.T, url string, remoteAddr string, userAgent string, duration int64, status int) {
+
+		// The line is split on its keywords and spaces, which the request line can't contain
+		if strings.IndexFunc(url, unicode.IsSpace) >= 0 || strings.ContainsAny(remoteAddr, "\r\n]") || strings.ContainsAny(userAgent, "\r\n") || duration < 0 || status < 0 {
+			return
+		}
+
+		e := AccessEntry{
+			Time:       time.Date(2017, 3, 1, 10, 0, 0, 0, time.Local),
+			Method:     "GET",
+			URL:        url,
+			Proto:      "HTTP/1.1",
+			RemoteAddr: remoteAddr,
+			UserAgent:  userAgent,
+			Duration:   time.Duration(duration),
+			Status:     status,
+			SampleRate: 1,
+		}
+
+		parsed, err := ParseAccessLine(e.String())
+		if err != nil {
+			t.Fatalf("Unable to parse [%s]: %v", e.String(), err)
+		}
+
+		if parsed.URL != url || parsed.RemoteAddr != remoteAddr || parsed.UserAgent != userAgent || parsed.Status != status {
+			t.Fatalf("Round trip of [%s] returned %+v", e.String(), parsed)
+		}
+	})
+}
+
+func FuzzFormatJSON(f *testing.F) {
+
+	f.Add("hello", "user", "bob", `{"a":[1,2]}`)
+	f.Add("\x00\xff ", "\"", "\\", "not json")
+
+	f.Fuzz(func(t *testing.T, message string, key string, value string, raw string) {
+
+		e := decorateAppLogEntry(INFO, INFO, []Field{{Key: key, Value: value}, JSON("raw", json.RawMessage(raw))}, []interface{}{message}, 1)
+
+		if s := formatJSON(e); !json.Valid([]byte(s)) {
+			t.Fatalf("Invalid JSON %s", s)
+		}
+	})
+}
+
+func FuzzCaptureReader(f *testing.F) {
+
+	f.Add([]byte(captureMagic))
+	f.Add([]byte(captureMagic + "\x01\x03GET\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"))
+	f.Add([]byte(captureMagic + "\x01\xff\xff\xff\xff\xff\xff\xff\xff\x7f"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		folder, err := ioutil.TempDir("", "golcap")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(folder)
+
+		if err := ioutil.WriteFile(folder+"/"+capturePrefix+"1"+captureExtension, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := OpenCaptureReader(folder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		for i := 0; i < 1000; i++ {
+			if _, err := r.Next(); err != nil {
+				if err == io.EOF {
+					return
+				}
+				break
+			}
+		}
+	})
+}
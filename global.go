@@ -0,0 +1,67 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+type globals struct {
+	fields []Field
+	text   string // Pre-encoded fields, e.g. " app=checkout env=prod"
+}
+
+var globalFields atomic.Value
+
+func init() {
+	globalFields.Store(globals{})
+}
+
+// Sets low cardinality fields stamped on every entry of the log files (and on the formatted
+// entries and syslog messages of the sinks), e.g. app=checkout env=prod region=eu-west-1.
+// The fields are encoded once, sorted by key. Calling it with nil removes them.
+func SetGlobalFields(fields map[string]string) {
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	g := globals{}
+
+	for _, k := range keys {
+		f := Field{Key: k, Value: fields[k]}
+		g.fields = append(g.fields, f)
+		g.text += " " + formatField(f)
+	}
+
+	globalFields.Store(g)
+}
+
+func loadGlobals() globals {
+	return globalFields.Load().(globals)
+}
@@ -0,0 +1,72 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlobalFields(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetGlobalFields(map[string]string{"env": "prod", "app": "checkout", "region": "eu west"})
+	defer SetGlobalFields(nil)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("hello", Field{Key: "user", Value: "bob"})
+	Public(*httptest.NewRequest("GET", "/global", nil), 200, 10, time.Millisecond)
+
+	if !fileContains("./application.log", `[hello] user=bob app=checkout env=prod region="eu west"`, t) ||
+		!fileContains("./access.log", `app=checkout env=prod region="eu west"`, t) {
+		fmt.Println("Missing global fields")
+		t.Fail()
+	}
+
+	msg := formatSyslog(Entry{Time: time.Now(), Level: INFO, Message: "m"}, FacilityUser, "h", "a", 1, "gol@32473")
+
+	if !strings.Contains(msg, `[gol@32473 app="checkout" env="prod" region="eu west"]`) {
+		fmt.Println("Missing global fields in syslog message", msg)
+		t.Fail()
+	}
+}
@@ -26,7 +26,6 @@ package gol
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -34,6 +33,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -54,42 +54,75 @@ var levels = map[int]string{
 }
 
 var running bool = false
+var runLock = sync.RWMutex{} // Held for reading while sending to the write routines, Stop closes their channels
 
-var aLoglevel int = INFO           // Log level
-var aLogFolder string = "/var/log" // Path to gol file
-var aLogMaxSize int64 = 1024       // in KB
-var aLogMaxAge int = 10            // File older than MaxAge days will be deleted automatically
-var aLogSuffix int = 0
-var aLogName = "application.log"
+var aLoglevel int32 = INFO // Log level, read with appLogLevel as it can change while logging
 
-var pLogFolder string = "/var/log" // Path to gol file
-var pLogMaxSize int64 = 1024       // in KB
-var pLogMaxAge int = 10            // File older than MaxAge will be deleted automatically
-var pLogSuffix int = 0
-var pLogName = "access.log"
+var appChannel = &channel{folder: "/var/log", name: "application.log", maxSize: 1024, maxAge: 10}
+var publicChannel = &channel{folder: "/var/log", name: "access.log", maxSize: 1024, maxAge: 10, format: "access"}
 
 var startStopMutex = sync.Mutex{}
-var aFileRotateLock = sync.RWMutex{}
-var pFileRotateLock = sync.RWMutex{}
 
-var appLogChan chan string
+var appLogChan chan *Entry
 var publicLogChan chan string
 
-var appLogFile *os.File
-var publicLogFile *os.File
-
 var currentDate = time.Now().Local().Format("2006-01-02")
 
-var logToStdOut = true
+var logToStdOut int32 = 1  // 1 to print the app log entries to stdout, see stdoutEnabled
+var stdoutLevel int32 = -1 // Minimum level logged to stdout, -1 to follow the app log level
+
+var showFunctionNames int32 // 1 to add the caller function names
+
+var showLineNumbers [levelSlots]int32 // Caller lookup per level (1), all on by default
 
-var showLineNumbers = true
+var synchronous int32 // 1 if the entries are written by the calling goroutine, read with isSynchronous
 
 var wg sync.WaitGroup
 
-var aRotateCounter int
-var pRotateCounter int
+var stopping chan struct{}  // Closed by Stop to end the background routines of the run
+var routines sync.WaitGroup // Background routines of the run: purge, watchdog, checkpoints, flushes
+
+var fatalHandler atomic.Value // func(message string), called instead of terminating the app when set
+var errorHandler atomic.Value // func(err error), called on gol internal errors when set
+
+// Entry is an application log entry.
+type Entry struct {
+	Time     time.Time
+	Level    int
+	Message  string
+	Fields   []Field
+	File     string // Caller file, empty unless line numbers are shown
+	Line     int    // Caller line, 0 unless line numbers are shown
+	Sequence uint64 // Sequence number in the app log, 0 if not stamped
+	Function string // Caller function, empty unless function names are shown
+
+	text    string // Formatted entry
+	toFile  bool   // Entry accepted by the app log file
+	written bool   // Entry written in the app log file by a batch
+
+	personal string   // Personal data log entry, see Personal
+	priority bool     // High priority entry, see Priority
+	group    []*Entry // Entries written contiguously, see Tx
+}
+
+// Returns the entry formatted as in the app log file.
+func (e *Entry) String() string {
+	return e.text
+}
 
-func Start() error {
+// Starts the logging routines. The options (e.g. WithLevel or a Config) are all validated, then
+// applied in order, unless gol is already running: nothing is applied if one is invalid. Options
+// are preferred to the setters called before Start, as most setters have no effect once started.
+// The environment variables are applied last, so that the deployments can override the settings
+// of the code, an invalid value making Start fail:
+//
+//   - GOL_LEVEL: app log level, e.g. debug or WARN
+//   - GOL_APP_LOG_DIR, GOL_PUBLIC_LOG_DIR: folders of the app and public access log files
+//   - GOL_MAX_SIZE: max size in KB of the log files
+//   - GOL_MAX_AGE: max age in days of the log files
+//   - GOL_STDOUT: true or false, prints the app log entries to stdout
+//   - GOL_STDOUT_FORMAT: text or json
+func Start(opts ...Option) error {
 
 	startStopMutex.Lock()
 	defer startStopMutex.Unlock()
@@ -98,162 +131,541 @@ func Start() error {
 		return nil
 	}
 
-	appLogChan = make(chan string, 1000)
-	publicLogChan = make(chan string)
+	opts = append(opts[:len(opts):len(opts)], envOptions(os.Getenv)...)
 
-	var err error
+	invalid := &ErrInvalidConfig{}
 
-	aLogSuffix = 0
-	appLogFile, err = openLogFile(aLogFolder, aLogName)
-	if err != nil {
-		return err
+	for _, o := range opts {
+		if err := o.validate(); err != nil {
+			if e, ok := err.(*ErrInvalidConfig); ok {
+				invalid.Problems = append(invalid.Problems, e.Problems...)
+			} else {
+				invalid.Problems = append(invalid.Problems, ConfigProblem{Message: err.Error()})
+			}
+		}
+	}
+
+	if len(invalid.Problems) > 0 {
+		return invalid
 	}
 
-	publicLogFile, err = openLogFile(pLogFolder, pLogName)
+	for _, o := range opts {
+		o.apply()
+	}
+
+	readOnly, err := checkSettings()
 	if err != nil {
 		return err
 	}
+	atomic.StoreInt32(&stdoutOnly, toggle(len(readOnly) > 0))
+
+	atomic.StoreInt32(&errorLogActive, 0)
+	atomic.StoreInt32(&personalLogActive, 0)
+
+	if !onlyStdout() {
+		if err := openFiles(); err != nil {
+			return err
+		}
+	}
+
+	resetStats()
 
+	runLock.Lock()
+	appLogChan = make(chan *Entry, 1000)
+	priorityLogChan = make(chan *Entry, 100)
+	publicLogChan = make(chan string)
 	running = true
+	runLock.Unlock()
+
+	ordered := atomic.LoadInt32(&orderedWrites) == 1
+
+	if ordered {
+		wg.Add(1)
+		go appLogBatchWrite(appLogChan) // Single app log write routine
+	}
+
+	wg.Add(1)
+	go appLogWrite(priorityLogChan) // High priority app log write routine
 
 	for i := 0; i < NUM_LOGGING_ROUTINES; i++ {
-		go appLogWrite(appLogChan)             // App log write routine
+		if !ordered {
+			wg.Add(1)
+			go appLogWrite(appLogChan) // App log write routine
+		}
+		wg.Add(1)
 		go publicAccessLogWrite(publicLogChan) // Public access log write routine
 	}
 
-	go purgeFiles(aLogFolder, aLogName, aLogMaxAge) // App log purge routine
-	go purgeFiles(pLogFolder, pLogName, pLogMaxAge) // Public log purge routine
+	stopping = make(chan struct{})
+
+	if onlyStdout() {
+		warnReadOnly(readOnly)
+		return nil
+	}
+
+	routines.Add(2)
+	go purgeFiles(logChannels()...) // App, public and error log purge routine
+	go watchDiskSpace()             // Free disk space watchdog routine
+
+	if checkpointInterval > 0 {
+		routines.Add(1)
+		go checkpointFiles([]*channel{appChannel, publicChannel}, checkpointInterval) // Sequence checkpoints routine
+	}
+
+	if atomic.LoadInt32(&mmapWrites) == 1 {
+		routines.Add(1)
+		go syncMappedFiles([]*channel{appChannel, publicChannel}) // Memory mapped files flush routine
+	}
+
+	return nil
+}
+
+// Opens the files of the app, public access, error, personal data and routed logs.
+func openFiles() (err error) {
+
+	mmap := atomic.LoadInt32(&mmapWrites) == 1
+	appChannel.update(func() { appChannel.mmap = mmap })
+	publicChannel.update(func() { publicChannel.mmap = mmap })
+
+	err = appChannel.open()
+	if err != nil {
+		return err
+	}
+
+	err = publicChannel.open()
+	if err != nil {
+		return err
+	}
+
+	errorLog := atomic.LoadInt32(&errorLogEnabled)
+
+	if errorLog == 1 {
+		err = errorChannel.open()
+		if err != nil {
+			return err
+		}
+	}
+	atomic.StoreInt32(&errorLogActive, errorLog)
+
+	err = openPersonalLog()
+	if err != nil {
+		return err
+	}
+
+	err = openRoutes()
+	if err != nil {
+		return err
+	}
+
+	if checkpointInterval > 0 {
+		appChannel.restoreSequence()
+		publicChannel.restoreSequence()
+	}
 
 	return nil
 }
 
+// Stops gol: the queued entries are written, the background routines end and the files are closed.
+// Start can be called again, it applies the configuration set meanwhile.
 func Stop() {
 
 	startStopMutex.Lock()
 	defer startStopMutex.Unlock()
 
+	if !running {
+		return
+	}
+
+	runLock.Lock()
 	running = false
 
 	close(appLogChan)
+	close(priorityLogChan)
 	close(publicLogChan)
+	runLock.Unlock()
 
 	wg.Wait()
-}
 
-func Debug(v ...interface{}) {
+	close(stopping)
+	routines.Wait()
+	compressions.Wait()
 
-	if !running {
-		return
+	if atomic.LoadInt32(&shutdownReport) == 1 {
+		writeShutdownReport()
+	}
+
+	closeAccessCapture()
+
+	if checkpointInterval > 0 {
+		appChannel.checkpoint()
+		publicChannel.checkpoint()
 	}
 
-	if s := decorateAppLogEntry(DEBUG, v); s != "" {
-		appLogChan <- s
+	for _, c := range logChannels() {
+		if err := c.close(); err != nil {
+			reportError(err)
+		}
 	}
 }
 
-func Info(v ...interface{}) {
+// Waits for d, returns false if Stop was called meanwhile.
+func idle(d time.Duration) bool {
 
-	if !running {
-		return
+	select {
+	case <-stopping:
+		return false
+	case <-time.After(d):
+		return true
 	}
+}
 
-	if s := decorateAppLogEntry(INFO, v); s != "" {
-		appLogChan <- s
-	}
+func Debug(v ...interface{}) {
+	appLog(DEBUG, appLogLevel(), nil, v)
+}
+
+func Info(v ...interface{}) {
+	appLog(INFO, appLogLevel(), nil, v)
 }
 
 func Warn(v ...interface{}) {
+	appLog(WARN, appLogLevel(), nil, v)
+}
 
-	if !running {
-		return
-	}
+func Error(v ...interface{}) {
+	appLog(ERROR, appLogLevel(), nil, v)
+}
 
-	if s := decorateAppLogEntry(WARN, v); s != "" {
-		appLogChan <- s
+// Logs the message synchronously and terminates the app with exit code 1 (see SetFatalHandler).
+func Fatal(v ...interface{}) {
+	if e := decorateAppLogEntry(FATAL, appLogLevel(), nil, v, 2); e != nil && writeRunning(e) {
+		terminate(e.String())
 	}
 }
 
-func Error(v ...interface{}) {
+// Writes the entry synchronously, returns false if gol is not running.
+func writeRunning(e *Entry) bool {
+
+	runLock.RLock()
+	defer runLock.RUnlock()
 
 	if !running {
+		return false
+	}
+
+	doAppLogWrite(e)
+
+	return true
+}
+
+// Returns true if gol is started.
+func isRunning() bool {
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
+	return running
+}
+
+func Public(req http.Request, statusCode int, contentLength int, duration time.Duration) {
+
+	if duration < 0 {
+		duration = 0 // Measured with wall clock readings across a clock step
+	}
+
+	route := ""
+	if req.URL != nil {
+		route = routeOf(req.URL.Path)
+	}
+
+	publicLatencies.record(duration)
+	recordRouteLatency(route, duration)
+	observeClient(remoteAddr(&req))
+
+	rate := publicSampleRate(&req, statusCode)
+
+	if isDebugCapture(&req) {
+		rate = 1 // Debug captures are always kept
+	}
+
+	if !keepSample(rate) {
+		atomic.AddInt64(&sampledOutCount, 1)
 		return
 	}
 
-	if s := decorateAppLogEntry(ERROR, v); s != "" {
-		appLogChan <- s
+	e := decoratePublicAccessLogEntry(req, statusCode, contentLength, duration, rate)
+	e.Route = route
+	e.Fields = accessFields(&req, contentLength, duration)
+	e.request = &req
+
+	if req.URL != nil {
+		e.Class = classOf(req.URL.Path, e.UserAgent, e.RemoteAddr)
 	}
-}
 
-// Logs the message synchronously and terminates the app with exit code 1.
-func Fatal(v ...interface{}) {
+	if !runPublicHooks(e) {
+		return
+	}
+
+	anonymizeAccessEntry(e)
+
+	captureAccessEntry(e)
+
+	if !publicTextLog {
+		atomic.AddInt64(&publicCount, 1)
+		return
+	}
+
+	msg := e.String()
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
 	if !running {
 		return
 	}
 
-	if message := decorateAppLogEntry(FATAL, v); message != "" {
-		doAppLogWrite(message)
-		os.Exit(1)
+	if isSynchronous() {
+		if err := doPublicAccessLogWrite(msg); err != nil {
+			log.Println("Unable to log message ["+msg+"]", err)
+		}
+		return
 	}
-}
 
-func Public(req http.Request, statusCode int, contentLength int, duration time.Duration) {
-	publicLogChan <- decoratePublicAccessLogEntry(req, statusCode, contentLength, duration)
+	publicLogChan <- msg
 }
 
 func SetAppLogFolder(path string) {
-	aLogFolder = path
+	appChannel.update(func() { appChannel.folder = path })
 }
 
 func SetAppLogMaxSize(size int64) {
-	aLogMaxSize = size
+	appChannel.update(func() { appChannel.maxSize = size })
 }
 
 func SetAppLogMaxAge(age int) {
-	aLogMaxAge = age
+	appChannel.update(func() { appChannel.maxAge = age })
+}
+
+// Sets the maximum size in KB of the app log file and its archives, the oldest archives
+// are removed beyond it (default 0, no quota).
+func SetAppLogQuota(quota int64) {
+	appChannel.update(func() { appChannel.quota = quota })
+}
+
+// Sets the calendar based retention of the app log archives, replacing their max age.
+func SetAppLogRetention(retention Retention) {
+	appChannel.update(func() { appChannel.retention = retention })
 }
 
 func SetPublicLogFolder(path string) {
-	pLogFolder = path
+	publicChannel.update(func() { publicChannel.folder = path })
 }
 
 func SetPublicLogMaxSize(size int64) {
-	pLogMaxSize = size
+	publicChannel.update(func() { publicChannel.maxSize = size })
 }
 
 func SetPublicLogMaxAge(age int) {
-	pLogMaxAge = age
+	publicChannel.update(func() { publicChannel.maxAge = age })
+}
+
+// Sets the maximum size in KB of the public access log file and its archives, the oldest
+// archives are removed beyond it (default 0, no quota).
+func SetPublicLogQuota(quota int64) {
+	publicChannel.update(func() { publicChannel.quota = quota })
+}
+
+// Sets the calendar based retention of the public access log archives, replacing their max age.
+func SetPublicLogRetention(retention Retention) {
+	publicChannel.update(func() { publicChannel.retention = retention })
 }
 
 func LogToStdout(b bool) {
-	logToStdOut = b
+	atomic.StoreInt32(&logToStdOut, toggle(b))
+}
+
+// Returns true if the app log entries are printed to stdout.
+func stdoutEnabled() bool {
+	return atomic.LoadInt32(&logToStdOut) == 1
+}
+
+// Returns the value of a boolean setting stored as an int32, so that it can be changed while logging.
+func toggle(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Makes every log call write its entry to the files before returning, instead of handing it
+// to the logging routines. Meant for tests asserting the content of the log files.
+func SetSynchronous(enabled bool) {
+	atomic.StoreInt32(&synchronous, toggle(enabled))
+}
+
+// Returns true if the entries are written by the calling goroutine.
+func isSynchronous() bool {
+	return atomic.LoadInt32(&synchronous) == 1
+}
+
+// Adds the caller function name (e.g. github.com/me/app/db.(*Pool).Get) after the line number,
+// more stable than line numbers across refactors.
+func ShowFunctionNames(b bool) {
+	atomic.StoreInt32(&showFunctionNames, toggle(b))
 }
 
 func ShowLineNumbers(b bool) {
-	showLineNumbers = b
+	for level := range showLineNumbers {
+		atomic.StoreInt32(&showLineNumbers[level], toggle(b))
+	}
+}
+
+// Shows the line numbers of the entries of the given levels only, e.g. WARN, ERROR and FATAL,
+// sparing the cost of the caller lookup on the other levels.
+func ShowLineNumbersFor(levels ...int) {
+
+	ShowLineNumbers(false)
+
+	for _, level := range levels {
+		if level >= 0 && level < len(showLineNumbers) {
+			atomic.StoreInt32(&showLineNumbers[level], 1)
+		}
+	}
+}
+
+// Sets the function called by Fatal and on invalid configuration instead of terminating the app,
+// so gol never calls os.Exit or log.Fatal on its own (e.g. when embedded in a library).
+// A nil handler restores the default behavior.
+func SetFatalHandler(handler func(message string)) {
+	fatalHandler.Store(handler)
+}
+
+// Sets the function called when gol runs into an internal error (e.g. log volume almost full),
+// instead of printing it with the standard logger. A nil handler restores the default behavior.
+func SetErrorHandler(handler func(err error)) {
+	errorHandler.Store(handler)
+}
+
+// Returns the fatal handler, nil if none.
+func onFatal() func(message string) {
+	handler, _ := fatalHandler.Load().(func(message string))
+	return handler
+}
+
+// Reports a gol internal error.
+func reportError(err error) {
+
+	if handler, _ := errorHandler.Load().(func(err error)); handler != nil {
+		handler(err)
+		return
+	}
+
+	log.Println("ERROR -", err)
 }
 
 func SetAppLogLevel(level int) {
-	if level != DEBUG && level != INFO && level != WARN && level != ERROR {
-		log.Fatal("Ivalid gol level " + string(level))
+	if checkLevel(level) {
+		cancelLevelFor()
+		atomic.StoreInt32(&aLoglevel, int32(level))
 	}
-	aLoglevel = level
 }
 
-func appLogWrite(appDataChannel chan string) {
+// Returns the app log level.
+func appLogLevel() int {
+	return int(atomic.LoadInt32(&aLoglevel))
+}
+
+// Sets the minimum level of the app log entries printed to stdout, independently of the
+// app log level. A level of -1 (default) follows the app log level.
+func SetStdoutLogLevel(level int) {
+	if level == -1 || checkLevel(level) {
+		atomic.StoreInt32(&stdoutLevel, int32(level))
+	}
+}
+
+// Returns true if the level can be set, or reports it through the fatal handler.
+func checkLevel(level int) bool {
+
+	if isLevel(level) && level != FATAL {
+		return true
+	}
+
+	message := "Invalid gol level " + strconv.Itoa(level)
+
+	handler := onFatal()
+
+	if handler == nil {
+		log.Fatal(message)
+	}
+
+	handler(message)
+
+	return false
+}
+
+// Terminates the app with exit code 1, unless a fatal handler is set.
+func terminate(message string) {
+
+	writeCrashReport(message)
+
+	if handler := onFatal(); handler != nil {
+		handler(message)
+		return
+	}
+
+	os.Exit(1)
+}
+
+// Sends an application log entry to the app log write routines.
+func appLog(level int, minLevel int, fields []Field, v []interface{}) {
+	appLogSkip(level, minLevel, fields, v, 4)
+}
+
+// Sends an application log entry, skip being the number of stack frames to the caller to report.
+func appLogSkip(level int, minLevel int, fields []Field, v []interface{}, skip int) {
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
+	if !running {
+		return
+	}
+
+	if shed(level) {
+		return
+	}
+
+	if e := decorateAppLogEntry(level, minLevel, fields, v, skip); e != nil {
+		sendAppLog(e)
+	}
+}
+
+// Writes the entry, or sends it to the app log write routines. The caller holds runLock.
+func sendAppLog(e *Entry) {
+
+	if isSynchronous() {
+		if err := doAppLogWrite(e); err != nil {
+			log.Println("Unable to log message ["+e.String()+"]", err)
+		}
+		return
+	}
+
+	appLogChan <- e
+}
+
+func appLogWrite(appDataChannel chan *Entry) {
 
-	wg.Add(1)
 	defer wg.Done()
 
 	var more bool = true
-	var msg string = ""
+	var e *Entry
 
 	for more {
-		msg, more = <-appDataChannel
-		if msg != "" {
-			err := doAppLogWrite(msg)
+		e, more = <-appDataChannel
+		if e != nil {
+			err := doAppLogWrite(e)
 
 			if err != nil {
-				log.Println("Unable to log message ["+msg+"]", err)
+				log.Println("Unable to log message ["+e.String()+"]", err)
 			}
 		}
 	}
@@ -261,7 +673,6 @@ func appLogWrite(appDataChannel chan string) {
 
 func publicAccessLogWrite(publicDataChannel chan string) {
 
-	wg.Add(1)
 	defer wg.Done()
 
 	var more bool = true
@@ -279,62 +690,58 @@ func publicAccessLogWrite(publicDataChannel chan string) {
 	}
 }
 
-func doAppLogWrite(msg string) (err error) {
+func doAppLogWrite(e *Entry) (err error) {
 
-	aRotateCounter++
+	if e.group != nil {
+		return doGroupWrite(e.group)
+	}
 
-	if aRotateCounter <= 10 {
-		aRotateCounter = 0
-		aFileRotateLock.Lock()
-		if needRotation(appLogFile, aLogMaxSize) {
-			appLogFile.Close()
-			newLogFile, err := rotate(aLogFolder, aLogName, &aLogSuffix)
-			if err != nil {
-				log.Println("ERROR - Rotation required and unable to create file ", err)
+	if (stdoutEnabled() || onlyStdout()) && stdoutAccepts(e) {
+		writeStdout(e.Level, stdoutText(e))
+	}
+
+	if e.toFile && !e.written && !budget.take(len(e.String()), e.budgetLevel()) {
+		e.toFile = false
+	}
+
+	if e.toFile {
+		if !e.written {
+			if c := routeFor(e); c != nil {
+				c.write([]byte(e.String()))
 			} else {
-				appLogFile = newLogFile
+				appChannel.write([]byte(e.String()))
 			}
 		}
-		aFileRotateLock.Unlock()
-	}
+		atomic.AddInt64(&levelCounts[e.Level], 1)
 
-	if logToStdOut {
-		log.Print(msg)
+		if atomic.LoadInt32(&errorLogActive) == 1 && atLeast(e.Level, ERROR) {
+			errorChannel.write([]byte(e.String()))
+		}
+
+		if atomic.LoadInt32(&personalLogActive) == 1 && e.personal != "" {
+			personalChannel.write([]byte(e.personal))
+		}
 	}
 
-	aFileRotateLock.RLock()
-	appLogFile.Write([]byte(msg))
-	aFileRotateLock.RUnlock()
+	writeSinks(e)
+	recordCrashEntry(e)
+	publishEntry(e)
 
 	return nil
 }
 
 func doPublicAccessLogWrite(msg string) (err error) {
 
-	pRotateCounter++
-
-	if pRotateCounter <= 10 {
-		pRotateCounter = 0
-		pFileRotateLock.Lock()
-		if needRotation(publicLogFile, pLogMaxSize) {
-			publicLogFile.Close()
-			newLogFile, err := rotate(pLogFolder, pLogName, &pLogSuffix)
-			if err != nil {
-				log.Println("ERROR - Rotation required and unable to create file ", err)
-			} else {
-				publicLogFile = newLogFile
-			}
-		}
-		pFileRotateLock.Unlock()
+	if stdoutEnabled() || onlyStdout() {
+		writeStdout(INFO, msg)
 	}
 
-	if logToStdOut {
-		log.Print(msg)
+	if !budget.take(len(msg), -1) {
+		return nil
 	}
 
-	pFileRotateLock.RLock()
-	publicLogFile.Write([]byte(msg))
-	pFileRotateLock.RUnlock()
+	publicChannel.write([]byte(msg))
+	atomic.AddInt64(&publicCount, 1)
 
 	return nil
 }
@@ -355,37 +762,11 @@ func needRotation(f *os.File, maxSize int64) bool {
 	return false
 }
 
-func purgeFiles(folder string, suffix string, maxAge int) {
-
-	for running {
-
-		then := time.Now().AddDate(0, 0, 0-maxAge)
-		files, err := ioutil.ReadDir(folder)
-		if err != nil {
-			log.Println("ERROR: Purge routine unable to read directory ["+folder+"]", err)
-		}
-		for _, f := range files {
-			if strings.HasSuffix(f.Name(), suffix) {
-				if f.ModTime().Before(then) {
-					path := folder + "/" + f.Name()
-					err := os.Remove(path)
-					if err != nil {
-						log.Println("ERROR: Purge routine unable to remove file ["+path+"]", err)
-					} else {
-						log.Println("Purge routine removed file [" + path + "]")
-					}
-				}
-			}
-		}
-		time.Sleep(1 * time.Minute)
-	}
-}
-
-func openLogFile(folder string, aLogName string) (logFile *os.File, err error) {
+func openLogFile(folder string, name string) (logFile *os.File, err error) {
 
 	os.MkdirAll(folder, 0744)
 
-	fileName := folder + "/" + aLogName
+	fileName := folder + "/" + name
 
 	logFile, err = os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(0644))
 	if err != nil {
@@ -395,16 +776,14 @@ func openLogFile(folder string, aLogName string) (logFile *os.File, err error) {
 	return logFile, err
 }
 
-func rotate(folder string, fileName string, fileNumber *int) (logFile *os.File, err error) {
-
-	now := time.Now().Local().Format("2006-01-02")
+func rotate(folder string, fileName string, fileNumber *int, date string) (logFile *os.File, err error) {
 
 	os.MkdirAll(folder, 0744)
 
 	var rotated bool = false
 
 	for !rotated {
-		archiveFilePath := folder + "/" + now + "-" + strconv.Itoa(*fileNumber) + "-" + fileName
+		archiveFilePath := folder + "/" + date + "-" + strconv.Itoa(*fileNumber) + "-" + fileName
 		currentFilePath := folder + "/" + fileName
 
 		_, err = os.Stat(archiveFilePath)
@@ -436,25 +815,201 @@ func rotate(folder string, fileName string, fileNumber *int) (logFile *os.File,
 	return logFile, nil
 }
 
-func decorateAppLogEntry(level int, v []interface{}) string {
+// Returns the entry to log, or nil if no destination accepts its level. The file
+// threshold is minLevel, skip is the number of stack frames to the caller to report.
+func decorateAppLogEntry(level int, minLevel int, fields []Field, v []interface{}, skip int) *Entry {
+	return decorateEntry(appChannel, level, minLevel, fields, v, skip+1)
+}
+
+// Returns the entry to log in the file of the channel, see decorateAppLogEntry.
+func decorateEntry(c *channel, level int, minLevel int, fields []Field, v []interface{}, skip int) *Entry {
+
+	toFile := atLeast(level, minLevel)
+
+	if !toFile && !destinationsAccept(level) {
+		return nil
+	}
+
+	if muted(level, fields, skip) {
+		return nil
+	}
+
+	v, fields = splitFields(v, fields)
+
+	if atomic.LoadInt32(&goroutineIDs) == 1 {
+		fields = append(fields[:len(fields):len(fields)], Field{Key: "goroutine", Value: goroutineID()})
+	}
+
+	fields = sortedFields(fields)
+
+	fields, personal := splitPersonal(fields)
+
+	ref := ""
+	if personal != nil && toFile {
+		ref = personalRef()
+		fields = append(fields, Field{Key: personalRefKey, Value: ref})
+	}
+
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: formatMessage(v),
+		Fields:  fields,
+		toFile:  toFile,
+	}
+
+	// The entry is appended to a single buffer, the static parts being encoded once
+	buf := make([]byte, 0, 128+len(e.Message))
+
+	buf = append(buf, formatSecond(e.Time)...)
+	buf = append(buf, levelPrefix(level)...)
+	buf = append(buf, e.Message...)
+	buf = append(buf, ']')
+
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = appendField(buf, f)
+	}
+
+	buf = append(buf, loadGlobals().text...)
+
+	if toFile {
+		if e.Sequence = c.nextSequence(); e.Sequence > 0 {
+			buf = append(buf, " seq="...)
+			buf = strconv.AppendUint(buf, e.Sequence, 10)
+		}
+	}
 
-	if aLoglevel > level {
-		return ""
+	if atomic.LoadInt32(&showLineNumbers[level]) == 1 {
+		var pc uintptr
+		pc, e.File, e.Line, _ = runtime.Caller(skip)
+		buf = append(buf, " at "...)
+		buf = append(buf, e.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(e.Line), 10)
+
+		if atomic.LoadInt32(&showFunctionNames) == 1 {
+			if f := runtime.FuncForPC(pc); f != nil {
+				e.Function = f.Name()
+				buf = append(buf, " in "...)
+				buf = append(buf, e.Function...)
+			}
+		}
 	}
 
-	msg := time.Now().Format("2006-01-02 15:04:05") + " " + levels[level] + " " + fmt.Sprint(v)
+	buf = append(buf, '\n')
+
+	e.text = string(buf)
+
+	if ref != "" {
+		e.personal = formatPersonal(e, personal, ref)
+	}
 
-	if showLineNumbers {
-		_, file, line, _ := runtime.Caller(2)
-		msg += " at " + file + ":" + strconv.Itoa(line) + "\n"
+	if f := loadAppSyslogFormat(); f != nil {
+		e.text = f.line(e)
+	} else if t := loadAppTemplate(); t != nil {
+		applyTemplate(t, e)
 	}
 
-	return msg
+	return e
 }
-func decoratePublicAccessLogEntry(r http.Request, status int, contentLength int, d time.Duration) string {
-	ns := int64(d)
-	μs := int64(d / time.Microsecond)
-	ms := int64(d / time.Millisecond)
+
+// Returns the message of the arguments, formatted as by fmt.Sprint([]interface{}).
+func formatMessage(v []interface{}) string {
+
+	if len(v) == 1 {
+		if s, ok := v[0].(string); ok {
+			return s
+		}
+	}
+
+	msg := fmt.Sprint(v)
+
+	return msg[1 : len(msg)-1]
+}
+
+// Cached formatted second of the entries
+type formattedSecond struct {
+	unix int64
+	text string
+}
+
+var lastSecond atomic.Value
+
+// Returns the time formatted to the second, e.g. 2017-03-04 05:06:07, formatting each second once.
+func formatSecond(t time.Time) string {
+
+	unix := t.Unix()
+
+	if s, ok := lastSecond.Load().(formattedSecond); ok && s.unix == unix {
+		return s.text
+	}
+
+	text := t.Format("2006-01-02 15:04:05")
+	lastSecond.Store(formattedSecond{unix: unix, text: text})
+
+	return text
+}
+
+var levelPrefixes = map[int]string{}
+
+func init() {
+	for level, name := range levels {
+		levelPrefixes[level] = " " + name + " ["
+	}
+	ShowLineNumbers(true)
+}
+
+// Returns the encoded level and message opening of the entries of the level, e.g. " INFO [".
+func levelPrefix(level int) string {
+
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+
+	return levelPrefixes[level]
+}
+
+// Formats a field as key=value, quoting the value if needed.
+func formatField(f Field) string {
+	return string(appendField(nil, f))
+}
+
+// Appends the field formatted as key=value, quoting the value if needed.
+func appendField(buf []byte, f Field) []byte {
+
+	buf = append(buf, f.Key...)
+	buf = append(buf, '=')
+
+	switch v := f.Value.(type) {
+	case rawValue:
+		return append(buf, v.render()...)
+	case string:
+		return appendValue(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	default:
+		return appendValue(buf, fmt.Sprint(v))
+	}
+}
+
+func appendValue(buf []byte, value string) []byte {
+
+	// Unbalanced brackets would end the value early when parsed back
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"=") || valueLength(value) != len(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+
+	return append(buf, value...)
+}
+
+// Returns the X-Forwarded-For header of the request, or its remote address.
+func remoteAddr(r *http.Request) string {
 
 	fromIp := r.Header.Get("X-Forwarded-For")
 
@@ -462,19 +1017,24 @@ func decoratePublicAccessLogEntry(r http.Request, status int, contentLength int,
 		fromIp = r.RemoteAddr
 	}
 
-	message := time.Now().Format("2006-01-02 15:04:05") + " "
-	message += r.Method + " " + fmt.Sprint(r.URL) + " " + r.Proto + " from [" + fromIp + "] with agent [" + r.Header.Get("User-Agent") + "]"
+	return fromIp
+}
 
-	if ms > 0 {
-		message += " in " + strconv.FormatInt(ms, 10) + "ms => " + strconv.Itoa(status)
-	} else if μs > 0 {
-		message += " in " + strconv.FormatInt(μs, 10) + "μs => " + strconv.Itoa(status)
-	} else {
-		// Very fast computer ;)
-		message += " in " + strconv.FormatInt(ns, 10) + "ns => " + strconv.Itoa(status)
+func decoratePublicAccessLogEntry(r http.Request, status int, contentLength int, d time.Duration, sampleRate float64) *AccessEntry {
+
+	return &AccessEntry{
+		Time:          time.Now(),
+		Method:        r.Method,
+		URL:           fmt.Sprint(r.URL),
+		Proto:         r.Proto,
+		RemoteAddr:    remoteAddr(&r),
+		UserAgent:     r.Header.Get("User-Agent"),
+		Duration:      d,
+		Status:        status,
+		ContentLength: contentLength,
+		RequestID:     requestID(r.Context()),
+		Errors:        requestErrors(r.Context()),
+		SampleRate:    sampleRate,
+		Sequence:      publicChannel.nextSequence(),
 	}
-
-	message += " with " + strconv.Itoa(contentLength) + " bytes \n"
-
-	return message
 }
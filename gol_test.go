@@ -356,6 +356,48 @@ func TestPublicLogMultiThreaded(t *testing.T) {
 	}
 }
 
+func TestFatalHandler(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	var messages []string
+	SetFatalHandler(func(message string) {
+		messages = append(messages, message)
+	})
+	defer SetFatalHandler(nil)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(INFO)
+	SetAppLogLevel(42)
+
+	if appLogLevel() != INFO || len(messages) != 1 || !strings.Contains(messages[0], "42") {
+		fmt.Println("Invalid level not reported to the fatal handler")
+		t.FailNow()
+	}
+
+	Fatal("fatal1")
+
+	if len(messages) != 2 || !strings.Contains(messages[1], "fatal1") {
+		fmt.Println("Fatal not reported to the fatal handler")
+		t.FailNow()
+	}
+
+	if !fileContains("./application.log", "fatal1", t) {
+		t.Fail()
+	}
+}
+
 func removeLogFiles(path string) {
 
 	files, err := ioutil.ReadDir(path)
@@ -365,7 +407,8 @@ func removeLogFiles(path string) {
 	}
 
 	for _, f := range files {
-		if strings.HasSuffix(f.Name(), ".log") {
+		if strings.HasSuffix(archiveName(strings.TrimSuffix(strings.TrimSuffix(f.Name(), ".tmp"), shippedSuffix)), ".log") || strings.HasSuffix(f.Name(), manifestSuffix) || strings.HasSuffix(f.Name(), checkpointSuffix) ||
+			strings.Contains(f.Name(), crashSuffix) || strings.HasSuffix(f.Name(), runtimeCrashSuffix) {
 			err := os.Remove(path + "/" + f.Name())
 			if err != nil {
 				log.Fatal("Unable to remove log files before test", err)
@@ -442,3 +485,84 @@ func filesContains(path string, s string, t *testing.T) bool {
 	}
 	return false
 }
+
+func readFile(path string, t *testing.T) string {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		fmt.Println("Unable to read file "+path, err)
+		t.FailNow()
+	}
+
+	return string(b)
+}
+
+func TestSynchronous(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for i := 0; i < 10; i++ {
+		Info("synchronous" + strconv.Itoa(i))
+
+		if !fileContains("./application.log", "synchronous"+strconv.Itoa(i), t) {
+			fmt.Println("Entry not written before returning")
+			t.FailNow()
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/synchronous", nil)
+	Public(*req, 200, 0, time.Millisecond)
+
+	if !fileContains("./access.log", "/synchronous", t) {
+		fmt.Println("Public entry not written before returning")
+		t.Fail()
+	}
+}
+
+func TestShowLineNumbersFor(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	ShowLineNumbersFor(WARN, ERROR, FATAL)
+	defer ShowLineNumbers(true)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("without line")
+	Warn("with line")
+
+	content := readFile("./application.log", t)
+
+	if !strings.Contains(content, "[without line]\n") || !strings.Contains(content, "[with line] at ") {
+		fmt.Println("Unexpected line numbers", content)
+		t.Fail()
+	}
+}
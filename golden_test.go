@@ -0,0 +1,170 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The golden files hold the expected output of each format for the current SchemaVersion. A
+// change of output fails the test: bump SchemaVersion if it is intended, then regenerate
+// the files with go test -run TestGolden -update-golden.
+var updateGolden = flag.Bool("update-golden", false, "rewrite the golden files of the output formats")
+
+var goldenTime = time.Date(2017, 3, 1, 10, 4, 5, 678000000, time.UTC)
+
+var goldenSecond = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
+
+// Returns the app log entries of the golden files, stamped with goldenTime.
+func goldenEntries() []*Entry {
+
+	cases := []struct {
+		level  int
+		fields []Field
+		v      []interface{}
+	}{
+		{DEBUG, nil, []interface{}{"debug message"}},
+		{INFO, []Field{{Key: "user", Value: "bob"}, {Key: "count", Value: 3}, {Key: "ok", Value: true}}, []interface{}{"plain fields"}},
+		{WARN, []Field{{Key: "q", Value: `say "hi" = bye`}, {Key: "empty", Value: ""}, {Key: "open", Value: "a]"}}, []interface{}{"quoted fields"}},
+		{ERROR, []Field{{Key: "err", Value: errors.New("disk full")}, JSON("data", map[string]interface{}{"a": []int{1, 2}})}, []interface{}{"structured fields"}},
+		{INFO, nil, []interface{}{"multi", "part", 3}},
+		{FATAL, nil, []interface{}{"first line\nsecond line"}},
+	}
+
+	var entries []*Entry
+
+	for i, c := range cases {
+		e := decorateAppLogEntry(c.level, DEBUG, c.fields, c.v, 1)
+		e.Time = goldenTime
+		e.Sequence = uint64(i + 1)
+		e.text = goldenSecond.ReplaceAllString(e.text, goldenTime.Format("2006-01-02 15:04:05"))
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// Returns the public access log entries of the golden files.
+func goldenAccessEntries() []AccessEntry {
+
+	return []AccessEntry{
+		{Time: goldenTime, Method: "GET", URL: "/a?b=c", Proto: "HTTP/1.1", RemoteAddr: "1.2.3.4", UserAgent: "curl/7.54", Duration: 12 * time.Millisecond, Status: 200, ContentLength: 512, SampleRate: 1},
+		{Time: goldenTime, Method: "POST", URL: "/upload", Proto: "HTTP/2.0", RemoteAddr: "", UserAgent: "", Duration: 15 * time.Microsecond, Status: 500, ContentLength: 0, Errors: 2, SampleRate: 1, RequestID: "req-1", Route: "/upload"},
+		{Time: goldenTime, Method: "GET", URL: "/", Proto: "HTTP/1.0", RemoteAddr: "::1", UserAgent: "Mozilla/5.0 (X11; Linux)", Duration: 300, Status: 304, SampleRate: 0.25, Sequence: 42, Class: "bot", Fields: []Field{{Key: "cache_status", Value: "hit"}, {Key: "range", Value: "bytes=0-99"}}},
+	}
+}
+
+func TestGolden(t *testing.T) {
+
+	ShowLineNumbers(false)
+	defer ShowLineNumbers(true)
+	SetGlobalFields(map[string]string{"env": "prod"})
+	defer SetGlobalFields(nil)
+
+	render := map[string]func() string{
+		"app": func() string {
+			s := ""
+			for _, e := range goldenEntries() {
+				s += e.String()
+			}
+			return s
+		},
+		"json": func() string {
+			s := ""
+			for _, e := range goldenEntries() {
+				s += formatJSON(e)
+			}
+			return s
+		},
+		"console": func() string {
+			SetStdoutColors(true)
+			SetShortLevels(true)
+			defer SetStdoutColors(false)
+			defer SetShortLevels(false)
+			s := ""
+			for _, e := range goldenEntries() {
+				s += stdoutText(e)
+			}
+			return s
+		},
+		"syslog": func() string {
+			s := ""
+			for _, e := range goldenEntries() {
+				s += formatSyslog(*e, 1, "host", "app", 42, "gol") + "\n"
+			}
+			return s
+		},
+		"access": func() string {
+			s := ""
+			for _, e := range goldenAccessEntries() {
+				s += e.String()
+			}
+			return s
+		},
+	}
+
+	folder := "testdata/golden/v" + strconv.Itoa(SchemaVersion)
+
+	for name, f := range render {
+		path := folder + "/" + name + ".golden"
+		got := f()
+
+		if *updateGolden {
+			if err := os.MkdirAll(folder, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		want, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("No golden file for the %s format of schema %d: %v", name, SchemaVersion, err)
+		}
+
+		if got != string(want) {
+			gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+			for i := 0; i < len(gotLines) && i < len(wantLines); i++ {
+				if gotLines[i] != wantLines[i] {
+					t.Errorf("The %s format changed at line %d:\n got: %q\nwant: %q", name, i+1, gotLines[i], wantLines[i])
+					break
+				}
+			}
+			if len(gotLines) != len(wantLines) {
+				t.Errorf("The %s format has %d lines instead of %d", name, len(gotLines), len(wantLines))
+			}
+		}
+	}
+}
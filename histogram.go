@@ -0,0 +1,162 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Log-linear buckets: 4 buckets per power of two nanoseconds, i.e. a relative error under 25%
+const histogramBuckets = 64 * 4
+
+// Histogram is a snapshot of the latencies of the requests logged by Public.
+type Histogram struct {
+	Count  int64
+	Sum    time.Duration
+	Max    time.Duration
+	counts [histogramBuckets]int64
+}
+
+type latencyHistogram struct {
+	count  int64
+	sum    int64
+	max    int64
+	counts [histogramBuckets]int64
+}
+
+var publicLatencies = &latencyHistogram{}
+
+func (h *latencyHistogram) record(d time.Duration) {
+
+	ns := int64(d)
+	if ns < 0 {
+		ns = 0
+	}
+
+	atomic.AddInt64(&h.counts[bucketOf(ns)], 1)
+	atomic.AddInt64(&h.count, 1)
+	atomic.AddInt64(&h.sum, ns)
+
+	for {
+		max := atomic.LoadInt64(&h.max)
+		if ns <= max || atomic.CompareAndSwapInt64(&h.max, max, ns) {
+			return
+		}
+	}
+}
+
+func (h *latencyHistogram) snapshot() Histogram {
+
+	s := Histogram{
+		Count: atomic.LoadInt64(&h.count),
+		Sum:   time.Duration(atomic.LoadInt64(&h.sum)),
+		Max:   time.Duration(atomic.LoadInt64(&h.max)),
+	}
+
+	for i := range h.counts {
+		s.counts[i] = atomic.LoadInt64(&h.counts[i])
+	}
+
+	return s
+}
+
+func (h *latencyHistogram) reset() {
+
+	atomic.StoreInt64(&h.count, 0)
+	atomic.StoreInt64(&h.sum, 0)
+	atomic.StoreInt64(&h.max, 0)
+
+	for i := range h.counts {
+		atomic.StoreInt64(&h.counts[i], 0)
+	}
+}
+
+// Returns the bucket of the value in nanoseconds.
+func bucketOf(ns int64) int {
+
+	if ns < 4 {
+		return int(ns)
+	}
+
+	e := bits.Len64(uint64(ns)) - 1 // Power of two
+	sub := (ns >> uint(e-2)) & 3    // Quarter of the power of two
+
+	return e*4 + int(sub)
+}
+
+// Returns the exclusive upper bound of the bucket in nanoseconds.
+func bucketUpperBound(i int) int64 {
+
+	if i < 4 {
+		return int64(i) + 1
+	}
+
+	e, sub := i/4, int64(i%4)
+
+	return (4 + sub + 1) << uint(e-2)
+}
+
+// Returns the latency under which the fraction q (e.g. 0.99) of the requests were served,
+// as the upper bound of its bucket (capped by Max).
+func (h Histogram) Quantile(q float64) time.Duration {
+
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := int64(q * float64(h.Count))
+	if rank >= h.Count {
+		rank = h.Count - 1
+	}
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen > rank {
+			if d := time.Duration(bucketUpperBound(i)); d < h.Max {
+				return d
+			}
+			return h.Max
+		}
+	}
+
+	return h.Max
+}
+
+// Returns the number of requests served in less than the duration, rounded to the buckets.
+func (h Histogram) CountBelow(d time.Duration) int64 {
+
+	var count int64
+	for i, c := range h.counts {
+		if bucketUpperBound(i) > int64(d) {
+			break
+		}
+		count += c
+	}
+
+	return count
+}
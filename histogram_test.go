@@ -0,0 +1,108 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogramQuantiles(t *testing.T) {
+
+	h := &latencyHistogram{}
+
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	s := h.snapshot()
+
+	if s.Count != 1000 || s.Max != time.Second {
+		fmt.Println("Unexpected histogram", s.Count, s.Max)
+		t.Fail()
+	}
+
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		expected := time.Duration(q * float64(time.Second))
+		if d := s.Quantile(q); d < expected || float64(d) > 1.25*float64(expected) {
+			fmt.Println("Unexpected quantile", q, d)
+			t.Fail()
+		}
+	}
+
+	if n := s.CountBelow(100 * time.Millisecond); n < 75 || n > 100 {
+		fmt.Println("Unexpected count below 100ms", n)
+		t.Fail()
+	}
+
+	for ns := int64(0); ns < 1<<20; ns += 997 {
+		if i := bucketOf(ns); ns >= bucketUpperBound(i) || (i > 8 && ns < bucketUpperBound(i-1)) {
+			fmt.Println("Unexpected bucket", ns, i)
+			t.FailNow()
+		}
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	Public(*req, 200, 10, 3*time.Millisecond)
+	Public(*req, 200, 10, 300*time.Millisecond)
+
+	Stop()
+
+	res := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(res, req)
+
+	body := res.Body.String()
+
+	for _, line := range []string{
+		"gol_public_entries_total 2\n",
+		"gol_request_duration_seconds_count 2\n",
+		"gol_request_duration_seconds_bucket{le=\"0.004096\"} 1\n",
+		"gol_request_duration_seconds_bucket{le=\"+Inf\"} 2\n",
+	} {
+		if !strings.Contains(body, line) {
+			fmt.Println("Missing line", line, "in", body)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,139 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var idGenerator func() string = UUIDv7 // Generates the request IDs of the middleware
+var requestIDHeader = "X-Request-ID"   // Header carrying the request ID, empty to ignore incoming IDs
+var idLock = sync.RWMutex{}
+
+var uuidLock = sync.Mutex{}
+var uuidLastMillis int64
+var uuidSequence uint16
+
+// Sets the function generating the request IDs of the middleware (e.g. ksuid, snowflake),
+// UUIDv7 by default. A nil generator restores the default.
+func SetIDGenerator(generator func() string) {
+
+	if generator == nil {
+		generator = UUIDv7
+	}
+
+	idLock.Lock()
+	idGenerator = generator
+	idLock.Unlock()
+}
+
+// Sets the header from which the middleware takes the request ID, if present, and in which
+// it returns it. An empty header makes the middleware always generate the ID.
+func SetRequestIDHeader(header string) {
+	idLock.Lock()
+	requestIDHeader = header
+	idLock.Unlock()
+}
+
+// Returns a new time ordered UUID (RFC 9562 version 7). IDs generated by the process keep
+// increasing even if the wall clock goes backwards.
+func UUIDv7() string {
+
+	uuidLock.Lock()
+
+	millis := time.Now().UnixNano() / int64(time.Millisecond)
+
+	if millis > uuidLastMillis {
+		uuidLastMillis = millis
+		uuidSequence = 0
+	} else {
+		// Same millisecond or clock skew, keep the last timestamp and increase the sequence
+		uuidSequence++
+		if uuidSequence > 0x0fff {
+			uuidLastMillis++
+			uuidSequence = 0
+		}
+		millis = uuidLastMillis
+	}
+
+	sequence := uuidSequence
+
+	uuidLock.Unlock()
+
+	var b [16]byte
+
+	rand.Read(b[8:])
+
+	b[0] = byte(millis >> 40)
+	b[1] = byte(millis >> 32)
+	b[2] = byte(millis >> 24)
+	b[3] = byte(millis >> 16)
+	b[4] = byte(millis >> 8)
+	b[5] = byte(millis)
+	b[6] = 0x70 | byte(sequence>>8)
+	b[7] = byte(sequence)
+	b[8] = 0x80 | (b[8] & 0x3f)
+
+	s := hex.EncodeToString(b[:])
+
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// Returns the request ID from the request header, or a new one if absent or invalid.
+func requestIDFor(r *http.Request) string {
+
+	idLock.RLock()
+	defer idLock.RUnlock()
+
+	if requestIDHeader != "" {
+		if id := r.Header.Get(requestIDHeader); validRequestID(id) {
+			return id
+		}
+	}
+
+	return idGenerator()
+}
+
+// Returns true if the incoming request ID can be logged and returned as is: 1 to 128 letters,
+// digits, dots, underscores and dashes, so that it can't forge the fields of the log entries.
+func validRequestID(id string) bool {
+
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
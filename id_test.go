@@ -0,0 +1,119 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestUUIDv7(t *testing.T) {
+
+	format := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	last := ""
+
+	for i := 0; i < 10000; i++ {
+		id := UUIDv7()
+
+		if !format.MatchString(id) {
+			fmt.Println("Invalid UUIDv7 " + id)
+			t.FailNow()
+		}
+
+		if id <= last {
+			fmt.Println("UUIDv7 " + id + " not after " + last)
+			t.FailNow()
+		}
+
+		last = id
+	}
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	next := 0
+	SetIDGenerator(func() string {
+		next++
+		return "id-" + strconv.Itoa(next)
+	})
+	defer SetIDGenerator(nil)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		InfoContext(r.Context(), "handling "+r.URL.Path)
+	}))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("GET", "http://www.deal.com/generated", nil))
+
+	if res.Header().Get("X-Request-ID") != "id-1" {
+		fmt.Println("Missing request ID response header")
+		t.FailNow()
+	}
+
+	if !fileContains("./application.log", "[handling /generated] request_id=id-1", t) || !fileContains("./access.log", "request_id=id-1", t) {
+		fmt.Println("Missing generated request ID")
+		t.FailNow()
+	}
+
+	req := httptest.NewRequest("GET", "http://www.deal.com/forwarded", nil)
+	req.Header.Set("X-Request-ID", "upstream-7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !fileContains("./application.log", "[handling /forwarded] request_id=upstream-7", t) {
+		fmt.Println("Missing forwarded request ID")
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", "http://www.deal.com/forged", nil)
+	req.Header.Set("X-Request-ID", "x errors=9 route=/admin")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	if res.Header().Get("X-Request-ID") != "id-2" || !fileContains("./application.log", "[handling /forged] request_id=id-2", t) ||
+		fileContains("./access.log", "errors=9", t) {
+		fmt.Println("Invalid forwarded request ID kept", res.Header().Get("X-Request-ID"))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,173 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ingestPollInterval = 250 * time.Millisecond // Time between two reads of an ingested file at its end
+var ingestMaxLine = 16 * 1024 * 1024            // Longest line kept, as the lines of the log readers, longer ones are split
+
+// Tails a log file written by another component (e.g. an embedded C library) and republishes each
+// of its lines as is in the gol channel (see Raw), so that they share the rotation and the sinks
+// of gol. The lines already in the file are skipped. The file may not exist yet, and is reopened
+// when rotated or truncated. Lines longer than 16MB are split. Returns a function stopping the
+// tailing, which may be called more than once.
+func IngestFile(path string, channel string) (stop func(), err error) {
+
+	t := &ingester{path: path, channel: channel, done: make(chan struct{}), stopped: make(chan struct{})}
+
+	if t.file, err = os.Open(path); err == nil {
+		if _, err = t.file.Seek(0, io.SeekEnd); err != nil {
+			t.file.Close()
+			return nil, err
+		}
+		t.reader = bufio.NewReader(t.file)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	go t.run()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { close(t.done) })
+		<-t.stopped
+	}, nil
+}
+
+type ingester struct {
+	path    string
+	channel string
+	file    *os.File
+	reader  *bufio.Reader
+	partial string // Line being written
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (t *ingester) run() {
+
+	defer close(t.stopped)
+	defer func() {
+		if t.file != nil {
+			t.file.Close()
+		}
+	}()
+
+	for {
+		t.readLines()
+
+		select {
+		case <-t.done:
+			t.readLines()
+			return
+		case <-time.After(ingestPollInterval):
+		}
+
+		t.reopenIfRotated()
+	}
+}
+
+// Republishes the complete lines added since the last read.
+func (t *ingester) readLines() {
+
+	if t.reader == nil {
+		return
+	}
+
+	for {
+		b, err := t.reader.ReadSlice('\n')
+
+		if t.partial != "" && len(t.partial)+len(b) > ingestMaxLine {
+			t.publish(t.partial)
+			t.partial = ""
+		}
+		t.partial += string(b)
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				log.Println("ERROR - Unable to read ingested file ["+t.path+"]", err)
+			}
+			return
+		}
+
+		t.publish(strings.TrimSuffix(t.partial, "\r\n"))
+		t.partial = ""
+	}
+}
+
+// Republishes the line in the gol channel.
+func (t *ingester) publish(line string) {
+	if err := Raw(t.channel, line); err != nil {
+		log.Println("ERROR - Unable to ingest line of file ["+t.path+"]", err)
+	}
+}
+
+// Reopens the file when it has been replaced (rotation) or truncated, reading it from its start.
+func (t *ingester) reopenIfRotated() {
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return
+	}
+
+	if t.file != nil {
+		current, err := t.file.Stat()
+		offset, _ := t.file.Seek(0, io.SeekCurrent)
+
+		if err == nil && os.SameFile(info, current) {
+			if info.Size() < offset-int64(t.reader.Buffered()) {
+				t.file.Seek(0, io.SeekStart) // Truncated
+				t.reader.Reset(t.file)
+				t.partial = ""
+			}
+			return
+		}
+
+		t.readLines() // Remaining lines of the rotated file
+		t.file.Close()
+	}
+
+	if t.file, err = os.Open(t.path); err != nil {
+		t.file, t.reader = nil, nil
+		return
+	}
+
+	t.reader = bufio.NewReader(t.file)
+	t.partial = ""
+}
@@ -0,0 +1,143 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIngestFile(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	ingestPollInterval = 10 * time.Millisecond
+	defer func() { ingestPollInterval = 250 * time.Millisecond }()
+
+	ioutil.WriteFile("./foreign.log", []byte("already there\n"), 0644)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	stop, err := IngestFile("./foreign.log", AppLog)
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	appendFile := func(s string) {
+		f, _ := os.OpenFile("./foreign.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(s)
+		f.Close()
+	}
+
+	waitFor := func(s string) {
+		for i := 0; i < 200 && !strings.Contains(readFile("./application.log", t), s); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	appendFile("first line\nsecond ")
+	waitFor("first line")
+	appendFile("line\n")
+	waitFor("second line")
+
+	os.Rename("./foreign.log", "./foreign-1.log")
+	appendFile("after rotation\n")
+	waitFor("after rotation")
+
+	stop()
+	Stop()
+
+	if text := readFile("./application.log", t); text != "first line\nsecond line\nafter rotation\n" {
+		fmt.Printf("Unexpected ingested lines %q\n", text)
+		t.Fail()
+	}
+}
+
+func TestIngestFileLongLine(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	ingestPollInterval = 10 * time.Millisecond
+	ingestMaxLine = 8
+	defer func() {
+		ingestPollInterval = 250 * time.Millisecond
+		ingestMaxLine = 16 * 1024 * 1024
+	}()
+
+	ioutil.WriteFile("./foreign.log", nil, 0644)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop, err := IngestFile("./foreign.log", AppLog)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appendFile := func(s string) {
+		f, _ := os.OpenFile("./foreign.log", os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(s)
+		f.Close()
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	appendFile("aaaaaa")
+	appendFile("bbbbbb")
+	appendFile("c\n")
+
+	stop()
+	stop()
+	Stop()
+
+	if text := readFile("./application.log", t); text != "aaaaaa\nbbbbbbc\n" {
+		fmt.Printf("Unexpected ingested lines %q\n", text)
+		t.Fail()
+	}
+}
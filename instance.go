@@ -0,0 +1,162 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Files of a logger created with New, independent from the package level app and public access logs.
+type instance struct {
+	level  int
+	app    *channel
+	public *channel
+	stdout bool
+	closed int32
+	done   chan struct{}
+}
+
+// Returns a logger writing into its own app and public access log files, with the level, the
+// folders, the file names and the rotation settings of the configuration, so that several
+// components of a binary can log to separate files. The package level functions keep logging into
+// the files set up by Start. Process wide features (sinks, routes, subscribers, stats) only apply to
+// the package level files. The logger must be closed once done.
+func New(config Config) (*Logger, error) {
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	level, _ := parseLevel(config.Level)
+
+	in := &instance{
+		level:  level,
+		app:    &channel{},
+		public: &channel{format: "access"},
+		stdout: config.Console.Enabled,
+		done:   make(chan struct{}),
+	}
+
+	config.App.apply(in.app)
+	config.Public.apply(in.public)
+
+	if err := in.app.open(); err != nil {
+		return nil, err
+	}
+
+	if err := in.public.open(); err != nil {
+		in.app.closeFile()
+		return nil, err
+	}
+
+	go in.purgeFiles(config.PurgeInterval)
+
+	return &Logger{out: in}, nil
+}
+
+// Closes the files of a logger created with New, its entries are discarded afterwards.
+// It does nothing for the other loggers.
+func (l *Logger) Close() error {
+
+	if l.out == nil || !atomic.CompareAndSwapInt32(&l.out.closed, 0, 1) {
+		return nil
+	}
+
+	close(l.out.done)
+
+	var err error
+
+	for _, c := range []*channel{l.out.app, l.out.public} {
+		c.lock.Lock()
+		if cerr := c.closeFile(); cerr != nil && err == nil {
+			err = cerr
+		}
+		c.file = nil
+		c.lock.Unlock()
+	}
+
+	return err
+}
+
+// Logs the message in the app log file of the instance, skip being the number of stack frames
+// from here to the caller to report.
+func (in *instance) log(level int, fields []Field, v []interface{}, skip int) *Entry {
+
+	if atomic.LoadInt32(&in.closed) == 1 {
+		return nil
+	}
+
+	e := decorateEntry(in.app, level, in.level, fields, v, skip+1)
+	if e == nil || !e.toFile {
+		return nil
+	}
+
+	if in.stdout {
+		writeStdout(e.Level, e.String())
+	}
+
+	in.app.write([]byte(e.String()))
+
+	return e
+}
+
+// Logs the request in the public access log file of the instance.
+func (in *instance) logPublic(req http.Request, statusCode int, contentLength int, duration time.Duration) {
+
+	if atomic.LoadInt32(&in.closed) == 1 {
+		return
+	}
+
+	e := decoratePublicAccessLogEntry(req, statusCode, contentLength, duration, 1)
+	anonymizeAccessEntry(e)
+
+	msg := e.String()
+
+	if in.stdout {
+		writeStdout(INFO, msg)
+	}
+
+	in.public.write([]byte(msg))
+}
+
+// Purges the files of the instance until it is closed.
+func (in *instance) purgeFiles(interval time.Duration) {
+
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for {
+		purge([]*channel{in.app, in.public}, false)
+
+		select {
+		case <-in.done:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
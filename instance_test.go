@@ -0,0 +1,100 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	removeLogFiles(".")
+	os.RemoveAll("./instance")
+	defer os.RemoveAll("./instance")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.Level = "DEBUG"
+	config.Console.Enabled = false
+	config.App = ChannelConfig{Folder: "./instance", Name: "billing.log", MaxSize: 1024, MaxAge: 10}
+	config.Public = ChannelConfig{Folder: "./instance", Name: "billing-access.log", MaxSize: 1024, MaxAge: 10}
+
+	billing, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	billing.Debug("invoice drafted")
+	billing.Named("pdf").With("id", 42).Info("invoice rendered")
+	billing.Public(http.Request{Method: "GET", URL: &url.URL{Path: "/invoices"}}, 200, 10, time.Millisecond)
+	Info("from the app")
+	Debug("not at the app level")
+
+	if err := billing.Close(); err != nil {
+		t.Fatal(err)
+	}
+	billing.Info("after close")
+
+	Stop()
+
+	if !fileContains("./instance/billing.log", "DEBUG [invoice drafted]", t) ||
+		!fileContains("./instance/billing.log", "INFO [invoice rendered] logger=pdf id=42", t) {
+		fmt.Println("Instance entries not written", readFile("./instance/billing.log", t))
+		t.Fail()
+	}
+
+	if fileContains("./instance/billing.log", "from the app", t) || fileContains("./instance/billing.log", "after close", t) {
+		fmt.Println("Unexpected entries in the instance file", readFile("./instance/billing.log", t))
+		t.Fail()
+	}
+
+	if !fileContains("./instance/billing-access.log", "/invoices", t) {
+		fmt.Println("Instance access entry not written")
+		t.Fail()
+	}
+
+	if !fileContains("./application.log", "from the app", t) || fileContains("./application.log", "invoice", t) {
+		fmt.Println("Instance entries mixed with the app log", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if _, err := New(Config{Level: "LOUD"}); err == nil {
+		fmt.Println("Invalid configuration accepted")
+		t.Fail()
+	}
+}
@@ -0,0 +1,58 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var journalPrefixes int32 // 1 to prefix the stdout lines with their syslog priority
+var journalOutput io.Writer = os.Stderr
+
+// Writes the stdout entries to stderr with a <N> syslog priority prefix on each line (sd-daemon
+// convention) and without timestamp, so that journald records the priority of each entry when
+// the app runs under systemd without the journal socket.
+func SetJournalPrefixes(enabled bool) {
+	atomic.StoreInt32(&journalPrefixes, toggle(enabled))
+}
+
+// Writes the message on the standard output of gol (see LogToStdout).
+func writeStdout(level int, msg string) {
+
+	if atomic.LoadInt32(&journalPrefixes) == 0 {
+		log.Print(msg)
+		return
+	}
+
+	prefix := "<" + strconv.Itoa(syslogSeverity(level)) + ">"
+	lines := strings.Split(strings.TrimSuffix(msg, "\n"), "\n")
+
+	io.WriteString(journalOutput, prefix+strings.Join(lines, "\n"+prefix)+"\n")
+}
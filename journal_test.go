@@ -0,0 +1,53 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestJournalPrefixes(t *testing.T) {
+
+	var out bytes.Buffer
+	journalOutput = &out
+	SetJournalPrefixes(true)
+
+	defer func() {
+		SetJournalPrefixes(false)
+		journalOutput = os.Stderr
+	}()
+
+	writeStdout(ERROR, "first line\nsecond line\n")
+	writeStdout(DEBUG, "debug")
+	writeStdout(INFO, "GET / HTTP/1.1\n")
+
+	if out.String() != "<3>first line\n<3>second line\n<7>debug\n<6>GET / HTTP/1.1\n" {
+		fmt.Printf("Unexpected output %q\n", out.String())
+		t.Fail()
+	}
+}
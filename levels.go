@@ -0,0 +1,171 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const levelSlots = 64 // Values of the built-in and custom levels, see RegisterLevel
+
+// Ranks ordering the levels, spaced to leave room for the custom levels.
+var levelRanks = map[int]int{
+	DEBUG: 0,
+	INFO:  10,
+	WARN:  20,
+	ERROR: 30,
+	FATAL: 50,
+}
+
+var levelSeverities = map[int]int{} // RFC 5424 severities of the custom levels
+
+var levelsLock = sync.RWMutex{} // Guards the names, ranks, prefixes and severities of the levels
+
+// Registers a custom level, e.g. NOTICE ranked 15 between INFO (10) and WARN (20) or AUDIT ranked
+// 40 between ERROR (30) and FATAL (50), as required by some logging standards. The value (from 0 to
+// 63, not used by another level) identifies the level in the log calls, the rank orders it for the
+// filtering (app log level, stdout, sinks, readers), the name is written in the entries and the
+// severity (0 to 7) is used by the syslog sink and the journal prefixes. Must be called before Start.
+func RegisterLevel(level int, name string, rank int, severity int) error {
+
+	if level < 0 || level >= levelSlots {
+		return errors.New("gol custom level " + strconv.Itoa(level) + " not between 0 and " + strconv.Itoa(levelSlots-1))
+	}
+
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+
+	if _, ok := levels[level]; ok {
+		return errors.New("gol level " + strconv.Itoa(level) + " already registered")
+	}
+
+	if rank <= levelRanks[DEBUG] || rank >= levelRanks[FATAL] {
+		return errors.New("gol custom level rank " + strconv.Itoa(rank) + " not between DEBUG and FATAL")
+	}
+
+	for other, r := range levelRanks {
+		if r == rank {
+			return errors.New("gol level rank " + strconv.Itoa(rank) + " already used by " + levels[other])
+		}
+	}
+
+	if name == "" || strings.ContainsAny(name, " \t\r\n[]=") {
+		return errors.New("invalid gol level name [" + name + "]")
+	}
+
+	for _, other := range levels {
+		if strings.EqualFold(other, name) {
+			return errors.New("gol level name [" + name + "] already registered")
+		}
+	}
+
+	if severity < 0 || severity > 7 {
+		return errors.New("invalid syslog severity " + strconv.Itoa(severity))
+	}
+
+	levels[level] = name
+	levelRanks[level] = rank
+	levelPrefixes[level] = " " + name + " ["
+	levelSeverities[level] = severity
+
+	return nil
+}
+
+// Logs the message at the level, e.g. a custom level.
+func Log(level int, v ...interface{}) {
+	if isLevel(level) && level != FATAL {
+		appLog(level, appLogLevel(), nil, v)
+	}
+}
+
+// Returns the name of the level, empty if the level isn't registered.
+func levelName(level int) string {
+
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+
+	return levels[level]
+}
+
+// Returns true if the level is registered.
+func isLevel(level int) bool {
+
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+
+	_, ok := levels[level]
+
+	return ok
+}
+
+// Returns a copy of the names of the levels, by level.
+func levelNames() map[int]string {
+
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+
+	names := make(map[int]string, len(levels))
+	for level, name := range levels {
+		names[level] = name
+	}
+
+	return names
+}
+
+// Returns the levels in rank order, DEBUG first.
+func sortedLevels() []int {
+
+	names := levelNames()
+
+	sorted := make([]int, 0, len(names))
+	for level := range names {
+		sorted = append(sorted, level)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return rank(sorted[i]) < rank(sorted[j]) })
+
+	return sorted
+}
+
+// Returns the rank ordering the level, -1 if the level isn't registered.
+func rank(level int) int {
+
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+
+	if r, ok := levelRanks[level]; ok {
+		return r
+	}
+
+	return -1
+}
+
+// Returns true if the level is ranked at or above min.
+func atLeast(level int, min int) bool {
+	return rank(level) >= rank(min)
+}
@@ -0,0 +1,119 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+const notice = 7
+
+// The values of the built-in levels are kept, e.g. stored by the callers.
+func TestLevelValues(t *testing.T) {
+
+	SetAppLogLevel(1)
+	defer SetAppLogLevel(INFO)
+
+	if DEBUG != 0 || INFO != 1 || WARN != 2 || ERROR != 3 || FATAL != 5 || appLogLevel() != INFO {
+		fmt.Println("Built-in level values changed")
+		t.Fail()
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	if err := RegisterLevel(notice, "NOTICE", 15, 5); err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	defer unregisterLevel(notice)
+
+	if RegisterLevel(notice, "OTHER", 16, 5) == nil || RegisterLevel(8, "notice", 16, 5) == nil || RegisterLevel(8, "AUDIT", 15, 5) == nil ||
+		RegisterLevel(8, "AUDIT", 60, 5) == nil || RegisterLevel(64, "AUDIT", 40, 5) == nil || RegisterLevel(8, "AU DIT", 40, 5) == nil ||
+		RegisterLevel(8, "AUDIT", 40, 9) == nil {
+		fmt.Println("Invalid level registered")
+		t.Fail()
+	}
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(notice)
+	defer SetAppLogLevel(INFO)
+
+	Info("info entry")
+	Log(notice, "notice entry")
+	Named("billing").Log(notice, "named notice entry")
+	Warn("warn entry")
+
+	Stop()
+
+	if fileContains("./application.log", "info entry", t) || !fileContains("./application.log", "NOTICE [notice entry]", t) ||
+		!fileContains("./application.log", "NOTICE [named notice entry] logger=billing", t) || !fileContains("./application.log", "WARN [warn entry]", t) {
+		fmt.Println("Unexpected entries", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if sortedLevels()[2] != notice || !atLeast(WARN, notice) || atLeast(INFO, notice) {
+		fmt.Println("Unexpected order of the custom level", sortedLevels())
+		t.Fail()
+	}
+
+	if e, err := ParseLogLine("2017-03-01 10:00:00 NOTICE [config reloaded]"); err != nil || e.Level != notice {
+		fmt.Println("Unable to parse a custom level", err)
+		t.Fail()
+	}
+
+	if syslogSeverity(notice) != 5 || Stats().Entries["NOTICE"] != 2 {
+		fmt.Println("Unexpected severity or count", syslogSeverity(notice), Stats().Entries)
+		t.Fail()
+	}
+}
+
+// Removes a level registered by a test.
+func unregisterLevel(level int) {
+
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+
+	delete(levels, level)
+	delete(levelRanks, level)
+	delete(levelPrefixes, level)
+	delete(levelSeverities, level)
+}
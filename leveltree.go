@@ -0,0 +1,120 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LoggerLevel is the effective level of a named logger.
+type LoggerLevel struct {
+	Name     string
+	Level    int
+	From     string // Name of the logger whose level is inherited, empty for the app log level
+	Override bool   // Level set for the logger itself
+}
+
+var loggerLevels = map[string]int{}     // Levels set per logger name
+var loggerNames = map[string]struct{}{} // Names of the created named loggers
+var loggerLevelLock = sync.RWMutex{}
+
+// Sets the level of the named logger and of its children not having their own level,
+// e.g. "kafka" for "kafka.consumer".
+func SetLoggerLevel(name string, level int) {
+
+	if !checkLevel(level) {
+		return
+	}
+
+	loggerLevelLock.Lock()
+	loggerLevels[name] = level
+	loggerLevelLock.Unlock()
+}
+
+// Removes the level of the named logger, which inherits the level of its parent again.
+func ClearLoggerLevel(name string) {
+	loggerLevelLock.Lock()
+	delete(loggerLevels, name)
+	loggerLevelLock.Unlock()
+}
+
+// Returns the level of the named logger, inherited from its closest parent having a level
+// or else the app log level.
+func EffectiveLevel(name string) int {
+	return effectiveLevel(name).Level
+}
+
+// Returns the effective levels of the named loggers created or having a level, sorted by name.
+func LevelTree() []LoggerLevel {
+
+	loggerLevelLock.RLock()
+	names := make([]string, 0, len(loggerNames)+len(loggerLevels))
+	for name := range loggerNames {
+		names = append(names, name)
+	}
+	for name := range loggerLevels {
+		if _, ok := loggerNames[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	loggerLevelLock.RUnlock()
+
+	sort.Strings(names)
+
+	tree := make([]LoggerLevel, 0, len(names))
+	for _, name := range names {
+		tree = append(tree, effectiveLevel(name))
+	}
+
+	return tree
+}
+
+func effectiveLevel(name string) LoggerLevel {
+
+	loggerLevelLock.RLock()
+	defer loggerLevelLock.RUnlock()
+
+	for parent := name; parent != ""; {
+		if level, ok := loggerLevels[parent]; ok {
+			return LoggerLevel{Name: name, Level: level, From: parent, Override: parent == name}
+		}
+
+		i := strings.LastIndex(parent, ".")
+		if i < 0 {
+			break
+		}
+		parent = parent[:i]
+	}
+
+	return LoggerLevel{Name: name, Level: appLogLevel()}
+}
+
+func registerLogger(name string) {
+	loggerLevelLock.Lock()
+	loggerNames[name] = struct{}{}
+	loggerLevelLock.Unlock()
+}
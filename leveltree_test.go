@@ -0,0 +1,68 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEffectiveLevel(t *testing.T) {
+
+	SetAppLogLevel(INFO)
+	SetLoggerLevel("billing", DEBUG)
+	SetLoggerLevel("billing.stripe", ERROR)
+	defer ClearLoggerLevel("billing")
+	defer ClearLoggerLevel("billing.stripe")
+
+	invoices := Named("billing").Named("invoices")
+	Named("payroll")
+
+	if EffectiveLevel("billing.invoices") != DEBUG || EffectiveLevel("billing.stripe.webhooks") != ERROR ||
+		EffectiveLevel("payroll") != INFO || invoices.level() != DEBUG {
+		fmt.Println("Unexpected effective levels", LevelTree())
+		t.Fail()
+	}
+
+	res := httptest.NewRecorder()
+	LevelsHandler().ServeHTTP(res, httptest.NewRequest("GET", "/levels", nil))
+
+	body := res.Body.String()
+
+	for _, line := range []string{
+		"* INFO\n",
+		"billing DEBUG\n",
+		"billing.invoices DEBUG (inherited from billing)\n",
+		"billing.stripe ERROR\n",
+		"payroll INFO (inherited from *)\n",
+	} {
+		if !strings.Contains(body, line) {
+			fmt.Println("Missing line", line, "in", body)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,140 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigDecoder returns the options of a configuration file, keyed as the json tags of Config
+// (e.g. max_size), see RegisterConfigFormat.
+type ConfigDecoder func(data []byte) (map[string]interface{}, error)
+
+var configFormats = map[string]ConfigDecoder{".json": decodeJSONConfig}
+var configFormatsLock = sync.RWMutex{}
+
+// Registers the decoder of the configuration files with the extension, e.g. ".yaml", used by
+// LoadConfig. JSON is built in, YAML and TOML are registered by importing their module, e.g.
+// github.com/alexv99/gol/config/yaml, so that their dependencies are only required by the apps using them.
+func RegisterConfigFormat(extension string, decoder ConfigDecoder) {
+
+	configFormatsLock.Lock()
+	defer configFormatsLock.Unlock()
+
+	configFormats[strings.ToLower(extension)] = decoder
+}
+
+// Returns the configuration of the file, applied by Start(config): the options of the file
+// override those of DefaultConfig. The format follows the extension: .json, or a registered one
+// (see RegisterConfigFormat), with the keys of the json tags of Config (e.g. max_size) and
+// durations such as "1m". Unknown keys are rejected, so that a typo doesn't silently leave an
+// option unset.
+func LoadConfig(path string) (Config, error) {
+
+	config := DefaultConfig()
+
+	configFormatsLock.RLock()
+	decode, ok := configFormats[strings.ToLower(filepath.Ext(path))]
+	configFormatsLock.RUnlock()
+
+	if !ok {
+		return config, errors.New("unknown configuration format [" + filepath.Ext(path) + "]")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	values, err := decode(data)
+	if err == nil {
+		err = parseDurations(reflect.TypeOf(config), values)
+	}
+	if err == nil {
+		data, err = json.Marshal(values)
+	}
+	if err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	}
+
+	if err != nil {
+		return config, errors.New("invalid configuration file [" + path + "]: " + err.Error())
+	}
+
+	return config, config.Validate()
+}
+
+func decodeJSONConfig(data []byte) (values map[string]interface{}, err error) {
+	err = json.Unmarshal(data, &values)
+	return values, err
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Replaces the durations written as strings, e.g. "1m", by their value, following the fields of t.
+func parseDurations(t reflect.Type, values map[string]interface{}) error {
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := parseDurations(field.Type, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		switch value := values[key].(type) {
+		case string:
+			if field.Type == durationType {
+				d, err := time.ParseDuration(value)
+				if err != nil {
+					return errors.New(key + ": " + err.Error())
+				}
+				values[key] = int64(d)
+			}
+		case map[string]interface{}:
+			if field.Type.Kind() == reflect.Struct {
+				if err := parseDurations(field.Type, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+
+	files := map[string]string{
+		"gol-config.json": `{
+	"level": "DEBUG",
+	"app": {"folder": "./loaded", "name": "app.log", "max_size": 2048, "max_age": 3},
+	"console": {"enabled": false},
+	"daily_rotation": {"enabled": true, "zone": "UTC"},
+	"error": {"max_age": 2},
+	"anonymization": {"salt_rotation": "12h"},
+	"purge_interval": "5m"
+}`,
+	}
+
+	for name, content := range files {
+		ioutil.WriteFile(name, []byte(content), 0644)
+		defer os.Remove(name)
+
+		c, err := LoadConfig(name)
+		if err != nil {
+			fmt.Println(name, err)
+			t.Fail()
+			continue
+		}
+
+		if c.Level != "DEBUG" || c.App.Folder != "./loaded" || c.App.MaxSize != 2048 || c.App.MaxAge != 3 ||
+			c.Console.Enabled || !c.DailyRotation.Enabled || c.PurgeInterval != 5*time.Minute || c.Error.MaxAge != 2 ||
+			c.Anonymization.SaltRotation != 12*time.Hour {
+			fmt.Printf("%s not loaded %+v\n", name, c)
+			t.Fail()
+		}
+
+		if c.Public.Name != DefaultConfig().Public.Name || c.SampleRate != 1 {
+			fmt.Printf("%s defaults lost %+v\n", name, c)
+			t.Fail()
+		}
+	}
+
+	ioutil.WriteFile("gol-typo.json", []byte(`{"levle": "DEBUG"}`), 0644)
+	defer os.Remove("gol-typo.json")
+
+	if _, err := LoadConfig("gol-typo.json"); err == nil || !strings.Contains(err.Error(), "levle") {
+		fmt.Println("Unknown key accepted", err)
+		t.Fail()
+	}
+
+	if _, err := LoadConfig("gol-config.yaml"); err == nil || !strings.Contains(err.Error(), "unknown configuration format") {
+		fmt.Println("Unregistered format accepted", err)
+		t.Fail()
+	}
+}
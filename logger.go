@@ -0,0 +1,213 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"net/http"
+	"time"
+)
+
+// Interface is implemented by the gol loggers. Application code can depend on it and
+// tests can inject a mock instead of relying on the package level functions.
+type Interface interface {
+	Debug(v ...interface{})
+	Info(v ...interface{})
+	Warn(v ...interface{})
+	Error(v ...interface{})
+	Fatal(v ...interface{})
+	With(key string, value interface{}) Interface
+	Public(req http.Request, statusCode int, contentLength int, duration time.Duration)
+}
+
+// Field is a key/value pair appended to the application log entries.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger logs into the gol application and public access logs with its own fields.
+type Logger struct {
+	name   string // Dotted name of a named logger, e.g. "kafka.consumer"
+	fields []Field
+	out    *instance // Files of a logger created with New, nil for the package level files
+}
+
+var _ Interface = (*Logger)(nil)
+
+// Returns a logger without fields, logging like the package level functions.
+func Default() *Logger {
+	return &Logger{}
+}
+
+// Returns a logger adding the key/value field to all its application log entries.
+func With(key string, value interface{}) Interface {
+	return Default().With(key, value)
+}
+
+func (l *Logger) With(key string, value interface{}) Interface {
+
+	fields := make([]Field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+
+	return &Logger{name: l.name, fields: append(fields, Field{Key: key, Value: value}), out: l.out}
+}
+
+// Returns a named logger, adding the logger=name field to all its application log entries.
+// Named loggers can be silenced with Mute.
+func Named(name string) *Logger {
+	return Default().Named(name)
+}
+
+// Returns a child logger named after the logger, e.g. "kafka" then "kafka.consumer".
+func (l *Logger) Named(name string) *Logger {
+
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+
+	fields := make([]Field, 0, len(l.fields)+1)
+	for _, f := range l.fields {
+		if f.Key != loggerKey {
+			fields = append(fields, f)
+		}
+	}
+
+	registerLogger(name)
+
+	return &Logger{name: name, fields: append(fields, Field{Key: loggerKey, Value: name}), out: l.out}
+}
+
+// Returns the minimum level logged by the logger.
+func (l *Logger) level() int {
+
+	if l.out != nil {
+		return l.out.level
+	}
+
+	if l.name == "" {
+		return appLogLevel()
+	}
+
+	return EffectiveLevel(l.name)
+}
+
+func (l *Logger) Debug(v ...interface{}) {
+	if l.out != nil {
+		l.out.log(DEBUG, l.fields, v, 2)
+		return
+	}
+	appLog(DEBUG, l.level(), l.fields, v)
+}
+
+func (l *Logger) Info(v ...interface{}) {
+	if l.out != nil {
+		l.out.log(INFO, l.fields, v, 2)
+		return
+	}
+	appLog(INFO, l.level(), l.fields, v)
+}
+
+func (l *Logger) Warn(v ...interface{}) {
+	if l.out != nil {
+		l.out.log(WARN, l.fields, v, 2)
+		return
+	}
+	appLog(WARN, l.level(), l.fields, v)
+}
+
+func (l *Logger) Error(v ...interface{}) {
+	if l.out != nil {
+		l.out.log(ERROR, l.fields, v, 2)
+		return
+	}
+	appLog(ERROR, l.level(), l.fields, v)
+}
+
+// Logs the message at the level, e.g. a custom level (see RegisterLevel).
+func (l *Logger) Log(level int, v ...interface{}) {
+	if !isLevel(level) || level == FATAL {
+		return
+	}
+
+	if l.out != nil {
+		l.out.log(level, l.fields, v, 2)
+		return
+	}
+
+	appLog(level, l.level(), l.fields, v)
+}
+
+// Logs the message synchronously and terminates the app with exit code 1 (see SetFatalHandler).
+func (l *Logger) Fatal(v ...interface{}) {
+
+	if l.out != nil {
+		if e := l.out.log(FATAL, l.fields, v, 2); e != nil {
+			terminate(e.String())
+		}
+		return
+	}
+
+	if e := decorateAppLogEntry(FATAL, l.level(), l.fields, v, 2); e != nil && writeRunning(e) {
+		terminate(e.String())
+	}
+}
+
+func (l *Logger) Public(req http.Request, statusCode int, contentLength int, duration time.Duration) {
+
+	if l.out != nil {
+		l.out.logPublic(req, statusCode, contentLength, duration)
+		return
+	}
+
+	Public(req, statusCode, contentLength, duration)
+}
+
+// Logger discarding everything.
+type nopLogger struct{}
+
+var nop Interface = nopLogger{}
+
+// Returns a logger discarding everything without allocating, for benchmarks,
+// tests and libraries with optional logging.
+func Nop() Interface {
+	return nop
+}
+
+func (nopLogger) Debug(v ...interface{}) {}
+
+func (nopLogger) Info(v ...interface{}) {}
+
+func (nopLogger) Warn(v ...interface{}) {}
+
+func (nopLogger) Error(v ...interface{}) {}
+
+func (nopLogger) Fatal(v ...interface{}) {}
+
+func (n nopLogger) With(key string, value interface{}) Interface {
+	return n
+}
+
+func (nopLogger) Public(req http.Request, statusCode int, contentLength int, duration time.Duration) {
+}
@@ -0,0 +1,99 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Application code depending on Interface rather than on the package functions.
+type billing struct {
+	log Interface
+}
+
+func (b billing) charge(amount int) {
+	b.log.With("amount", amount).Info("charged")
+}
+
+func TestLoggerWith(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(INFO)
+
+	b := billing{log: With("component", "billing").With("note", "two words")}
+	b.charge(42)
+
+	if !fileContains("./application.log", `[charged] component=billing note="two words" amount=42`, t) {
+		fmt.Println("Missing fields from application log entry")
+		t.FailNow()
+	}
+
+	if !fileContains("./application.log", "logger_test.go", t) {
+		fmt.Println("Wrong caller in application log entry")
+		t.FailNow()
+	}
+}
+
+func TestNopDoesNotAllocate(t *testing.T) {
+
+	// Calls through Interface box the variadic arguments on the caller side,
+	// the logger itself must not allocate.
+	log := Nop().(nopLogger)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.With("component", "billing").Info("charged")
+		log.Error("failed")
+	})
+
+	if allocs != 0 {
+		fmt.Println("Nop logger allocated", allocs)
+		t.Fail()
+	}
+}
+
+func BenchmarkNop(b *testing.B) {
+
+	log := Nop()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		log.With("component", "billing").Info("charged")
+	}
+}
@@ -0,0 +1,190 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Manifest lists the archives of a log file, written as name.manifest.json next to them
+// (e.g. application.log.manifest.json), see SetArchiveManifest.
+type Manifest struct {
+	Name     string            `json:"name"`
+	Updated  time.Time         `json:"updated"`
+	Archives []ManifestArchive `json:"archives"` // Oldest first
+}
+
+// ManifestArchive describes an archive of a manifest.
+type ManifestArchive struct {
+	File   string    `json:"file"` // Name in the folder of the manifest
+	From   time.Time `json:"from"` // Time of the first entry, zero if none
+	To     time.Time `json:"to"`   // Time of the last entry, to the second
+	Size   int64     `json:"size"`
+	SHA256 string    `json:"sha256"` // Hexadecimal checksum of the file
+}
+
+const manifestSuffix = ".manifest.json"
+
+var archiveManifest = false
+var manifestLock = sync.Mutex{}
+
+// Maintains a manifest of the archives of each log file, with their time range, size and
+// checksum, updated on rotation, compression and purge. See ReadManifest.
+func SetArchiveManifest(enabled bool) {
+	archiveManifest = enabled
+}
+
+// Reads the manifest of the archives of the log name in the folder.
+func ReadManifest(folder string, name string) (Manifest, error) {
+
+	var m Manifest
+
+	b, err := ioutil.ReadFile(folder + "/" + name + manifestSuffix)
+	if err != nil {
+		return m, err
+	}
+
+	err = json.Unmarshal(b, &m)
+
+	return m, err
+}
+
+// Rewrites the manifest of the channel if enabled, see SetArchiveManifest.
+func (c *channel) updateManifest() {
+
+	if archiveManifest {
+		c.writeManifest()
+	}
+}
+
+// Rewrites the manifest of the channel from its archives, describing the new ones only.
+func (c *channel) writeManifest() {
+
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+
+	c.lock.RLock()
+	folder, name := c.folder, c.name
+	c.lock.RUnlock()
+
+	known := map[string]ManifestArchive{}
+	if old, err := ReadManifest(folder, name); err == nil {
+		for _, a := range old.Archives {
+			known[a.File] = a
+		}
+	}
+
+	files, err := c.archives()
+	if err != nil {
+		reportError(err)
+		return
+	}
+
+	m := Manifest{Name: name, Updated: time.Now(), Archives: []ManifestArchive{}}
+
+	for _, f := range files {
+		if a, ok := known[f.Name()]; ok && a.Size == f.Size() {
+			m.Archives = append(m.Archives, a)
+			continue
+		}
+
+		a, err := describeArchive(folder, f.Name())
+		if err != nil {
+			reportError(err)
+			continue
+		}
+		m.Archives = append(m.Archives, a)
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		reportError(err)
+		return
+	}
+
+	// Replaced at once, readers never see a partial manifest
+	path := folder + "/" + name + manifestSuffix
+	if err := ioutil.WriteFile(path+".tmp", append(b, '\n'), 0644); err != nil {
+		reportError(err)
+		return
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		reportError(err)
+	}
+}
+
+// Returns the description of the archive, reading its entries for their time range.
+func describeArchive(folder string, fileName string) (a ManifestArchive, err error) {
+
+	path := folder + "/" + fileName
+
+	f, err := os.Open(path)
+	if err != nil {
+		return a, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if a.Size, err = io.Copy(h, f); err != nil {
+		return a, err
+	}
+
+	a.File = fileName
+	a.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	r := &lineReader{paths: []string{path}}
+	defer r.closeFile()
+
+	for {
+		line, err := r.next()
+		if err != nil {
+			break
+		}
+
+		// The entries of the app and public access logs start with their time
+		if len(line) < 19 {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", line[:19], time.Local)
+		if err != nil {
+			continue
+		}
+
+		if a.From.IsZero() {
+			a.From = t
+		}
+		a.To = t
+	}
+
+	return a, nil
+}
@@ -0,0 +1,98 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestArchiveManifest(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetArchiveManifest(true)
+	defer SetArchiveManifest(false)
+
+	SetDailyRotation(true, time.UTC, 0)
+	defer SetDailyRotation(false, nil, 0)
+
+	// File of a previous day, rotated on start
+	old := time.Now().UTC().AddDate(0, 0, -2)
+	ioutil.WriteFile("./application.log", []byte("2017-03-01 10:00:00 INFO [first]\n2017-03-01 11:30:00 INFO [last]\n"), 0644)
+	os.Chtimes("./application.log", old, old)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("new entry")
+
+	Stop()
+
+	m, err := ReadManifest(".", "application.log")
+	if err != nil || m.Name != "application.log" || len(m.Archives) != 1 {
+		fmt.Println("Unexpected manifest", m, err)
+		t.FailNow()
+	}
+
+	a := m.Archives[0]
+	content := readFile("./"+a.File, t)
+	sum := sha256.Sum256([]byte(content))
+
+	if a.File != old.Format("2006-01-02")+"-0-application.log" || a.Size != int64(len(content)) || a.SHA256 != hex.EncodeToString(sum[:]) {
+		fmt.Println("Unexpected archive in the manifest", a)
+		t.Fail()
+	}
+
+	if a.From.Format("15:04:05") != "10:00:00" || a.To.Format("15:04:05") != "11:30:00" {
+		fmt.Println("Unexpected time range", a.From, a.To)
+		t.Fail()
+	}
+
+	// The purged archives are removed from the manifest
+	SetAppLogMaxAge(1)
+	defer SetAppLogMaxAge(10)
+
+	if _, _, err := purge([]*channel{appChannel}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, _ := ReadManifest(".", "application.log"); len(m.Archives) != 0 {
+		fmt.Println("Purged archive still in the manifest", m)
+		t.Fail()
+	}
+}
@@ -0,0 +1,105 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns an http handler serving the statistics of gol in the Prometheus text format, including
+// the histogram of the latencies of the requests logged by Public.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		stats := Stats()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		io.WriteString(w, "# TYPE gol_entries_total counter\n")
+		for _, level := range sortedLevels() {
+			fmt.Fprintf(w, "gol_entries_total{level=%q} %d\n", levelName(level), stats.Entries[levelName(level)])
+		}
+
+		counters := []struct {
+			name  string
+			value int64
+		}{
+			{"gol_public_entries_total", stats.Public},
+			{"gol_sampled_out_total", stats.SampledOut},
+			{"gol_vetoed_total", stats.Vetoed},
+			{"gol_dropped_total", stats.Dropped},
+			{"gol_shed_total", stats.Shed},
+			{"gol_rotations_total", stats.Rotations},
+			{"gol_written_bytes_total", stats.Bytes},
+		}
+
+		for _, c := range counters {
+			fmt.Fprintf(w, "# TYPE %s counter\n%s %d\n", c.name, c.name, c.value)
+		}
+
+		io.WriteString(w, "# TYPE gol_request_duration_seconds histogram\n")
+		writeHistogram(w, "", stats.Latency)
+
+		routes := make([]string, 0, len(stats.Routes))
+		for route := range stats.Routes {
+			routes = append(routes, route)
+		}
+		sort.Strings(routes)
+
+		if len(routes) > 0 {
+			io.WriteString(w, "# TYPE gol_route_duration_seconds histogram\n")
+		}
+		for _, route := range routes {
+			writeHistogram(w, route, stats.Routes[route])
+		}
+	})
+}
+
+// Writes the histogram of the request durations, of all the requests if the route is empty.
+func writeHistogram(w io.Writer, route string, h Histogram) {
+
+	name, labels := "gol_request_duration_seconds", ""
+	if route != "" {
+		name, labels = "gol_route_duration_seconds", "route="+strconv.Quote(route)+","
+	}
+
+	for d := time.Microsecond; d <= time.Minute; d *= 2 {
+		fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", name, labels, strconv.FormatFloat(d.Seconds(), 'g', -1, 64), h.CountBelow(d))
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.Count)
+
+	labels = strings.TrimSuffix(labels, ",")
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.Count)
+}
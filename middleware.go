@@ -0,0 +1,292 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type scopeKey struct{}
+
+// Request scoped logging state, carried by the request context.
+type requestScope struct {
+	id     string // Request ID
+	debug  bool   // Debug capture requested by an allowlisted header
+	errors int32  // Number of ERROR entries logged for the request
+
+	response    http.Header  // Headers of the response, set once served
+	cacheStatus atomic.Value // Cache status set by the handler, see SetCacheStatus
+
+	entries    int64 // App log entries and bytes of their messages, see SetRequestBudget
+	bytes      int64
+	overBudget int32 // 1 once the budget is exceeded
+}
+
+var debugHeader string
+var debugTokens []string
+var debugHeaderLock = sync.RWMutex{}
+
+// Allows a request carrying the given header with one of the tokens to be logged at DEBUG level
+// (through the *Context functions) and forces its public access log entry to be kept.
+// Calling it without tokens disables the debug capture.
+func SetDebugHeader(header string, tokens ...string) {
+	debugHeaderLock.Lock()
+	debugHeader = header
+	debugTokens = tokens
+	debugHeaderLock.Unlock()
+}
+
+// Wraps an http handler to log every request in the public access log. The request context
+// carries the request scoped logging state used by DebugContext, InfoContext, WarnContext and ErrorContext.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		start := time.Now()
+
+		scope := &requestScope{id: requestIDFor(r), debug: isDebugCapture(r)}
+		r = r.WithContext(context.WithValue(r.Context(), scopeKey{}, scope))
+
+		idLock.RLock()
+		if requestIDHeader != "" {
+			w.Header().Set(requestIDHeader, scope.id)
+		}
+		idLock.RUnlock()
+
+		rec := &responseRecorder{ResponseWriter: w}
+
+		var reqBody *captureReader
+
+		if max := bodyCaptureSize(r); max > 0 {
+			rec.body = &bytes.Buffer{}
+			rec.maxBody = max
+
+			if r.Body != nil && r.Body != http.NoBody {
+				reqBody = &captureReader{ReadCloser: r.Body, max: max}
+				r.Body = reqBody
+			}
+		}
+
+		next.ServeHTTP(rec, r)
+
+		scope.response = rec.Header()
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		if reqBody != nil {
+			logCapturedBody(r, "request body", r.Header.Get("Content-Type"), &reqBody.buf, reqBody.length)
+		}
+
+		logCapturedBody(r, "response body", rec.Header().Get("Content-Type"), rec.body, rec.length)
+
+		Public(*r, rec.status, rec.length, time.Since(start))
+	})
+}
+
+func DebugContext(ctx context.Context, v ...interface{}) {
+	contextLog(ctx, DEBUG, v)
+}
+
+func InfoContext(ctx context.Context, v ...interface{}) {
+	contextLog(ctx, INFO, v)
+}
+
+func WarnContext(ctx context.Context, v ...interface{}) {
+	contextLog(ctx, WARN, v)
+}
+
+func ErrorContext(ctx context.Context, v ...interface{}) {
+
+	if scope := scopeFrom(ctx); scope != nil {
+		atomic.AddInt32(&scope.errors, 1)
+	}
+
+	contextLog(ctx, ERROR, v)
+}
+
+func scopeFrom(ctx context.Context) *requestScope {
+
+	if ctx == nil {
+		return nil
+	}
+
+	scope, _ := ctx.Value(scopeKey{}).(*requestScope)
+
+	return scope
+}
+
+// Returns the minimum level logged for the request carried by the context.
+func appLogLevelFor(ctx context.Context) int {
+
+	if scope := scopeFrom(ctx); scope != nil && scope.debug {
+		return DEBUG
+	}
+
+	return appLogLevel()
+}
+
+// Returns the fields of the request carried by the context.
+func contextFields(ctx context.Context) []Field {
+
+	var fields []Field
+
+	if scope := scopeFrom(ctx); scope != nil && scope.id != "" {
+		fields = append(fields, Field{Key: "request_id", Value: scope.id})
+	}
+
+	if label := workerLabel(ctx); label != "" {
+		fields = append(fields, Field{Key: "worker", Value: label})
+	}
+
+	if dc := DiagnosticContextFrom(ctx); dc != nil {
+		fields = append(fields, dc.Fields()...)
+	}
+
+	return fields
+}
+
+// Returns the ID of the request carried by the context, empty if none.
+func requestID(ctx context.Context) string {
+
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.id
+	}
+
+	return ""
+}
+
+// Returns the number of ERROR entries logged for the request carried by the context.
+func requestErrors(ctx context.Context) int {
+
+	if scope := scopeFrom(ctx); scope != nil {
+		return int(atomic.LoadInt32(&scope.errors))
+	}
+
+	return 0
+}
+
+func isDebugCapture(r *http.Request) bool {
+
+	debugHeaderLock.RLock()
+	defer debugHeaderLock.RUnlock()
+
+	if debugHeader == "" || len(debugTokens) == 0 {
+		return false
+	}
+
+	value := strings.TrimSpace(r.Header.Get(debugHeader))
+
+	if value == "" {
+		return false
+	}
+
+	for _, token := range debugTokens {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Records the status code, the number of bytes and optionally the first bytes of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status  int
+	length  int
+	body    *bytes.Buffer
+	maxBody int
+}
+
+func (w *responseRecorder) WriteHeader(statusCode int) {
+
+	if w.status == 0 {
+		w.status = statusCode
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.length += n
+
+	if w.body != nil {
+		if left := w.maxBody - w.body.Len(); left > 0 {
+			if n < left {
+				left = n
+			}
+			w.body.Write(b[:left])
+		}
+	}
+
+	return n, err
+}
+
+// Sends the buffered response to the client, e.g. the Server-Sent Events of LiveTailHandler.
+func (w *responseRecorder) Flush() {
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hands the connection over to the handler, e.g. for a websocket upgrade.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+
+	return h.Hijack()
+}
+
+// Returns the wrapped response writer, e.g. for http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
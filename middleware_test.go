@@ -0,0 +1,306 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareDebugCapture(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetDebugHeader("X-Debug-Token", "s3cr3t")
+	defer SetDebugHeader("")
+
+	SetPublicSampleRate(0)
+	defer SetPublicSampleRate(1)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(ERROR)
+	defer SetAppLogLevel(INFO)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DebugContext(r.Context(), "debug "+r.URL.Path)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	captured := httptest.NewRequest("GET", "http://www.deal.com/captured", nil)
+	captured.Header.Set("X-Debug-Token", "s3cr3t")
+	handler.ServeHTTP(httptest.NewRecorder(), captured)
+
+	ignored := httptest.NewRequest("GET", "http://www.deal.com/ignored", nil)
+	ignored.Header.Set("X-Debug-Token", "wrong")
+	handler.ServeHTTP(httptest.NewRecorder(), ignored)
+
+	if !fileContains("./application.log", "debug /captured", t) {
+		fmt.Println("Missing debug entry of captured request")
+		t.FailNow()
+	}
+
+	if !fileContains("./access.log", "/captured", t) || !fileContains("./access.log", "418", t) {
+		fmt.Println("Missing public access log entry of captured request")
+		t.FailNow()
+	}
+
+	if fileContains("./application.log", "debug /ignored", t) || fileContains("./access.log", "/ignored", t) {
+		fmt.Println("Unexpected entry of request without a valid debug token")
+		t.FailNow()
+	}
+}
+
+func TestMiddlewareErrorCorrelation(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing" {
+			ErrorContext(r.Context(), "first error")
+			ErrorContext(r.Context(), "second error")
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://www.deal.com/failing", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://www.deal.com/working", nil))
+
+	path := "./access.log"
+
+	if !fileContains(path, "/failing HTTP/1.1", t) || !fileContains(path, "had_errors=true errors=2", t) {
+		fmt.Println("Missing error count from public access log entry")
+		t.FailNow()
+	}
+
+	if !fileContains(path, "/working HTTP/1.1", t) || strings.Count(readFile(path, t), "had_errors") != 1 {
+		fmt.Println("Unexpected error count in public access log entry")
+		t.FailNow()
+	}
+}
+
+func TestMiddlewareBodyCapture(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetBodyCapture(16)
+	defer SetBodyCapture(0)
+
+	SetBodyRedactions(regexp.MustCompile(`"password":"[^"]*"`))
+	defer SetBodyRedactions()
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(DEBUG)
+	defer SetAppLogLevel(INFO)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("hello world, this is a long response"))
+	}))
+
+	req := httptest.NewRequest("POST", "http://www.deal.com/login", strings.NewReader(`{"password":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "42")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	path := "./application.log"
+
+	if !fileContains(path, `[request body] request_id=42 method=POST url=http://www.deal.com/login content_type=application/json body={[REDACTED]}`, t) {
+		fmt.Println("Missing redacted request body")
+		t.FailNow()
+	}
+
+	if !fileContains(path, `[response body] request_id=42 method=POST url=http://www.deal.com/login content_type=text/plain body="hello world, thi" truncated=20`, t) {
+		fmt.Println("Missing truncated response body")
+		t.FailNow()
+	}
+}
+
+func TestMiddlewareBodyCaptureCutSecret(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	SetBodyCapture(32)
+	defer SetBodyCapture(0)
+
+	SetBodyRedactions(regexp.MustCompile(`"password":"[^"]*"`))
+	defer SetBodyRedactions()
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	SetAppLogLevel(DEBUG)
+	defer SetAppLogLevel(INFO)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest("POST", "http://www.deal.com/login", strings.NewReader(`{"user":"bob","password":"hunter2hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "43")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	path := "./application.log"
+
+	if !fileContains(path, `[request body] request_id=43 method=POST url=http://www.deal.com/login content_type=application/json body="{\"user\":\"bob\"," truncated=10`, t) {
+		fmt.Println("Missing truncated request body")
+		t.Fail()
+	}
+
+	if fileContains(path, "hunter", t) {
+		fmt.Println("Secret cut by the truncation logged")
+		t.Fail()
+	}
+}
+
+func TestPartialMatchStart(t *testing.T) {
+
+	pattern := regexp.MustCompile(`"password":"[^"]*"`)
+
+	for text, start := range map[string]int{
+		`{"user":"bob"`:                 12,
+		`{"user":"bob","pass`:           14,
+		`{"user":"bob","password":"hun`: 14,
+		`{"password":"x"} [REDACTED]`:   27,
+		`"password":"x`:                 0,
+		``:                              0,
+	} {
+		if got := partialMatchStart(pattern, []byte(text)); got != start {
+			fmt.Println("Unexpected partial match start", text, got, start)
+			t.Fail()
+		}
+	}
+
+	if got := partialMatchStart(regexp.MustCompile(`(?i)\bTOKEN=\w+`), []byte("a token=ab")); got != 2 {
+		fmt.Println("Unexpected partial match start with flags", got)
+		t.Fail()
+	}
+}
+
+func TestMiddlewareStreaming(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/live", LiveTailHandler())
+	mux.HandleFunc("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		buf.Flush()
+		conn.Close()
+	})
+
+	server := httptest.NewServer(Middleware(mux))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/live")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		fmt.Println("Live tail not streamed through the middleware", res.Status, res.Header)
+		t.Fail()
+	}
+	res.Body.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/upgrade", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		fmt.Println("Connection not hijacked through the middleware", res.Status)
+		t.Fail()
+	}
+
+	if w := (&responseRecorder{ResponseWriter: httptest.NewRecorder()}); w.Unwrap() == nil {
+		fmt.Println("Response writer not unwrapped")
+		t.Fail()
+	}
+}
@@ -0,0 +1,65 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var mmapWrites int32 // 1 to write the files through memory mappings
+
+var mmapSyncInterval = 1 * time.Second
+
+// EXPERIMENTAL: writes the app and public access log files through shared memory mappings of
+// preallocated 4MB regions instead of write calls (Linux and macOS only), for latency sensitive
+// services. The mapped regions are flushed every second and when the files are rotated, moved
+// or closed by Stop. Applied by the next Start.
+//
+// Crash consistency: the entries of a crashed process are kept by the kernel page cache, but
+// the entries written after the last flush are lost on a power failure or kernel crash. The file
+// ends with zero bytes until it is closed; they are removed when the file is opened again, and
+// skipped by the log readers.
+func SetMmapWrites(enabled bool) {
+	atomic.StoreInt32(&mmapWrites, toggle(enabled))
+}
+
+// Flushes the memory mapped files periodically.
+func syncMappedFiles(channels []*channel) {
+
+	defer routines.Done()
+
+	for idle(mmapSyncInterval) {
+		for _, c := range channels {
+			c.lock.RLock()
+			if c.mm != nil {
+				if err := c.mm.sync(); err != nil {
+					reportError(err)
+				}
+			}
+			c.lock.RUnlock()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package gol
+
+import (
+	"errors"
+	"os"
+)
+
+// mmapAppender is not supported on this platform.
+type mmapAppender struct{}
+
+func openMmapAppender(f *os.File) (*mmapAppender, error) {
+	return nil, errors.New("memory mapped writes are not supported on this platform")
+}
+
+func (m *mmapAppender) Write(b []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func (m *mmapAppender) size() int64 {
+	return 0
+}
+
+func (m *mmapAppender) sync() error {
+	return nil
+}
+
+func (m *mmapAppender) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build linux || darwin
+// +build linux darwin
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMmapWrites(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetMmapWrites(true)
+	defer SetMmapWrites(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	// A previous run crashed, leaving preallocated zero bytes
+	if err := os.WriteFile("./application.log", append([]byte("2000-01-01 00:00:00 INFO [before crash]\n"), make([]byte, 8192)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for i := 0; i < 100; i++ {
+		Info("mapped " + strconv.Itoa(i))
+	}
+
+	if info, _ := os.Stat("./application.log"); info.Size() < mmapRegionSize {
+		fmt.Println("File not preallocated", info.Size())
+		t.Fail()
+	}
+
+	reader, err := OpenLogReader(".", "application.log", ReaderOptions{Since: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, err := reader.Next(); err == nil; _, err = reader.Next() {
+		count++
+	}
+	reader.Close()
+
+	if count != 100 {
+		fmt.Println("Unexpected number of entries read", count)
+		t.Fail()
+	}
+
+	Stop()
+
+	content := readFile("./application.log", t)
+
+	if !strings.HasPrefix(content, "2000-01-01 00:00:00 INFO [before crash]\n") || strings.Contains(content, "\x00") ||
+		strings.Count(content, "\n") != 101 || !strings.Contains(content, "[mapped 99]") {
+		fmt.Println("Unexpected content", len(content))
+		t.Fail()
+	}
+}
@@ -0,0 +1,215 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build linux || darwin
+// +build linux darwin
+
+package gol
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const mmapRegionSize = 4 * 1024 * 1024 // Size of the file regions mapped at once
+
+// mmapAppender appends to a file by copying into a shared memory mapping of a preallocated
+// region of the file, without any syscall until the region is full.
+type mmapAppender struct {
+	file   *os.File
+	data   []byte // Mapping of the current region
+	base   int64  // Offset of the region in the file, page aligned
+	offset int64  // Bytes used in the region
+	lock   sync.Mutex
+}
+
+// Maps the end of the file. The zero bytes preallocated and not written before a crash are removed.
+func openMmapAppender(f *os.File) (*mmapAppender, error) {
+
+	size, err := dataSize(f)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mmapAppender{file: f}
+
+	if err := m.mapRegion(size, 0); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Returns the size of the file without its trailing zero bytes.
+func dataSize(f *os.File) (int64, error) {
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	size := info.Size()
+	buf := make([]byte, 64*1024)
+
+	for size > 0 {
+		start := size - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+
+		n, err := f.ReadAt(buf[:size-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		for i := n - 1; i >= 0; i-- {
+			if buf[i] != 0 {
+				return start + int64(i) + 1, nil
+			}
+		}
+
+		size = start
+	}
+
+	return 0, nil
+}
+
+// Maps a region of the file starting at the page of the offset, large enough for min more bytes.
+func (m *mmapAppender) mapRegion(offset int64, min int) error {
+
+	pageSize := int64(os.Getpagesize())
+	base := offset - offset%pageSize
+
+	length := int64(mmapRegionSize)
+	for length < offset-base+int64(min) {
+		length += mmapRegionSize
+	}
+
+	if err := m.file.Truncate(base + length); err != nil {
+		return err
+	}
+
+	data, err := syscall.Mmap(int(m.file.Fd()), base, int(length), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+
+	m.data, m.base, m.offset = data, base, offset-base
+
+	return nil
+}
+
+func (m *mmapAppender) unmap() error {
+
+	if m.data == nil {
+		return nil
+	}
+
+	err := syscall.Munmap(m.data)
+	m.data = nil
+
+	return err
+}
+
+func (m *mmapAppender) Write(b []byte) (int, error) {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.data == nil {
+		return 0, os.ErrClosed
+	}
+
+	if m.offset+int64(len(b)) > int64(len(m.data)) {
+		end := m.base + m.offset
+		if err := m.unmap(); err != nil {
+			return 0, err
+		}
+		if err := m.mapRegion(end, len(b)); err != nil {
+			return 0, err
+		}
+	}
+
+	copy(m.data[m.offset:], b)
+	m.offset += int64(len(b))
+
+	return len(b), nil
+}
+
+// Returns the size of the data written in the file.
+func (m *mmapAppender) size() int64 {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.base + m.offset
+}
+
+// Flushes the mapped region to the file.
+func (m *mmapAppender) sync() error {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.data == nil {
+		return nil
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// Flushes and unmaps the region, truncates the file to its data and closes it.
+func (m *mmapAppender) Close() error {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.data == nil {
+		return m.file.Close()
+	}
+
+	size := m.base + m.offset
+
+	syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+
+	if err := m.unmap(); err != nil {
+		m.file.Close()
+		return err
+	}
+
+	if err := m.file.Truncate(size); err != nil {
+		m.file.Close()
+		return err
+	}
+
+	return m.file.Close()
+}
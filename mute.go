@@ -0,0 +1,151 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const loggerKey = "logger" // Field holding the name of a named logger
+
+var mutes []string
+var muteLock = sync.RWMutex{}
+
+// Silences the app log entries of the named loggers or caller packages matching the pattern,
+// where * matches any sequence of characters, e.g. "kafka.consumer.*" or "github.com/Shopify/*".
+// FATAL entries are never muted.
+func Mute(pattern string) {
+
+	muteLock.Lock()
+	defer muteLock.Unlock()
+
+	for _, m := range mutes {
+		if m == pattern {
+			return
+		}
+	}
+
+	mutes = append(mutes, pattern)
+}
+
+// Removes the pattern from the mute list.
+func Unmute(pattern string) {
+
+	muteLock.Lock()
+	defer muteLock.Unlock()
+
+	for i, m := range mutes {
+		if m == pattern {
+			mutes = append(mutes[:i:i], mutes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns true if the category of the entry, its logger name or else the package of the caller
+// skip frames above, is muted.
+func muted(level int, fields []Field, skip int) bool {
+
+	if atLeast(level, FATAL) {
+		return false
+	}
+
+	muteLock.RLock()
+	defer muteLock.RUnlock()
+
+	if len(mutes) == 0 {
+		return false
+	}
+
+	category := ""
+
+	for _, f := range fields {
+		if f.Key == loggerKey {
+			category, _ = f.Value.(string)
+		}
+	}
+
+	if category == "" {
+		category = callerPackage(skip + 1)
+	}
+
+	for _, m := range mutes {
+		if globMatch(m, category) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the import path of the package of the caller skip frames above.
+func callerPackage(skip int) string {
+
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return ""
+	}
+
+	// e.g. github.com/alexv99/gol.(*Logger).Info
+	name := f.Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+
+	return name
+}
+
+// Matches the string against the pattern, where * matches any sequence of characters.
+func globMatch(pattern string, s string) bool {
+
+	parts := strings.Split(pattern, "*")
+
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
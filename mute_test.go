@@ -0,0 +1,99 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+
+	cases := map[string]bool{
+		"kafka.consumer.*|kafka.consumer.group": true,
+		"kafka.consumer.*|kafka.consumer":       false,
+		"kafka.*.group|kafka.consumer.group":    true,
+		"*.group|kafka.producer":                false,
+		"github.com/*|github.com/a/b":           true,
+		"exact|exact":                           true,
+		"exact|exactly":                         false,
+	}
+
+	for c, expected := range cases {
+		parts := strings.Split(c, "|")
+		if globMatch(parts[0], parts[1]) != expected {
+			fmt.Println("Unexpected match", parts)
+			t.Fail()
+		}
+	}
+}
+
+func TestMute(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	consumer := Named("kafka").Named("consumer").Named("group")
+
+	Mute("kafka.consumer.*")
+
+	consumer.Info("muted consumer")
+	Named("kafka").Info("kafka not muted")
+
+	Unmute("kafka.consumer.*")
+
+	consumer.Info("unmuted consumer")
+
+	Mute("github.com/alexv99/*")
+	Info("muted package")
+	Unmute("github.com/alexv99/*")
+
+	content := readFile("./application.log", t)
+
+	if strings.Contains(content, "[muted consumer]") || strings.Contains(content, "muted package") {
+		fmt.Println("Muted entries logged", content)
+		t.Fail()
+	}
+
+	if !strings.Contains(content, "[kafka not muted] logger=kafka") ||
+		!strings.Contains(content, "[unmuted consumer] logger=kafka.consumer.group") {
+		fmt.Println("Missing entries", content)
+		t.Fail()
+	}
+}
@@ -0,0 +1,76 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var occurrences = sync.Map{} // Number of calls per key of the Once and EveryN functions
+
+// Returns the number of calls for the key, this one included.
+func occurrence(key string) int64 {
+
+	counter, ok := occurrences.Load(key)
+	if !ok {
+		counter, _ = occurrences.LoadOrStore(key, new(int64))
+	}
+
+	return atomic.AddInt64(counter.(*int64), 1)
+}
+
+// Logs at INFO level the first call for the key only, e.g. in a loop.
+func InfoOnce(key string, v ...interface{}) {
+	if occurrence(key) == 1 {
+		appLog(INFO, appLogLevel(), nil, v)
+	}
+}
+
+// Logs at WARN level the first call for the key only, e.g. in a loop.
+func WarnOnce(key string, v ...interface{}) {
+	if occurrence(key) == 1 {
+		appLog(WARN, appLogLevel(), nil, v)
+	}
+}
+
+// Logs at INFO level the first call for the key and then every n calls, with the number of calls.
+func InfoEveryN(key string, n int64, v ...interface{}) {
+	if count := occurrence(key); n <= 1 || count%n == 1 {
+		appLog(INFO, appLogLevel(), []Field{{Key: "occurrences", Value: count}}, v)
+	}
+}
+
+// Logs at WARN level the first call for the key and then every n calls, with the number of calls.
+func WarnEveryN(key string, n int64, v ...interface{}) {
+	if count := occurrence(key); n <= 1 || count%n == 1 {
+		appLog(WARN, appLogLevel(), []Field{{Key: "occurrences", Value: count}}, v)
+	}
+}
+
+// Resets the calls counted for the key, e.g. once the condition logged is over.
+func ResetOccurrences(key string) {
+	occurrences.Delete(key)
+}
@@ -0,0 +1,73 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOnceAndEveryN(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	defer ResetOccurrences("cache")
+	defer ResetOccurrences("retry")
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for i := 0; i < 25; i++ {
+		InfoOnce("cache", "cache warming")
+		WarnEveryN("retry", 10, "retrying")
+	}
+
+	content := readFile("./application.log", t)
+
+	if strings.Count(content, "cache warming") != 1 || strings.Count(content, "retrying") != 3 ||
+		!strings.Contains(content, "[retrying] occurrences=21") {
+		fmt.Println("Unexpected entries", content)
+		t.Fail()
+	}
+
+	ResetOccurrences("cache")
+	InfoOnce("cache", "cache warming")
+
+	if strings.Count(readFile("./application.log", t), "cache warming") != 2 {
+		fmt.Println("Occurrences not reset")
+		t.Fail()
+	}
+}
@@ -0,0 +1,145 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+)
+
+// Option is an option of Start, e.g. WithLevel(gol.DEBUG). A Config is an option too, applying
+// the whole configuration.
+type Option interface {
+	validate() error
+	apply()
+}
+
+// Option validated then applied by Start
+type option struct {
+	check func() error
+	set   func()
+}
+
+func (o option) validate() error {
+
+	if o.check == nil {
+		return nil
+	}
+
+	return o.check()
+}
+
+func (o option) apply() {
+	o.set()
+}
+
+// Returns an *ErrInvalidConfig for the option.
+func invalidOption(option string, message string) error {
+	return &ErrInvalidConfig{Problems: []ConfigProblem{{Option: option, Message: message}}}
+}
+
+func (c Config) validate() error {
+	return c.Validate()
+}
+
+// Sets the folder of the app log files.
+func WithAppLogFolder(path string) Option {
+	return option{
+		check: func() error {
+			if path == "" {
+				return invalidOption("app.folder", "empty app log folder")
+			}
+			return nil
+		},
+		set: func() { SetAppLogFolder(path) },
+	}
+}
+
+// Sets the folder of the public access log files.
+func WithPublicLogFolder(path string) Option {
+	return option{
+		check: func() error {
+			if path == "" {
+				return invalidOption("public.folder", "empty public log folder")
+			}
+			return nil
+		},
+		set: func() { SetPublicLogFolder(path) },
+	}
+}
+
+// Sets the max size in KB of the app and public access log files, rotated once reached.
+func WithMaxSize(size int64) Option {
+	return option{
+		check: func() error {
+			if size <= 0 {
+				return invalidOption("max_size", "invalid max size "+strconv.FormatInt(size, 10))
+			}
+			return nil
+		},
+		set: func() {
+			SetAppLogMaxSize(size)
+			SetPublicLogMaxSize(size)
+		},
+	}
+}
+
+// Sets the max age in days of the app and public access log files, purged once reached.
+func WithMaxAge(days int) Option {
+	return option{
+		check: func() error {
+			if days <= 0 {
+				return invalidOption("max_age", "invalid max age "+strconv.Itoa(days))
+			}
+			return nil
+		},
+		set: func() {
+			SetAppLogMaxAge(days)
+			SetPublicLogMaxAge(days)
+		},
+	}
+}
+
+// Sets the app log level.
+func WithLevel(level int) Option {
+	return option{
+		check: func() error {
+			if !isLevel(level) || level == FATAL {
+				return invalidOption("level", "invalid level "+strconv.Itoa(level))
+			}
+			return nil
+		},
+		set: func() { SetAppLogLevel(level) },
+	}
+}
+
+// Prints the app log entries to stdout, or not.
+func WithStdout(enabled bool) Option {
+	return option{set: func() { LogToStdout(enabled) }}
+}
+
+// Makes every log call write its entry before returning, see SetSynchronous.
+func WithSynchronous(enabled bool) Option {
+	return option{set: func() { SetSynchronous(enabled) }}
+}
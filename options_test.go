@@ -0,0 +1,72 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestStartOptions(t *testing.T) {
+	removeLogFiles(".")
+	os.RemoveAll("./options")
+	defer os.RemoveAll("./options")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+
+	if err := Start(WithAppLogFolder("./not-applied"), WithLevel(FATAL)); err == nil {
+		fmt.Println("Invalid option accepted")
+		t.Fail()
+	}
+
+	if appChannel.folder != "." {
+		fmt.Println("Options applied despite an invalid one", appChannel.folder)
+		t.Fail()
+	}
+
+	err := Start(WithAppLogFolder("./options"), WithMaxSize(2048), WithMaxAge(3), WithLevel(DEBUG), WithStdout(false), WithSynchronous(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Debug("configured by options")
+
+	Stop()
+
+	SetAppLogFolder(".")
+	SetAppLogLevel(INFO)
+	SetAppLogMaxSize(1024)
+	SetPublicLogMaxSize(1024)
+	SetAppLogMaxAge(10)
+	SetPublicLogMaxAge(10)
+	SetSynchronous(false)
+
+	if !fileContains("./options/application.log", "DEBUG [configured by options]", t) {
+		fmt.Println("Options not applied")
+		t.Fail()
+	}
+}
@@ -0,0 +1,161 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Route of the requests not matching any pattern
+const OtherRoute = "other"
+
+var routePatterns [][]string // Segments of the route patterns
+var routeLatencies = map[string]*latencyHistogram{}
+var routePatternLock = sync.RWMutex{}
+
+// Registers a route pattern, e.g. "/users/:id" or "/static/*", so that the requests are
+// aggregated by route rather than by URL in the public access log (route=...) and in the
+// latency statistics. A :name segment matches any segment, a final * matches the rest of the
+// path. Requests not matching any pattern are aggregated as OtherRoute.
+func AddRoutePattern(pattern string) error {
+
+	if !strings.HasPrefix(pattern, "/") {
+		return errors.New("route pattern must start with / [" + pattern + "]")
+	}
+
+	segments := strings.Split(pattern, "/")[1:]
+
+	for i, s := range segments {
+		if s == "*" && i != len(segments)-1 {
+			return errors.New("* must end the route pattern [" + pattern + "]")
+		}
+	}
+
+	routePatternLock.Lock()
+	defer routePatternLock.Unlock()
+
+	if _, ok := routeLatencies[pattern]; !ok {
+		routePatterns = append(routePatterns, segments)
+		routeLatencies[pattern] = &latencyHistogram{}
+	}
+
+	return nil
+}
+
+// Removes all the route patterns.
+func ClearRoutePatterns() {
+	routePatternLock.Lock()
+	routePatterns = nil
+	routeLatencies = map[string]*latencyHistogram{}
+	routePatternLock.Unlock()
+}
+
+// Returns the first pattern matching the path, OtherRoute if none, or empty if no pattern is registered.
+func routeOf(path string) string {
+
+	routePatternLock.RLock()
+	defer routePatternLock.RUnlock()
+
+	if len(routePatterns) == 0 {
+		return ""
+	}
+
+	segments := strings.Split(path, "/")[1:]
+
+	for _, pattern := range routePatterns {
+		if matchSegments(pattern, segments) {
+			return "/" + strings.Join(pattern, "/")
+		}
+	}
+
+	return OtherRoute
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+
+	for i, p := range pattern {
+		if p == "*" {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if segments[i] != p && !(strings.HasPrefix(p, ":") && segments[i] != "") {
+			return false
+		}
+	}
+
+	return len(pattern) == len(segments)
+}
+
+// Records the latency of a request of the route.
+func recordRouteLatency(route string, d time.Duration) {
+
+	if route == "" {
+		return
+	}
+
+	routePatternLock.RLock()
+	h, ok := routeLatencies[route]
+	routePatternLock.RUnlock()
+
+	if !ok {
+		routePatternLock.Lock()
+		if h, ok = routeLatencies[route]; !ok {
+			h = &latencyHistogram{}
+			routeLatencies[route] = h
+		}
+		routePatternLock.Unlock()
+	}
+
+	h.record(d)
+}
+
+// Returns the latency histograms per route.
+func routeHistograms() map[string]Histogram {
+
+	routePatternLock.RLock()
+	defer routePatternLock.RUnlock()
+
+	histograms := make(map[string]Histogram, len(routeLatencies))
+	for route, h := range routeLatencies {
+		histograms[route] = h.snapshot()
+	}
+
+	return histograms
+}
+
+func resetRouteLatencies() {
+
+	routePatternLock.RLock()
+	defer routePatternLock.RUnlock()
+
+	for _, h := range routeLatencies {
+		h.reset()
+	}
+}
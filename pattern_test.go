@@ -0,0 +1,89 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRoutePatterns(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+	defer ClearRoutePatterns()
+
+	if AddRoutePattern("users") == nil || AddRoutePattern("/static/*/x") == nil {
+		fmt.Println("Invalid patterns accepted")
+		t.Fail()
+	}
+
+	AddRoutePattern("/users/:id")
+	AddRoutePattern("/users/:id/orders/:order")
+	AddRoutePattern("/static/*")
+
+	cases := map[string]string{
+		"/users/42":           "/users/:id",
+		"/users/":             OtherRoute,
+		"/users/42/orders/7":  "/users/:id/orders/:order",
+		"/static/css/app.css": "/static/*",
+		"/health":             OtherRoute,
+	}
+
+	for path, route := range cases {
+		if r := routeOf(path); r != route {
+			fmt.Println("Unexpected route", path, r)
+			t.Fail()
+		}
+	}
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Public(*httptest.NewRequest("GET", "/users/1", nil), 200, 10, time.Millisecond)
+	Public(*httptest.NewRequest("GET", "/users/2", nil), 200, 10, time.Millisecond)
+
+	if h := Stats().Routes["/users/:id"]; h.Count != 2 {
+		fmt.Println("Unexpected route count", h.Count)
+		t.Fail()
+	}
+
+	if !fileContains("./access.log", "route=/users/:id ", t) {
+		fmt.Println("Missing route in the public access log")
+		t.Fail()
+	}
+}
@@ -0,0 +1,150 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+)
+
+var personalChannel = &channel{folder: "/var/log", name: "personal.log", maxSize: 1024, maxAge: 1}
+
+var personalLogEnabled int32 // Set by SetPersonalDataLog, applied by Start
+var personalLogActive int32  // 1 if the personal data fields are written in the personal data log
+
+const personalRefKey = "pii_ref"
+const personalPlaceholder = "scrubbed"
+
+// Personal data, see Personal.
+type personalValue struct {
+	v interface{}
+}
+
+// Returns a field holding personal data (e.g. an email address), written in the personal data
+// log rather than in the app log when it is enabled, see SetPersonalDataLog. Otherwise it is
+// logged as a regular field.
+func Personal(key string, v interface{}) Field {
+	return Field{Key: key, Value: personalValue{v: v}}
+}
+
+// Writes the personal data fields of the app log entries (see Personal) in personal.log, in the
+// app log folder, purged once older than maxAge days, so that they can be retained for a shorter
+// time than the app log. The app log entries keep the fields with a scrubbed value and a pii_ref
+// field, also written in the personal data log entry to link both. Applied by the next Start.
+func SetPersonalDataLog(enabled bool, maxAge int) {
+	atomic.StoreInt32(&personalLogEnabled, toggle(enabled))
+	personalChannel.update(func() { personalChannel.maxAge = maxAge })
+}
+
+// Opens the personal data log, in the app log folder, if enabled.
+func openPersonalLog() error {
+
+	atomic.StoreInt32(&personalLogActive, 0)
+
+	if atomic.LoadInt32(&personalLogEnabled) == 0 {
+		return nil
+	}
+
+	personalChannel.folder = appChannel.folder
+	personalChannel.maxSize = appChannel.maxSize
+
+	if err := personalChannel.open(); err != nil {
+		return err
+	}
+
+	atomic.StoreInt32(&personalLogActive, 1)
+
+	return nil
+}
+
+// Returns the fields with the personal data values scrubbed and the fields holding them,
+// or with the values unwrapped if the personal data log is not active.
+func splitPersonal(fields []Field) ([]Field, []Field) {
+
+	var personal []Field
+	var split []Field
+
+	for i, f := range fields {
+		p, ok := f.Value.(personalValue)
+		if !ok {
+			continue
+		}
+
+		if split == nil {
+			split = make([]Field, len(fields))
+			copy(split, fields)
+		}
+
+		if atomic.LoadInt32(&personalLogActive) == 1 {
+			personal = append(personal, Field{Key: f.Key, Value: p.v})
+			split[i].Value = personalPlaceholder
+		} else {
+			split[i].Value = p.v
+		}
+	}
+
+	if split == nil {
+		return fields, nil
+	}
+
+	return split, personal
+}
+
+// Returns a reference linking an app log entry to its personal data log entry.
+func personalRef() string {
+
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Returns the personal data log entry of the app log entry.
+func formatPersonal(e *Entry, personal []Field, ref string) string {
+
+	buf := make([]byte, 0, 128)
+
+	buf = append(buf, formatSecond(e.Time)...)
+	buf = append(buf, levelPrefix(e.Level)...)
+	buf = append(buf, e.Message...)
+	buf = append(buf, ']')
+
+	for _, f := range personal {
+		buf = append(buf, ' ')
+		buf = appendField(buf, f)
+	}
+
+	buf = append(buf, " "+personalRefKey+"="...)
+	buf = append(buf, ref...)
+
+	if e.Sequence > 0 {
+		buf = append(buf, " seq="...)
+		buf = strconv.AppendUint(buf, e.Sequence, 10)
+	}
+
+	return string(append(buf, '\n'))
+}
@@ -0,0 +1,86 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestPersonalDataLog(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetPersonalDataLog(true, 1)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("signup", Personal("email", "alice@example.com"), Field{Key: "plan", Value: "pro"})
+	Info("no personal data")
+
+	Stop()
+
+	app := readFile("./application.log", t)
+	personal := readFile("./personal.log", t)
+
+	m := regexp.MustCompile(`INFO \[signup\] email=scrubbed plan=pro pii_ref=([0-9a-f]{16}) at `).FindStringSubmatch(app)
+	if m == nil || fileContains("./application.log", "alice", t) {
+		fmt.Println("Personal data not scrubbed from the app log", app)
+		t.Fail()
+	} else if !fileContains("./personal.log", "INFO [signup] email=alice@example.com pii_ref="+m[1]+"\n", t) {
+		fmt.Println("Personal data not written in the personal data log", personal)
+		t.Fail()
+	}
+
+	if fileContains("./personal.log", "no personal data", t) {
+		fmt.Println("Entry without personal data written in the personal data log", personal)
+		t.Fail()
+	}
+
+	// Regular field once the personal data log is disabled
+	SetPersonalDataLog(false, 1)
+	removeLogFiles(".")
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("signup", Personal("email", "bob@example.com"))
+
+	Stop()
+
+	if !fileContains("./application.log", "INFO [signup] email=bob@example.com at ", t) {
+		fmt.Println("Personal data field not logged as a regular field", readFile("./application.log", t))
+		t.Fail()
+	}
+}
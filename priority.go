@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+var priorityLogChan chan *Entry
+
+// Logs the message at the level with high priority, for the operator-critical messages: the entry
+// goes through its own queue and write routine, so it isn't delayed by a full app log queue, and
+// it is never shed (see SetLevelShedding and SetWriteBudget) nor dropped.
+func Priority(level int, v ...interface{}) {
+	if isLevel(level) && level != FATAL {
+		priorityLog(level, appLogLevel(), nil, v)
+	}
+}
+
+// Logs the message at the level with high priority, see Priority.
+func (l *Logger) Priority(level int, v ...interface{}) {
+	if isLevel(level) && level != FATAL {
+		priorityLog(level, l.level(), l.fields, v)
+	}
+}
+
+// Sends a high priority entry to the priority write routine.
+func priorityLog(level int, minLevel int, fields []Field, v []interface{}) {
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
+	if !running {
+		return
+	}
+
+	if e := decorateAppLogEntry(level, minLevel, fields, v, 3); e != nil {
+		e.priority = true
+
+		if isSynchronous() {
+			doAppLogWrite(e)
+			return
+		}
+
+		priorityLogChan <- e
+	}
+}
+
+// Returns the level of the entry for the write budget, high priority entries being always written.
+func (e *Entry) budgetLevel() int {
+
+	if e.priority {
+		return FATAL
+	}
+
+	return e.Level
+}
@@ -0,0 +1,93 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPriority(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Priority(WARN, "replica lagging")
+	Named("db").Priority(ERROR, "failover started")
+	Priority(DEBUG, "below the level")
+
+	Stop()
+
+	path := "./application.log"
+
+	if !fileContains(path, "WARN [replica lagging]", t) || !fileContains(path, "ERROR [failover started] logger=db", t) {
+		fmt.Println("High priority entries not written")
+		t.Fail()
+	}
+
+	if !fileContains(path, "priority_test.go", t) || fileContains(path, "below the level", t) {
+		fmt.Println("Unexpected caller or level of the high priority entries")
+		t.Fail()
+	}
+}
+
+func TestPriorityOverWriteBudget(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetWriteBudget(512)
+	defer SetWriteBudget(0)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		Info("flood", i)
+	}
+	Priority(INFO, "operator message")
+	Info("shed message")
+
+	Stop()
+
+	text := readFile("./application.log", t)
+
+	if !strings.Contains(text, "operator message") || strings.Contains(text, "shed message") {
+		fmt.Println("High priority entry shed over the write budget")
+		t.Fail()
+	}
+}
@@ -0,0 +1,127 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Progress logs a throttled heartbeat of a long job, e.g. "import processed 10k/1M items, ETA 3m0s".
+type Progress struct {
+	name    string
+	total   int64
+	every   time.Duration
+	start   time.Time
+	done    int64
+	lastLog int64 // Unix nanoseconds of the last heartbeat
+}
+
+// Returns a progress of the job logging a heartbeat at INFO level at most every interval.
+// The total is the number of items of the job, 0 if unknown.
+func NewProgress(name string, total int64, every time.Duration) *Progress {
+
+	now := time.Now()
+
+	return &Progress{name: name, total: total, every: every, start: now, lastLog: now.UnixNano()}
+}
+
+// Adds processed items, logging a heartbeat if the last one is older than the interval.
+func (p *Progress) Add(n int64) {
+
+	done := atomic.AddInt64(&p.done, n)
+
+	now := time.Now()
+	last := atomic.LoadInt64(&p.lastLog)
+
+	if now.UnixNano()-last < int64(p.every) || !atomic.CompareAndSwapInt64(&p.lastLog, last, now.UnixNano()) {
+		return
+	}
+
+	appLog(INFO, appLogLevel(), p.fields(done, now), []interface{}{p.message(done, now)})
+}
+
+// Logs the final heartbeat of the job.
+func (p *Progress) Done() {
+
+	done := atomic.LoadInt64(&p.done)
+	now := time.Now()
+
+	appLog(INFO, appLogLevel(), p.fields(done, now), []interface{}{p.name + " processed " + formatCount(done) + " items in " + now.Sub(p.start).Round(time.Millisecond).String()})
+}
+
+func (p *Progress) message(done int64, now time.Time) string {
+
+	if p.total <= 0 {
+		return p.name + " processed " + formatCount(done) + " items"
+	}
+
+	msg := p.name + " processed " + formatCount(done) + "/" + formatCount(p.total) + " items"
+
+	if eta := p.eta(done, now); eta > 0 {
+		msg += ", ETA " + eta.String()
+	}
+
+	return msg
+}
+
+func (p *Progress) fields(done int64, now time.Time) []Field {
+
+	fields := []Field{{Key: "done", Value: done}}
+
+	if p.total > 0 {
+		fields = append(fields, Field{Key: "total", Value: p.total})
+	}
+
+	return append(fields, Field{Key: "elapsed", Value: now.Sub(p.start).Round(time.Millisecond)})
+}
+
+// Returns the estimated time left, extrapolated from the rate since the start.
+func (p *Progress) eta(done int64, now time.Time) time.Duration {
+
+	if done <= 0 || done >= p.total {
+		return 0
+	}
+
+	elapsed := now.Sub(p.start)
+	left := time.Duration(float64(elapsed) * float64(p.total-done) / float64(done))
+
+	return left.Round(time.Second)
+}
+
+// Formats a count with a k or M suffix, e.g. 10k or 1.5M.
+func formatCount(n int64) string {
+
+	switch {
+	case n >= 1000000:
+		return strings.TrimSuffix(strconv.FormatFloat(float64(n)/1000000, 'f', 1, 64), ".0") + "M"
+	case n >= 1000:
+		return strconv.FormatInt(n/1000, 10) + "k"
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
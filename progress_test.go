@@ -0,0 +1,82 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatCount(t *testing.T) {
+
+	cases := map[int64]string{999: "999", 10000: "10k", 1000000: "1M", 1500000: "1.5M"}
+
+	for n, expected := range cases {
+		if s := formatCount(n); s != expected {
+			fmt.Println("Unexpected count", n, s)
+			t.Fail()
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	p := NewProgress("import", 1000000, 20*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		p.Add(1000) // Throttled
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	p.Add(1000)
+	p.Add(1000) // Throttled
+	p.Done()
+
+	content := readFile("./application.log", t)
+
+	if strings.Count(content, "\n") != 2 || !strings.Contains(content, "[import processed 11k/1M items, ETA ") ||
+		!strings.Contains(content, "[import processed 12k items in ") {
+		fmt.Println("Unexpected heartbeats", content)
+		t.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var publicHooks []func(e *AccessEntry) bool
+var publicHooksLock = sync.RWMutex{}
+var vetoedCount int64
+
+// Adds a hook called with each public access log entry before it is formatted, in the order
+// the hooks were added. A hook may enrich the entry (e.g. a tenant field looked up from the API
+// key header of e.Request()) or drop it by returning false (e.g. known scanners), in which case
+// the next hooks are not called. Hooks are called by the goroutine calling Public.
+func AddPublicHook(hook func(e *AccessEntry) bool) {
+
+	publicHooksLock.Lock()
+	defer publicHooksLock.Unlock()
+
+	publicHooks = append(publicHooks, hook)
+}
+
+// Removes all the hooks added with AddPublicHook.
+func ClearPublicHooks() {
+
+	publicHooksLock.Lock()
+	defer publicHooksLock.Unlock()
+
+	publicHooks = nil
+}
+
+// Returns false if a hook dropped the entry.
+func runPublicHooks(e *AccessEntry) bool {
+
+	publicHooksLock.RLock()
+	defer publicHooksLock.RUnlock()
+
+	for _, hook := range publicHooks {
+		if !hook(e) {
+			atomic.AddInt64(&vetoedCount, 1)
+			return false
+		}
+	}
+
+	return true
+}
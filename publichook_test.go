@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublicHooks(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	AddPublicHook(func(e *AccessEntry) bool {
+		return !strings.Contains(e.UserAgent, "zgrab")
+	})
+	AddPublicHook(func(e *AccessEntry) bool {
+		if key := e.Request().Header.Get("X-Api-Key"); key != "" {
+			e.Fields = append(e.Fields, Field{Key: "tenant", Value: "tenant-" + key})
+		}
+		return true
+	})
+	defer ClearPublicHooks()
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	req, _ := http.NewRequest("GET", "http://www.deal.com/orders", nil)
+	req.Header.Set("X-Api-Key", "42")
+	Public(*req, 200, 10, time.Millisecond)
+
+	scan, _ := http.NewRequest("GET", "http://www.deal.com/.env", nil)
+	scan.Header.Set("User-Agent", "Mozilla/5.0 zgrab/0.x")
+	Public(*scan, 404, 0, time.Millisecond)
+
+	Stop()
+
+	if !fileContains("./access.log", "/orders", t) || !fileContains("./access.log", "tenant=tenant-42", t) ||
+		fileContains("./access.log", "/.env", t) || Stats().Vetoed != 1 {
+		fmt.Println("Unexpected access log", readFile("./access.log", t), Stats().Vetoed)
+		t.Fail()
+	}
+}
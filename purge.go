@@ -0,0 +1,324 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var purgeInterval = 1 * time.Minute // Time between two purges
+var purgeJitter time.Duration = 0   // Random extra time between two purges
+var purgeLock = sync.RWMutex{}
+var purgePaused = false // Legal hold of all the channels
+var purgeDryRun = false // Only log the files the purge would remove
+
+// Retention keeps the archives of a log by calendar periods (grandfather-father-son), e.g. all the
+// archives of the last 7 days, one per day for 30 days and one per week for a year. The newest
+// archive of each period is kept, archives not kept by any period are purged.
+type Retention struct {
+	All     int `json:"all" yaml:"all"`         // Keep all the archives of the last All days
+	Daily   int `json:"daily" yaml:"daily"`     // Keep one archive per day for the last Daily days
+	Weekly  int `json:"weekly" yaml:"weekly"`   // Keep one archive per week for the last Weekly weeks
+	Monthly int `json:"monthly" yaml:"monthly"` // Keep one archive per month for the last Monthly months
+}
+
+func (r Retention) enabled() bool {
+	return r.All > 0 || r.Daily > 0 || r.Weekly > 0 || r.Monthly > 0
+}
+
+// Returns the archives (newest first) not kept by the retention.
+func (r Retention) expired(archives []os.FileInfo, now time.Time) (expired []os.FileInfo) {
+
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+	months := map[string]bool{}
+
+	for i := len(archives) - 1; i >= 0; i-- {
+		t := archives[i].ModTime().Local()
+
+		kept := t.After(now.AddDate(0, 0, -r.All))
+
+		if day := t.Format("2006-01-02"); t.After(now.AddDate(0, 0, -r.Daily)) && !days[day] {
+			days[day] = true
+			kept = true
+		}
+
+		year, week := t.ISOWeek()
+		if key := strconv.Itoa(year) + "-" + strconv.Itoa(week); t.After(now.AddDate(0, 0, -7*r.Weekly)) && !weeks[key] {
+			weeks[key] = true
+			kept = true
+		}
+
+		if month := t.Format("2006-01"); t.After(now.AddDate(0, -r.Monthly, 0)) && !months[month] {
+			months[month] = true
+			kept = true
+		}
+
+		if !kept {
+			expired = append(expired, archives[i])
+		}
+	}
+
+	return expired
+}
+
+// Suspends (or resumes) the deletion of the log files of all the channels by the purge, quota and
+// disk space watchdog routines, e.g. during an incident investigation or a legal hold.
+func SetPurgePaused(paused bool) {
+	purgeLock.Lock()
+	purgePaused = paused
+	purgeLock.Unlock()
+}
+
+// Suspends (or resumes) the deletion of the app log files.
+func SetAppLogPurgePaused(paused bool) {
+	purgeLock.Lock()
+	appChannel.purgePaused = paused
+	purgeLock.Unlock()
+}
+
+// Suspends (or resumes) the deletion of the public access log files.
+func SetPublicLogPurgePaused(paused bool) {
+	purgeLock.Lock()
+	publicChannel.purgePaused = paused
+	purgeLock.Unlock()
+}
+
+// Returns true if the files of the channel must not be deleted.
+func (c *channel) deletionPaused() bool {
+	purgeLock.RLock()
+	defer purgeLock.RUnlock()
+	return purgePaused || c.purgePaused
+}
+
+// Sets the time between two purges of the expired log files, plus a random jitter up to the
+// given duration so that instances sharing a volume don't scan it at the same time. A non
+// positive interval is ignored.
+func SetPurgeInterval(interval time.Duration, jitter time.Duration) {
+
+	if interval <= 0 {
+		return
+	}
+
+	if jitter < 0 {
+		jitter = 0
+	}
+
+	purgeLock.Lock()
+	purgeInterval = interval
+	purgeJitter = jitter
+	purgeLock.Unlock()
+}
+
+// Makes the purge routine only log the files it would remove and the space it would reclaim.
+func SetPurgeDryRun(dryRun bool) {
+	purgeLock.Lock()
+	purgeDryRun = dryRun
+	purgeLock.Unlock()
+}
+
+// Purges the app, public access and error logs now. Returns the removed files, or the files
+// that would be removed in dry run mode.
+func PurgeNow() (removed []string, err error) {
+
+	purgeLock.RLock()
+	dryRun := purgeDryRun
+	purgeLock.RUnlock()
+
+	removed, _, err = purge(logChannels(), dryRun)
+
+	return removed, err
+}
+
+// Returns the files the purge would remove and the space in bytes it would reclaim.
+func PurgePreview() (files []string, size int64, err error) {
+
+	candidates, err := purgeCandidates(logChannels())
+
+	for _, f := range candidates {
+		files = append(files, f.path)
+		size += f.size
+	}
+
+	return files, size, err
+}
+
+func purgeFiles(channels ...*channel) {
+
+	defer routines.Done()
+
+	for {
+
+		purgeLock.RLock()
+		dryRun := purgeDryRun
+		purgeLock.RUnlock()
+
+		purge(channels, dryRun)
+
+		purgeLock.RLock()
+		sleep := purgeInterval
+		if purgeJitter > 0 {
+			sleep += time.Duration(rand.Int63n(int64(purgeJitter)))
+		}
+		purgeLock.RUnlock()
+
+		if !idle(sleep) {
+			return
+		}
+	}
+}
+
+// Removes the files to purge, or only logs them in dry run mode. Returns the removed
+// files and the reclaimed space in bytes.
+func purge(channels []*channel, dryRun bool) (removed []string, reclaimed int64, err error) {
+
+	candidates, err := purgeCandidates(channels)
+
+	for _, f := range candidates {
+
+		if dryRun {
+			log.Println("Purge routine would remove file [" + f.path + "]")
+		} else if e := removeArchive(f.path); e != nil {
+			log.Println("ERROR: Purge routine unable to remove file ["+f.path+"]", e)
+			if err == nil {
+				err = e
+			}
+			continue
+		} else {
+			log.Println("Purge routine removed file [" + f.path + "]")
+		}
+
+		removed = append(removed, f.path)
+		reclaimed += f.size
+	}
+
+	if !dryRun && len(removed) > 0 {
+		for _, c := range channels {
+			c.updateManifest()
+		}
+	}
+
+	if dryRun && len(removed) > 0 {
+		log.Println("Purge routine would reclaim " + strconv.FormatInt(reclaimed/1024, 10) + "KB")
+	}
+
+	return removed, reclaimed, err
+}
+
+// File to purge.
+type purgeCandidate struct {
+	path string
+	size int64
+}
+
+// Returns the files of the channels beyond their quota, not kept by their retention or
+// older than their max age, scanning each folder once.
+func purgeCandidates(channels []*channel) (candidates []purgeCandidate, err error) {
+
+	seen := map[string]bool{}
+
+	add := func(folder string, f os.FileInfo) {
+		path := folder + "/" + f.Name()
+		if !seen[path] && !awaitingShipment(path) {
+			seen[path] = true
+			candidates = append(candidates, purgeCandidate{path: path, size: f.Size()})
+		}
+	}
+
+	// Settings of a channel, which may be resized meanwhile (see Reload)
+	type aged struct {
+		name   string
+		maxAge int
+	}
+
+	var folders []string
+	byFolder := map[string][]aged{}
+
+	for _, c := range channels {
+		if c.deletionPaused() {
+			continue
+		}
+
+		c.lock.RLock()
+		folder, name, maxAge, retention := c.folder, c.name, c.maxAge, c.retention
+		c.lock.RUnlock()
+
+		files, e := c.overQuota()
+		if e != nil {
+			log.Println("ERROR: Purge routine unable to list archives", e)
+			err = e
+		}
+		for _, f := range files {
+			add(folder, f)
+		}
+
+		if retention.enabled() {
+			archives, e := c.archives()
+			if e != nil {
+				log.Println("ERROR: Purge routine unable to list archives", e)
+				err = e
+			}
+			for _, f := range retention.expired(archives, time.Now()) {
+				add(folder, f)
+			}
+			continue
+		}
+
+		if _, ok := byFolder[folder]; !ok {
+			folders = append(folders, folder)
+		}
+		byFolder[folder] = append(byFolder[folder], aged{name: name, maxAge: maxAge})
+	}
+
+	for _, folder := range folders {
+
+		files, e := ioutil.ReadDir(folder)
+		if e != nil {
+			log.Println("ERROR: Purge routine unable to read directory ["+folder+"]", e)
+			err = e
+		}
+
+		for _, f := range files {
+			for _, c := range byFolder[folder] {
+				if !strings.HasSuffix(archiveName(f.Name()), c.name) {
+					continue
+				}
+				if f.ModTime().Before(time.Now().AddDate(0, 0, 0-c.maxAge)) {
+					add(folder, f)
+				}
+				break
+			}
+		}
+	}
+
+	return candidates, err
+}
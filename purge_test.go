@@ -0,0 +1,202 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// Creates a file modified the given number of days ago.
+func createOldFile(name string, days int, t *testing.T) {
+
+	if err := ioutil.WriteFile(name, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Now().AddDate(0, 0, -days)
+	os.Chtimes(name, modTime, modTime)
+}
+
+func TestPurge(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxAge(10)
+	SetPublicLogMaxAge(5)
+
+	createOldFile("2000-01-01-0-application.log", 20, t)
+	createOldFile("2000-01-02-0-application.log", 7, t)
+	createOldFile("2000-01-01-0-access.log", 7, t)
+	createOldFile("2000-01-02-0-access.log", 1, t)
+
+	purge([]*channel{appChannel, publicChannel}, false)
+
+	for name, kept := range map[string]bool{
+		"2000-01-01-0-application.log": false,
+		"2000-01-02-0-application.log": true,
+		"2000-01-01-0-access.log":      false,
+		"2000-01-02-0-access.log":      true,
+	} {
+		if _, err := os.Stat(name); os.IsNotExist(err) == kept {
+			fmt.Println("Unexpected purge of " + name)
+			t.Fail()
+		}
+	}
+}
+
+func TestPurgePaused(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxAge(10)
+	SetPublicLogMaxAge(10)
+
+	createOldFile("2000-01-01-0-application.log", 20, t)
+	createOldFile("2000-01-01-0-access.log", 20, t)
+
+	SetAppLogPurgePaused(true)
+	defer SetAppLogPurgePaused(false)
+
+	purge([]*channel{appChannel, publicChannel}, false)
+
+	if !fileExists("2000-01-01-0-application.log", t) {
+		fmt.Println("App log purged while paused")
+		t.Fail()
+	}
+
+	if _, err := os.Stat("2000-01-01-0-access.log"); !os.IsNotExist(err) {
+		fmt.Println("Public log not purged")
+		t.Fail()
+	}
+
+	SetPurgePaused(true)
+	SetAppLogPurgePaused(false)
+
+	purge([]*channel{appChannel, publicChannel}, false)
+
+	SetPurgePaused(false)
+
+	if !fileExists("2000-01-01-0-application.log", t) {
+		fmt.Println("App log purged while all channels paused")
+		t.Fail()
+	}
+}
+
+func TestPurgeDryRun(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxAge(10)
+	SetPublicLogMaxAge(10)
+
+	createOldFile("2000-01-01-0-application.log", 20, t)
+	createOldFile("2000-01-02-0-application.log", 1, t)
+
+	files, size, err := PurgePreview()
+
+	if err != nil || fmt.Sprint(files) != "[./2000-01-01-0-application.log]" || size != int64(len("archive")) {
+		fmt.Println("Unexpected purge preview", files, size, err)
+		t.Fail()
+	}
+
+	SetPurgeDryRun(true)
+
+	removed, err := PurgeNow()
+
+	if err != nil || len(removed) != 1 || !fileExists("2000-01-01-0-application.log", t) {
+		fmt.Println("Files removed in dry run mode", removed, err)
+		t.Fail()
+	}
+
+	SetPurgeDryRun(false)
+
+	removed, err = PurgeNow()
+
+	if err != nil || len(removed) != 1 || fileExists("2000-01-01-0-application.log", t) {
+		fmt.Println("Files not removed by PurgeNow", removed, err)
+		t.Fail()
+	}
+}
+
+type fakeFile struct {
+	name    string
+	modTime time.Time
+}
+
+func (f fakeFile) Name() string       { return f.name }
+func (f fakeFile) Size() int64        { return 0 }
+func (f fakeFile) Mode() os.FileMode  { return 0644 }
+func (f fakeFile) ModTime() time.Time { return f.modTime }
+func (f fakeFile) IsDir() bool        { return false }
+func (f fakeFile) Sys() interface{}   { return nil }
+
+func TestRetention(t *testing.T) {
+
+	now := time.Date(2017, 8, 18, 12, 0, 0, 0, time.Local)
+
+	// Oldest first, as listed by channel.archives
+	archives := []os.FileInfo{
+		fakeFile{"old", now.AddDate(-1, 0, 0)},
+		fakeFile{"month-1", now.AddDate(0, -1, -1)},
+		fakeFile{"month-1-newer", now.AddDate(0, -1, 0)},
+		fakeFile{"day-3", now.AddDate(0, 0, -3).Add(-1 * time.Hour)},
+		fakeFile{"day-3-newer", now.AddDate(0, 0, -3)},
+		fakeFile{"day-1", now.AddDate(0, 0, -1).Add(-1 * time.Hour)},
+		fakeFile{"day-1-newer", now.AddDate(0, 0, -1)},
+	}
+
+	retention := Retention{All: 2, Daily: 5, Monthly: 2}
+
+	var expired []string
+	for _, f := range retention.expired(archives, now) {
+		expired = append(expired, f.Name())
+	}
+
+	if fmt.Sprint(expired) != "[day-3 month-1 old]" {
+		fmt.Println("Unexpected expired archives", expired)
+		t.Fail()
+	}
+}
+
+func TestPurgeInterval(t *testing.T) {
+
+	defer SetPurgeInterval(time.Minute, 0)
+
+	SetPurgeInterval(2*time.Minute, -time.Second)
+	SetPurgeInterval(0, 0)
+	SetPurgeInterval(-time.Minute, 0)
+
+	if purgeInterval != 2*time.Minute || purgeJitter != 0 {
+		fmt.Println("Unexpected purge interval", purgeInterval, purgeJitter)
+		t.Fail()
+	}
+}
@@ -0,0 +1,85 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the channels of Raw, in addition to the file names of the routes (see SetRoutes).
+const (
+	AppLog    = "app"
+	PublicLog = "public"
+	ErrorLog  = "error"
+)
+
+// Writes a line already formatted by the caller, e.g. relayed from another system, as is in the
+// channel: no timestamp, level, caller or fields are added. The line still goes through the
+// rotation of the channel, and through stdout, the sinks and the subscribers (as an INFO entry)
+// for the app log. Raw app log lines are not filtered by the app log level.
+func Raw(channel string, line string) error {
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
+	if !running {
+		return nil
+	}
+
+	line = strings.TrimSuffix(line, "\n") + "\n"
+
+	switch channel {
+	case AppLog:
+		e := &Entry{Time: time.Now(), Level: INFO, Message: strings.TrimSuffix(line, "\n"), text: line, toFile: true}
+		if isSynchronous() {
+			return doAppLogWrite(e)
+		}
+		appLogChan <- e
+	case PublicLog:
+		if isSynchronous() {
+			return doPublicAccessLogWrite(line)
+		}
+		publicLogChan <- line
+	case ErrorLog:
+		if atomic.LoadInt32(&errorLogActive) == 0 {
+			return errors.New("gol error log not enabled")
+		}
+		errorChannel.write([]byte(line))
+	default:
+		routeLock.RLock()
+		c := routeChannels[channel]
+		routeLock.RUnlock()
+
+		if c == nil {
+			return errors.New("unknown gol channel [" + channel + "]")
+		}
+		c.write([]byte(line))
+	}
+
+	return nil
+}
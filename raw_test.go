@@ -0,0 +1,78 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetRoutes(Route{Field: "subsystem", Value: "relay", File: "relay.log"})
+	defer SetRoutes()
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	entries, unsubscribe := Subscribe(nil)
+	defer unsubscribe()
+
+	Raw(AppLog, "<14>1 2017-03-01T10:00:00Z host relayed app line\n")
+	Raw(PublicLog, "10.0.0.1 - - relayed access line")
+	Raw("relay.log", "relayed routed line")
+
+	if Raw("nowhere.log", "lost line") == nil || Raw(ErrorLog, "lost line") == nil {
+		fmt.Println("Raw line accepted by an unknown channel")
+		t.Fail()
+	}
+
+	if e := <-entries; e.Message != "<14>1 2017-03-01T10:00:00Z host relayed app line" {
+		fmt.Println("Unexpected published entry", e.Message)
+		t.Fail()
+	}
+
+	Stop()
+
+	if readFile("./application.log", t) != "<14>1 2017-03-01T10:00:00Z host relayed app line\n" {
+		fmt.Println("Unexpected app log", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if readFile("./access.log", t) != "10.0.0.1 - - relayed access line\n" || readFile("./relay.log", t) != "relayed routed line\n" {
+		fmt.Println("Missing raw lines")
+		t.Fail()
+	}
+}
@@ -0,0 +1,500 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reads the lines of the archives, oldest first, then of the current file of a log.
+type lineReader struct {
+	paths   []string
+	file    *os.File
+	dec     io.ReadCloser // Decompresses the archive, see Codec
+	scanner *bufio.Scanner
+}
+
+// Returns the paths of the archives (oldest first, compressed or not) then of the current file of the log name.
+func logFiles(folder string, name string) ([]string, error) {
+
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var extensions []string
+	for _, extension := range codecExtensions() {
+		extensions = append(extensions, regexp.QuoteMeta(extension))
+	}
+
+	archivePattern := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(\d+)-` + regexp.QuoteMeta(name) + `(?:\.part(\d+))?(` + strings.Join(extensions, "|") + `)?$`)
+
+	type archive struct {
+		path   string
+		date   string
+		number int
+		part   int // Chunk of a compressed archive, see SetArchiveChunkSize
+	}
+
+	var archives []archive
+	current := ""
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if f.Name() == name {
+			current = folder + "/" + name
+		} else if m := archivePattern.FindStringSubmatch(f.Name()); m != nil {
+			number, _ := strconv.Atoi(m[2])
+			part, _ := strconv.Atoi(m[3])
+			archives = append(archives, archive{path: folder + "/" + f.Name(), date: m[1], number: number, part: part})
+		}
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		if archives[i].date != archives[j].date {
+			return archives[i].date < archives[j].date
+		}
+		if archives[i].number != archives[j].number {
+			return archives[i].number < archives[j].number
+		}
+		return archives[i].part < archives[j].part
+	})
+
+	var paths []string
+	for _, a := range archives {
+		paths = append(paths, a.path)
+	}
+
+	if current != "" {
+		paths = append(paths, current)
+	}
+
+	return paths, nil
+}
+
+func newLineReader(r io.Reader) *lineReader {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	return &lineReader{scanner: scanner}
+}
+
+// Returns the next line, or io.EOF after the last one.
+func (r *lineReader) next() (string, error) {
+
+	for {
+		if r.scanner != nil {
+			if r.scanner.Scan() {
+				line := r.scanner.Text()
+				if line != "" && strings.Trim(line, "\x00") == "" {
+					continue // Preallocated end of a memory mapped file
+				}
+				if strings.HasPrefix(line, schemaHeaderPrefix) {
+					continue
+				}
+				return line, nil
+			}
+			err := r.scanner.Err()
+			r.scanner = nil
+			if err != nil {
+				r.closeFile()
+				return "", err // The next call reads the following file
+			}
+		}
+
+		r.closeFile()
+
+		if len(r.paths) == 0 {
+			return "", io.EOF
+		}
+
+		path := r.paths[0]
+		r.paths = r.paths[1:]
+
+		if err := r.openFile(path); err != nil {
+			return "", err // The next call reads the following file
+		}
+	}
+}
+
+func (r *lineReader) openFile(path string) (err error) {
+
+	r.file, err = os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r.dec, err = openArchive(r.file)
+	if err != nil {
+		r.closeFile()
+		return err
+	}
+
+	r.scanner = newLineReader(r.dec).scanner
+
+	return nil
+}
+
+func (r *lineReader) closeFile() (err error) {
+
+	if r.dec != nil {
+		r.dec.Close()
+		r.dec = nil
+	}
+
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+
+	return err
+}
+
+// AccessReader reads the entries of a public access log.
+type AccessReader struct {
+	lines *lineReader
+}
+
+// Returns a reader of the entries of the public access log name in the folder, through
+// its archives (oldest first, compressed or not) then its current file.
+func OpenAccessReader(folder string, name string) (*AccessReader, error) {
+
+	paths, err := logFiles(folder, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessReader{lines: &lineReader{paths: paths}}, nil
+}
+
+// Returns a reader of the public access log entries read from r.
+func NewAccessReader(r io.Reader) *AccessReader {
+	return &AccessReader{lines: newLineReader(r)}
+}
+
+// Returns the next entry, or io.EOF after the last one. An invalid line returns an
+// error, the following entries can still be read.
+func (r *AccessReader) Next() (AccessEntry, error) {
+
+	for {
+		line, err := r.lines.next()
+		if err != nil {
+			return AccessEntry{}, err
+		}
+
+		if strings.TrimSpace(line) != "" {
+			return ParseAccessLine(line)
+		}
+	}
+}
+
+func (r *AccessReader) Close() error {
+	return r.lines.closeFile()
+}
+
+// ReaderOptions filters the entries returned by a LogReader.
+type ReaderOptions struct {
+	MinLevel int       // Lowest level of the entries returned (default DEBUG)
+	Since    time.Time // Entries logged before are skipped, zero for no lower bound
+	Until    time.Time // Entries logged after are skipped, zero for no upper bound
+}
+
+// LogReader reads the entries of an app log.
+type LogReader struct {
+	lines   *lineReader
+	opts    ReaderOptions
+	pending string // First line of the next entry
+	err     error  // Read error, returned once the entry read before it is
+}
+
+var logLinePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) (\S+) \[`)
+var callerPattern = regexp.MustCompile(` at (\S+):(\d+)(?: in (\S+))?$`)
+
+// Returns a reader of the entries of the app log name in the folder, in time order through its
+// archives (compressed or not) then its current file, filtered by level and time range.
+func OpenLogReader(folder string, name string, opts ReaderOptions) (*LogReader, error) {
+
+	paths, err := logFiles(folder, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Since.IsZero() {
+		// Files last modified before the time range only contain older entries
+		var recent []string
+		for _, path := range paths {
+			if fileInfo, err := os.Stat(path); err != nil || !fileInfo.ModTime().Before(opts.Since) {
+				recent = append(recent, path)
+			}
+		}
+		paths = recent
+	}
+
+	return &LogReader{lines: &lineReader{paths: paths}, opts: opts}, nil
+}
+
+// Returns a reader of the app log entries read from r, filtered by level and time range.
+func NewLogReader(r io.Reader, opts ReaderOptions) *LogReader {
+	return &LogReader{lines: newLineReader(r), opts: opts}
+}
+
+// Returns the next entry matching the filters, or io.EOF after the last one. Lines which
+// don't start an entry are part of the message of the previous one.
+func (r *LogReader) Next() (Entry, error) {
+
+	for {
+		text, err := r.nextEntryText()
+		if err != nil {
+			return Entry{}, err
+		}
+
+		e, err := ParseLogLine(text)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		if !atLeast(e.Level, r.opts.MinLevel) {
+			continue
+		}
+
+		if !r.opts.Since.IsZero() && e.Time.Before(r.opts.Since.Truncate(time.Second)) {
+			continue
+		}
+
+		if !r.opts.Until.IsZero() && e.Time.After(r.opts.Until) {
+			continue
+		}
+
+		return e, nil
+	}
+}
+
+func (r *LogReader) Close() error {
+	return r.lines.closeFile()
+}
+
+// Returns the lines of the next entry.
+func (r *LogReader) nextEntryText() (string, error) {
+
+	if err := r.err; err != nil && r.pending == "" {
+		r.err = nil
+		return "", err
+	}
+
+	text := r.pending
+	r.pending = ""
+
+	for {
+		line, err := r.lines.next()
+
+		if err != nil && text != "" {
+			if err != io.EOF {
+				r.err = err
+			}
+			return text, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if logLinePattern.MatchString(line) {
+			if text != "" {
+				r.pending = line
+				return text, nil
+			}
+			text = line
+		} else if text != "" {
+			text += "\n" + line
+		} else if strings.TrimSpace(line) != "" {
+			return line, nil // Invalid line, reported by ParseLogLine
+		}
+	}
+}
+
+// Error of a line which isn't an app log entry, the following lines can still be read.
+type invalidLineError struct {
+	message string
+}
+
+func (e *invalidLineError) Error() string {
+	return e.message
+}
+
+func invalidLine(message string) error {
+	return &invalidLineError{message: message}
+}
+
+// Parses an app log entry. Field values are returned as strings, as formatted in the log.
+func ParseLogLine(s string) (Entry, error) {
+
+	s = strings.TrimRight(s, "\r\n")
+
+	m := logLinePattern.FindStringSubmatch(s)
+
+	if m == nil {
+		return Entry{}, invalidLine("invalid app log line [" + s + "]")
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	level := -1
+	for l, name := range levelNames() {
+		if name == m[2] {
+			level = l
+		}
+	}
+
+	if level == -1 {
+		return Entry{}, invalidLine("invalid level in app log line [" + s + "]")
+	}
+
+	e := Entry{Time: t, Level: level, text: s + "\n"}
+
+	rest := s[len(m[0]):]
+
+	if c := callerPattern.FindStringSubmatch(rest); c != nil {
+		e.File = c[1]
+		e.Line, _ = strconv.Atoi(c[2])
+		e.Function = c[3]
+		rest = rest[:len(rest)-len(c[0])]
+	}
+
+	// The message ends with the first "]" followed by valid fields
+	for i := strings.Index(rest, "]"); i >= 0; {
+		if fields, ok := parseFields(rest[i+1:]); ok {
+			e.Message = rest[:i]
+
+			// The sequence number follows the fields of the entry
+			if n := len(fields); n > 0 && fields[n-1].Key == "seq" {
+				if seq, err := strconv.ParseUint(fields[n-1].Value.(string), 10, 64); err == nil {
+					e.Sequence = seq
+					fields = fields[:n-1]
+				}
+			}
+
+			e.Fields = fields
+			return e, nil
+		}
+
+		next := strings.Index(rest[i+1:], "]")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return Entry{}, invalidLine("invalid message in app log line [" + s + "]")
+}
+
+// Parses space separated key=value fields, values being quoted, structured or plain.
+func parseFields(s string) (fields []Field, ok bool) {
+
+	for s != "" {
+		if s[0] != ' ' {
+			return nil, false
+		}
+		s = s[1:]
+
+		eq := strings.Index(s, "=")
+		if eq <= 0 || strings.ContainsAny(s[:eq], " \"") {
+			return nil, false
+		}
+
+		key := s[:eq]
+		s = s[eq+1:]
+
+		n := valueLength(s)
+		if n < 0 {
+			return nil, false
+		}
+
+		value := s[:n]
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, false
+			}
+			value = unquoted
+		}
+
+		fields = append(fields, Field{Key: key, Value: value})
+		s = s[n:]
+	}
+
+	return fields, true
+}
+
+// Returns the length of the field value starting s, -1 if invalid.
+func valueLength(s string) int {
+
+	depth := 0
+	quoted := false
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+			if !quoted && depth == 0 {
+				return i + 1
+			}
+		case quoted:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth < 0 {
+				return -1
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		case c == ' ' && depth == 0:
+			return i
+		}
+	}
+
+	if quoted || depth != 0 {
+		return -1
+	}
+
+	return len(s)
+}
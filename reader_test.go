@@ -0,0 +1,184 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+
+	e := decorateAppLogEntry(WARN, INFO, []Field{{Key: "user", Value: "a b"}}, []interface{}{"saving [draft]", Errors("errs", []error{errors.New("x] y")}), Slice("ids", []int{1, 2})}, 1)
+
+	parsed, err := ParseLogLine(e.String())
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	if parsed.Level != WARN || parsed.Message != "saving [draft]" || !strings.HasSuffix(parsed.File, "reader_test.go") || parsed.Line == 0 {
+		fmt.Printf("Unexpected entry %+v\n", parsed)
+		t.Fail()
+	}
+
+	if fmt.Sprint(parsed.Fields) != `[{user a b} {errs ["x] y"]} {ids [1,2]}]` {
+		fmt.Println("Unexpected fields", parsed.Fields)
+		t.Fail()
+	}
+}
+
+func TestLogReader(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1)
+	LogToStdout(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(DEBUG)
+	defer SetAppLogLevel(INFO)
+
+	for j := 0; j < 20; j++ {
+		Debug("debug", j)
+		Warn("warning", j)
+	}
+	Error("multi\nline")
+
+	Stop()
+
+	r, err := OpenLogReader(".", "application.log", ReaderOptions{MinLevel: WARN, Since: time.Now().Add(-1 * time.Minute)})
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	defer r.Close()
+
+	var messages []string
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		messages = append(messages, e.Message)
+	}
+
+	if len(messages) != 21 || strings.Contains(fmt.Sprint(messages), "debug") || !strings.Contains(fmt.Sprint(messages), "multi\nline") {
+		fmt.Println("Unexpected entries", messages)
+		t.Fail()
+	}
+
+	r = NewLogReader(strings.NewReader(readFile("./application.log", t)), ReaderOptions{Until: time.Now().Add(-1 * time.Minute)})
+
+	if _, err := r.Next(); err != io.EOF {
+		fmt.Println("Entry after the time range")
+		t.Fail()
+	}
+}
+
+// The reader goes on with the next file after a read error, e.g. a truncated archive.
+func TestLogReaderReadError(t *testing.T) {
+	removeLogFiles(".")
+	defer removeLogFiles(".")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for j := 0; j < 1000; j++ {
+		fmt.Fprintf(gz, "2020-01-01 10:00:00 INFO [archived %d]\n", j)
+	}
+	gz.Close()
+
+	ioutil.WriteFile("./2020-01-01-1-application.log.gz", buf.Bytes()[:buf.Len()/2], 0644)
+	ioutil.WriteFile("./application.log", []byte("2020-01-02 10:00:00 INFO [current]\n"), 0644)
+
+	r, err := OpenLogReader(".", "application.log", ReaderOptions{})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	var errs int
+	var last string
+
+	for i := 0; i < 10000; i++ {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs++
+			continue
+		}
+		last = e.Message
+	}
+
+	if errs == 0 || last != "current" {
+		fmt.Println("Unexpected read", errs, last)
+		t.Fail()
+	}
+}
+
+func TestShowFunctionNames(t *testing.T) {
+
+	ShowFunctionNames(true)
+	defer ShowFunctionNames(false)
+
+	e := decorateAppLogEntry(INFO, INFO, nil, []interface{}{"with function"}, 1)
+
+	if e.Function != "github.com/alexv99/gol.TestShowFunctionNames" || !strings.HasSuffix(e.String(), " in "+e.Function+"\n") {
+		fmt.Printf("Unexpected entry %+v\n", e)
+		t.FailNow()
+	}
+
+	parsed, err := ParseLogLine(e.String())
+
+	if err != nil || parsed.Function != e.Function || parsed.Line != e.Line || parsed.Message != "with function" {
+		fmt.Printf("Unexpected parsed entry %+v %v\n", parsed, err)
+		t.Fail()
+	}
+}
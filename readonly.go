@@ -0,0 +1,63 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+var readOnlyFallback int32 = 1
+var stdoutOnly int32 // 1 if the log folders are on a read-only filesystem, the entries are only printed to stdout
+
+// Makes Start fall back to printing the entries to stdout only, with a WARN entry, when a log folder is
+// on a read-only filesystem (e.g. a locked-down container), instead of failing. Enabled by default.
+func SetReadOnlyFallback(enabled bool) {
+	atomic.StoreInt32(&readOnlyFallback, toggle(enabled))
+}
+
+// Returns true if gol fell back to stdout only on Start, see SetReadOnlyFallback.
+func StdoutOnly() bool {
+	return isRunning() && onlyStdout()
+}
+
+// Returns true if Start falls back to stdout only on a read-only filesystem.
+func fallbackEnabled() bool {
+	return atomic.LoadInt32(&readOnlyFallback) == 1
+}
+
+// Returns true if gol fell back to stdout only.
+func onlyStdout() bool {
+	return atomic.LoadInt32(&stdoutOnly) == 1
+}
+
+func warnReadOnly(folders []string) {
+
+	fields := []Field{{Key: "folders", Value: strings.Join(folders, ",")}}
+
+	if e := decorateAppLogEntry(WARN, WARN, fields, []interface{}{"Log folders on a read-only filesystem, logging to stdout only"}, 2); e != nil {
+		doAppLogWrite(e)
+	}
+}
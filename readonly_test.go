@@ -0,0 +1,97 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build linux || darwin || freebsd || dragonfly
+// +build linux darwin freebsd dragonfly
+
+package gol
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestReadOnlyFallback(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+
+	defer SetSynchronous(false)
+
+	folderCheck = func(folder string) error {
+		return &os.PathError{Op: "mkdir", Path: folder, Err: syscall.EROFS}
+	}
+	defer func() { folderCheck = checkFolder }()
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	only := StdoutOnly()
+
+	Info("still visible")
+
+	Stop()
+
+	if !only || StdoutOnly() {
+		fmt.Println("Unexpected stdout only mode", only)
+		t.Fail()
+	}
+
+	if !strings.Contains(out.String(), "WARN [Log folders on a read-only filesystem, logging to stdout only] folders=.,.") ||
+		!strings.Contains(out.String(), "INFO [still visible]") {
+		fmt.Println("Entries not printed to stdout", out.String())
+		t.Fail()
+	}
+
+	if fileExists("./application.log", t) {
+		fmt.Println("File written on a read-only filesystem")
+		t.Fail()
+	}
+
+	SetReadOnlyFallback(false)
+	defer SetReadOnlyFallback(true)
+
+	err := Start()
+	if err == nil {
+		Stop()
+	}
+
+	if _, ok := err.(*ErrInvalidConfig); !ok {
+		fmt.Println("Start not failing without the fallback", err)
+		t.Fail()
+	}
+}
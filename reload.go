@@ -0,0 +1,159 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+var configPath string    // Configuration file reloaded by Reload, see WatchConfig
+var configStop chan bool // Stops the watcher of the configuration file
+var configLock = sync.Mutex{}
+
+// Watches the configuration file (see LoadConfig), polled every interval, and reloads it when it
+// changes, see Reload. An interval of 0 only sets the file reloaded by Reload. An empty path stops
+// watching.
+func WatchConfig(path string, interval time.Duration) {
+
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	if configStop != nil {
+		close(configStop)
+		configStop = nil
+	}
+
+	configPath = path
+
+	if path == "" || interval <= 0 {
+		return
+	}
+
+	last, _ := os.Stat(path)
+
+	configStop = make(chan bool)
+	go watchConfig(path, last, interval, configStop)
+}
+
+// Reloads the configuration file set with WatchConfig and applies the options which can change
+// at runtime: the levels, the max sizes, ages, quotas and retentions of the log files, the console,
+// line number, sampling, write budget, disk space and purge options. The other options (e.g. the
+// folders) and the environment variables are applied by the next Start only. The current
+// configuration is kept if the file is invalid.
+func Reload() error {
+
+	configLock.Lock()
+	path := configPath
+	configLock.Unlock()
+
+	if path == "" {
+		return errors.New("no configuration file to reload, see WatchConfig")
+	}
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	c.applyRuntime()
+
+	return nil
+}
+
+// Polls the configuration file every interval, reloading it when modified since last, until stopped.
+func watchConfig(path string, last os.FileInfo, interval time.Duration, stop chan bool) {
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Being replaced, e.g. by a config map update
+		}
+
+		if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			continue
+		}
+		last = info
+
+		if err := Reload(); err != nil {
+			reportError(err)
+		}
+	}
+}
+
+// Applies the options of the configuration which can change at runtime, see Reload.
+func (c Config) applyRuntime() {
+
+	level, _ := parseLevel(c.Level)
+	SetAppLogLevel(level)
+
+	c.App.resize(appChannel)
+	c.Public.resize(publicChannel)
+	c.Error.resize(errorChannel)
+
+	SetStdoutLogLevel(-1)
+	if c.Console.Level != "" {
+		level, _ := parseLevel(c.Console.Level)
+		SetStdoutLogLevel(level)
+	}
+	SetStdoutFormat(TextFormat)
+	SetStdoutFormat(c.Console.Format)
+	SetStdoutColors(c.Console.Colors)
+	SetShortLevels(c.Console.ShortLevels)
+
+	ShowLineNumbers(c.LineNumbers)
+	if len(c.LineNumberLevels) > 0 {
+		var levels []int
+		for _, name := range c.LineNumberLevels {
+			level, _ := parseLevel(name)
+			levels = append(levels, level)
+		}
+		ShowLineNumbersFor(levels...)
+	}
+	ShowFunctionNames(c.FunctionNames)
+
+	SetWriteBudget(c.WriteBudget)
+	SetPublicSampleRate(c.SampleRate)
+	SetMinFreeDiskSpace(c.MinFreeDisk)
+	SetPurgeInterval(c.PurgeInterval, c.PurgeJitter)
+}
+
+// Applies the max size, age, quota and retention to the channel, which may be running.
+func (c ChannelConfig) resize(ch *channel) {
+	ch.lock.Lock()
+	ch.maxSize = c.MaxSize
+	ch.maxAge = c.MaxAge
+	ch.quota = c.Quota
+	ch.retention = c.Retention
+	ch.lock.Unlock()
+}
@@ -0,0 +1,130 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReloadConfig(t *testing.T) {
+	removeLogFiles(".")
+
+	path := "./gol-reload.json"
+	defer os.Remove(path)
+
+	ioutil.WriteFile(path, []byte(`{"level": "INFO", "app": {"folder": ".", "max_size": 1024, "max_age": 10}, "public": {"folder": "."}, "console": {"enabled": false}}`), 0644)
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Start(c); err != nil {
+		t.Fatal(err)
+	}
+	defer SetAppLogLevel(INFO)
+	defer SetAppLogMaxSize(1024)
+
+	WatchConfig(path, 5*time.Millisecond)
+	defer WatchConfig("", 0)
+
+	ioutil.WriteFile(path, []byte(`{"level": "DEBUG", "app": {"folder": "./elsewhere", "max_size": 4096, "max_age": 10}, "public": {"folder": "."}, "console": {"enabled": false}}`), 0644)
+
+	for i := 0; i < 200 && appLogLevel() != DEBUG; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	appChannel.lock.RLock()
+	maxSize, folder := appChannel.maxSize, appChannel.folder
+	appChannel.lock.RUnlock()
+
+	if appLogLevel() != DEBUG || maxSize != 4096 || folder != "." {
+		fmt.Println("Configuration not reloaded", appLogLevel(), maxSize, folder)
+		t.Fail()
+	}
+
+	ioutil.WriteFile(path, []byte(`{"level": "LOUD"}`), 0644)
+
+	if err := Reload(); err == nil || appLogLevel() != DEBUG {
+		fmt.Println("Invalid configuration reloaded", err)
+		t.Fail()
+	}
+
+	Stop()
+}
+
+// Run with -race: the channels are resized while the purge routine lists their files.
+func TestResizeWhilePurging(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	defer SetAppLogMaxSize(1024)
+	defer SetAppLogMaxAge(10)
+	defer SetAppLogQuota(0)
+	defer SetAppLogRetention(Retention{})
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	done := make(chan struct{})
+	resizing := sync.WaitGroup{}
+
+	resizing.Add(1)
+	go func() {
+		defer resizing.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			c := ChannelConfig{MaxSize: int64(1024 + i%2), MaxAge: 10 + i%2, Quota: int64(i % 2 * 4096)}
+			if i%3 == 0 {
+				c.Retention = Retention{Daily: 7}
+			}
+			c.resize(appChannel)
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		if _, err := purgeCandidates(logChannels()); err != nil {
+			fmt.Println(err)
+			t.Fail()
+		}
+	}
+
+	close(done)
+	resizing.Wait()
+}
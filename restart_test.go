@@ -0,0 +1,157 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestart(t *testing.T) {
+	removeLogFiles(".")
+	os.RemoveAll("./restart")
+	defer os.RemoveAll("./restart")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	SetMmapWrites(true)
+	SetSequenceCheckpoints(time.Hour)
+
+	defer SetAppLogFolder(".")
+	defer SetPublicLogFolder(".")
+	defer SetSynchronous(false)
+	defer SetMmapWrites(false)
+	defer SetSequenceCheckpoints(0)
+	defer removeLogFiles(".")
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+	Info("first run")
+	Stop()
+	Stop() // No-op once stopped
+
+	goroutines := runtime.NumGoroutine()
+
+	os.MkdirAll("./restart", 0755)
+	SetAppLogFolder("./restart")
+	SetMmapWrites(false)
+
+	for i := 0; i < 3; i++ {
+		if err := Start(); err != nil {
+			t.Fatal(err)
+		}
+		Info(fmt.Sprint("run ", i))
+		Stop()
+	}
+
+	if n := runtime.NumGoroutine(); n > goroutines {
+		fmt.Println("Routines left after Stop", goroutines, n)
+		t.Fail()
+	}
+
+	if !fileContains("./application.log", "first run", t) || fileContains("./application.log", "run 2", t) {
+		fmt.Println("Unexpected entries in the first folder")
+		t.Fail()
+	}
+
+	for i := 0; i < 3; i++ {
+		if !fileContains("./restart/application.log", fmt.Sprint("run ", i), t) {
+			fmt.Println("Entry not written after restart", i)
+			t.Fail()
+		}
+	}
+
+	if appChannel.file != nil || appChannel.mm != nil || appChannel.suffix != 0 {
+		fmt.Println("Channel not torn down")
+		t.Fail()
+	}
+}
+
+// Run with -race: the entries logged while stopping are either written or dropped.
+func TestLogWhileRestarting(t *testing.T) {
+	removeLogFiles(".")
+	defer removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	// Shedding changes are logged by the log calls while Stop waits
+	SetLevelShedding(true)
+	defer SetLevelShedding(false)
+
+	done := make(chan struct{})
+	loggers := sync.WaitGroup{}
+
+	for i := 0; i < 4; i++ {
+		loggers.Add(1)
+		go func(i int) {
+			defer loggers.Done()
+
+			req, _ := http.NewRequest("GET", "http://www.deal.com/restart", nil)
+
+			for j := 0; ; j++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				Info("logger", i, j)
+				Priority(WARN, "priority", i, j)
+				Raw(AppLog, "raw")
+				Public(*req, 200, 10, time.Millisecond)
+
+				tx := Begin()
+				tx.Info("grouped")
+				tx.Commit()
+			}
+		}(i)
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := Start(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+		Stop()
+	}
+
+	close(done)
+	loggers.Wait()
+
+	if !fileContains("./application.log", "logger", t) || !fileContains("./access.log", "/restart", t) {
+		fmt.Println("Entries not written while restarting")
+		t.Fail()
+	}
+}
@@ -0,0 +1,111 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"os"
+	"time"
+)
+
+var dailyRotation = false
+var rotationLocation = time.Local
+var rotationHour = 0
+
+// Rotates the log files every day at the hour (0 to 23) in the location, in addition to the size
+// rotation, e.g. (true, time.UTC, 0) for UTC midnight boundaries or (true, time.Local, 3) to rotate
+// at 03:00 local time. The archives are named with the date of the day they cover in the location.
+// A file left by a previous run of an earlier day is rotated by the first write. The next rotation is
+// timed with the monotonic clock, so that NTP steps don't rotate the files twice. Applied by the next Start.
+func SetDailyRotation(enabled bool, location *time.Location, hour int) {
+
+	if location == nil {
+		location = time.Local
+	}
+
+	dailyRotation = enabled
+	rotationLocation = location
+	rotationHour = hour
+}
+
+// Returns the start of the daily rotation period containing t.
+func periodStart(t time.Time) time.Time {
+
+	t = t.In(rotationLocation)
+	start := time.Date(t.Year(), t.Month(), t.Day(), rotationHour, 0, 0, 0, rotationLocation)
+
+	if start.After(t) {
+		start = time.Date(t.Year(), t.Month(), t.Day()-1, rotationHour, 0, 0, 0, rotationLocation)
+	}
+
+	return start
+}
+
+// Returns the date of the archives of the entries written at t.
+func archiveDate(t time.Time) string {
+
+	if !dailyRotation {
+		return t.Local().Format("2006-01-02")
+	}
+
+	return periodStart(t).Format("2006-01-02")
+}
+
+// Sets the next daily rotation of the current file, from its last write. The channel must be locked.
+func (c *channel) scheduleRotation() {
+
+	c.boundary = time.Time{}
+
+	if !dailyRotation || c.file == nil {
+		return
+	}
+
+	from := time.Now()
+
+	if info, err := os.Stat(c.file.Name()); err == nil && info.Size() > 0 && info.ModTime().Before(from) {
+		from = info.ModTime()
+	}
+
+	start := periodStart(from)
+	boundary := time.Date(start.Year(), start.Month(), start.Day()+1, rotationHour, 0, 0, 0, rotationLocation)
+
+	// Deadline with a monotonic clock reading, so that a clock step doesn't rotate twice
+	now := time.Now()
+	c.boundary = now.Add(boundary.Sub(now))
+}
+
+// Returns true if the current file reached its daily rotation. The channel must be locked.
+func (c *channel) rotationDue() bool {
+	return !c.boundary.IsZero() && !time.Now().Before(c.boundary)
+}
+
+// Returns the date of the archive of the current file. The channel must be locked.
+func (c *channel) archiveDate() string {
+
+	if c.rotationDue() {
+		return archiveDate(c.boundary.Add(-time.Nanosecond))
+	}
+
+	return archiveDate(time.Now())
+}
@@ -0,0 +1,131 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+
+	SetDailyRotation(true, time.FixedZone("UTC+2", 2*3600), 3)
+	defer SetDailyRotation(false, nil, 0)
+
+	// 02:30 UTC is 04:30 UTC+2, after the 03:00 boundary
+	if d := archiveDate(time.Date(2017, 3, 2, 2, 30, 0, 0, time.UTC)); d != "2017-03-02" {
+		fmt.Println("Unexpected archive date", d)
+		t.Fail()
+	}
+
+	// 00:30 UTC is 02:30 UTC+2, before the 03:00 boundary
+	if d := archiveDate(time.Date(2017, 3, 1, 0, 30, 0, 0, time.UTC)); d != "2017-02-28" {
+		fmt.Println("Unexpected archive date", d)
+		t.Fail()
+	}
+}
+
+func TestDailyRotation(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetDailyRotation(true, time.UTC, 0)
+	defer SetDailyRotation(false, nil, 0)
+
+	// File left by a run two days ago
+	old := time.Now().UTC().AddDate(0, 0, -2)
+	ioutil.WriteFile("./application.log", []byte("old entry\n"), 0644)
+	os.Chtimes("./application.log", old, old)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("new entry")
+
+	Stop()
+
+	archive := "./" + old.Format("2006-01-02") + "-0-application.log"
+
+	if readFile(archive, t) != "old entry\n" || fileContains("./application.log", "old entry", t) ||
+		!fileContains("./application.log", "new entry", t) {
+		fmt.Println("File of a previous day not rotated")
+		t.Fail()
+	}
+}
+
+func TestMonotonicTiming(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	SetDailyRotation(true, time.UTC, 0)
+	defer SetDailyRotation(false, nil, 0)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	// The deadline keeps a monotonic clock reading, stripped by Round(0)
+	appChannel.lock.RLock()
+	boundary := appChannel.boundary
+	appChannel.lock.RUnlock()
+
+	if boundary.Round(0) == boundary || time.Until(boundary) > 24*time.Hour {
+		fmt.Println("Unexpected rotation deadline", boundary)
+		t.Fail()
+	}
+
+	// Duration measured across a clock step back
+	req, _ := http.NewRequest("GET", "http://www.deal.com/step", nil)
+	Public(*req, 200, 10, -3*time.Second)
+
+	Stop()
+
+	if !fileContains("./access.log", " in 0ns => 200", t) || Stats().Latency.Max < 0 {
+		fmt.Println("Negative duration logged", readFile("./access.log", t))
+		t.Fail()
+	}
+}
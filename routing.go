@@ -0,0 +1,193 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Route sends the app log entries matching a package or a field to another file of the
+// app log folder, e.g. Route{Field: "subsystem", Value: "db", File: "db.log"}.
+// Routed entries are not written in the app log file.
+type Route struct {
+	Package string // Entries logged from the package (directory suffix, e.g. "myapp/db"), needs line numbers
+	Field   string // Entries with the field, with the value if not empty
+	Value   string
+	File    string // Name of the log file, e.g. "db.log"
+}
+
+var routes []Route
+var routeChannels = map[string]*channel{}
+var routeLock = sync.RWMutex{}
+
+// Replaces the routing rules. Entries go to the file of the first matching route.
+// The files of the routes are opened right away if gol is running, and the files of the
+// routes dropped are closed.
+func SetRoutes(rules ...Route) error {
+
+	routeLock.Lock()
+	defer routeLock.Unlock()
+
+	reserved := map[string]bool{}
+	for _, c := range []*channel{appChannel, publicChannel, errorChannel, personalChannel} {
+		c.lock.RLock()
+		reserved[c.name] = true
+		c.lock.RUnlock()
+	}
+
+	kept := map[string]bool{}
+
+	for _, r := range rules {
+		if r.File == "" || strings.ContainsAny(r.File, "/\\") {
+			return fmt.Errorf("invalid route file name [%s]", r.File)
+		}
+		if reserved[r.File] {
+			return fmt.Errorf("route file name [%s] already used by another log", r.File)
+		}
+		if r.Package == "" && r.Field == "" {
+			return fmt.Errorf("route to [%s] has neither a package nor a field", r.File)
+		}
+		kept[r.File] = true
+	}
+
+	appChannel.lock.RLock()
+	folder, maxSize, maxAge := appChannel.folder, appChannel.maxSize, appChannel.maxAge
+	appChannel.lock.RUnlock()
+
+	for _, r := range rules {
+		c, ok := routeChannels[r.File]
+		if !ok {
+			c = &channel{folder: folder, name: r.File, maxSize: maxSize, maxAge: maxAge}
+			routeChannels[r.File] = c
+		}
+		if isRunning() && !c.isOpen() {
+			c.update(func() { c.folder = folder })
+			if err := c.open(); err != nil {
+				return err
+			}
+		}
+	}
+
+	routes = rules
+
+	for file, c := range routeChannels {
+		if !kept[file] {
+			if err := c.close(); err != nil {
+				reportError(err)
+			}
+			delete(routeChannels, file)
+		}
+	}
+
+	return nil
+}
+
+// Opens the files of the routes, in the app log folder.
+func openRoutes() error {
+
+	routeLock.Lock()
+	defer routeLock.Unlock()
+
+	appChannel.lock.RLock()
+	folder, maxSize, maxAge := appChannel.folder, appChannel.maxSize, appChannel.maxAge
+	appChannel.lock.RUnlock()
+
+	for _, r := range routes {
+		c := routeChannels[r.File]
+		c.update(func() {
+			c.folder, c.maxSize, c.maxAge = folder, maxSize, maxAge
+		})
+		if err := c.open(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the channels of the routes in use.
+func routedChannels() []*channel {
+
+	routeLock.RLock()
+	defer routeLock.RUnlock()
+
+	var channels []*channel
+	seen := map[string]bool{}
+
+	for _, r := range routes {
+		if !seen[r.File] {
+			seen[r.File] = true
+			channels = append(channels, routeChannels[r.File])
+		}
+	}
+
+	return channels
+}
+
+// Returns the channel of the first route matching the entry, nil if none.
+func routeFor(e *Entry) *channel {
+
+	routeLock.RLock()
+	defer routeLock.RUnlock()
+
+	for _, r := range routes {
+		if r.matches(e) {
+			return routeChannels[r.File]
+		}
+	}
+
+	return nil
+}
+
+func (r Route) matches(e *Entry) bool {
+
+	if r.Package != "" {
+		if e.File == "" {
+			return false
+		}
+		dir := filepath.ToSlash(filepath.Dir(e.File))
+		if dir != r.Package && !strings.HasSuffix(dir, "/"+r.Package) {
+			return false
+		}
+	}
+
+	if r.Field != "" {
+		found := false
+		for _, f := range e.Fields {
+			if f.Key == r.Field && (r.Value == "" || fmt.Sprint(f.Value) == r.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
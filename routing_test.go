@@ -0,0 +1,100 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	ShowLineNumbers(true)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := SetRoutes(
+		Route{Field: "subsystem", Value: "db", File: "db.log"},
+		Route{Package: "module", File: "module.log"},
+	)
+	defer SetRoutes()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	if SetRoutes(Route{File: "empty.log"}) == nil || SetRoutes(Route{Field: "a", File: "../a.log"}) == nil ||
+		SetRoutes(Route{Field: "a", File: "access.log"}) == nil {
+		fmt.Println("Invalid routes accepted")
+		t.Fail()
+	}
+
+	err = Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	Info("query done", Field{Key: "subsystem", Value: "db"})
+	Info("from the module package")
+
+	routeLock.RLock()
+	module := routeChannels["module.log"]
+	routeLock.RUnlock()
+
+	SetRoutes(Route{Field: "subsystem", Value: "db", File: "db.log"})
+
+	if module.isOpen() || len(routedChannels()) != 1 {
+		fmt.Println("Dropped route not closed")
+		t.Fail()
+	}
+
+	Info("not routed")
+
+	Stop()
+
+	if !fileContains("./db.log", "query done", t) || !fileContains("./module.log", "from the module package", t) {
+		fmt.Println("Missing routed entries")
+		t.Fail()
+	}
+
+	if !fileContains("./application.log", "not routed", t) {
+		fmt.Println("Missing entry in the app log")
+		t.Fail()
+	}
+
+	if content := readFile("./application.log", t); strings.Contains(content, "query done") || strings.Contains(content, "from the module package") {
+		fmt.Println("Routed entries in the app log", content)
+		t.Fail()
+	}
+}
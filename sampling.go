@@ -0,0 +1,142 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SampleRule defines the fraction of public access log entries kept for the
+// requests matching Path and the status code range. The first matching rule wins.
+type SampleRule struct {
+	Path      string  // path.Match pattern, a trailing "*" matches any suffix (e.g. "/static/*"), empty matches all
+	MinStatus int     // Lowest matching status code (inclusive), 0 for no lower bound
+	MaxStatus int     // Highest matching status code (inclusive), 0 for no upper bound
+	Rate      float64 // Fraction of matching entries kept, from 0 (none) to 1 (all)
+}
+
+var pSampleRate float64 = 1 // Fraction of entries kept when no rule matches
+var pSampleRules []SampleRule
+var sampleLock = sync.RWMutex{}
+
+// Sets the fraction (0 to 1) of public access log entries kept when no sample rule matches.
+func SetPublicSampleRate(rate float64) {
+	sampleLock.Lock()
+	pSampleRate = clampRate(rate)
+	sampleLock.Unlock()
+}
+
+// Adds a sampling rule for the public access log. Rules are evaluated in the order they were added.
+func AddPublicSampleRule(rule SampleRule) {
+	rule.Rate = clampRate(rule.Rate)
+	sampleLock.Lock()
+	pSampleRules = append(pSampleRules, rule)
+	sampleLock.Unlock()
+}
+
+// Removes all the public access log sampling rules.
+func ClearPublicSampleRules() {
+	sampleLock.Lock()
+	pSampleRules = nil
+	sampleLock.Unlock()
+}
+
+func (rule SampleRule) matches(urlPath string, status int) bool {
+
+	if rule.MinStatus > 0 && status < rule.MinStatus {
+		return false
+	}
+
+	if rule.MaxStatus > 0 && status > rule.MaxStatus {
+		return false
+	}
+
+	if rule.Path == "" {
+		return true
+	}
+
+	if strings.HasSuffix(rule.Path, "*") && strings.HasPrefix(urlPath, strings.TrimSuffix(rule.Path, "*")) {
+		return true
+	}
+
+	matched, _ := path.Match(rule.Path, urlPath)
+
+	return matched
+}
+
+func publicSampleRate(r *http.Request, status int) float64 {
+
+	urlPath := ""
+	if r.URL != nil {
+		urlPath = r.URL.Path
+	}
+
+	sampleLock.RLock()
+	defer sampleLock.RUnlock()
+
+	for _, rule := range pSampleRules {
+		if rule.matches(urlPath, status) {
+			return rule.Rate
+		}
+	}
+
+	return pSampleRate
+}
+
+// Returns true if an entry sampled at the given rate must be kept.
+func keepSample(rate float64) bool {
+
+	if rate >= 1 {
+		return true
+	}
+
+	if rate <= 0 {
+		return false
+	}
+
+	return rand.Float64() < rate
+}
+
+func formatSampleRate(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'g', -1, 64) + "%"
+}
+
+func clampRate(rate float64) float64 {
+
+	if rate < 0 {
+		return 0
+	}
+
+	if rate > 1 {
+		return 1
+	}
+
+	return rate
+}
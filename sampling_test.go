@@ -0,0 +1,104 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPublicLogSampling(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetPublicLogMaxSize(1024)
+	LogToStdout(false)
+
+	SetPublicSampleRate(0)
+	AddPublicSampleRule(SampleRule{MinStatus: 500, Rate: 1})
+	AddPublicSampleRule(SampleRule{Path: "/static/*", Rate: 0.5})
+	defer SetPublicSampleRate(1)
+	defer ClearPublicSampleRules()
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	dropped, _ := http.NewRequest("GET", "http://www.deal.com/dropped", nil)
+	Public(*dropped, 200, 10, 1*time.Millisecond)
+
+	failed, _ := http.NewRequest("GET", "http://www.deal.com/failed", nil)
+	Public(*failed, 503, 10, 1*time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		static, _ := http.NewRequest("GET", "http://www.deal.com/static/img/logo.png", nil)
+		Public(*static, 200, 10, 1*time.Millisecond)
+	}
+
+	path := "./access.log"
+
+	if !fileContains(path, "/failed", t) {
+		fmt.Println("Missing error entry from sampled public access log")
+		t.FailNow()
+	}
+
+	if !fileContains(path, "/static/img/logo.png", t) || !fileContains(path, "sampled at 50%", t) {
+		fmt.Println("Missing sampled entry from public access log")
+		t.FailNow()
+	}
+
+	if fileContains(path, "/dropped", t) {
+		fmt.Println("Unexpected entry in sampled public access log")
+		t.FailNow()
+	}
+}
+
+func TestSampleRuleMatches(t *testing.T) {
+
+	rule := SampleRule{Path: "/static/*", MaxStatus: 399}
+
+	if !rule.matches("/static/css/main.css", 200) {
+		t.Fail()
+	}
+
+	if rule.matches("/static/css/main.css", 404) {
+		t.Fail()
+	}
+
+	if rule.matches("/api/users", 200) {
+		t.Fail()
+	}
+
+	if !(SampleRule{Path: "/users/*/avatar"}).matches("/users/12/avatar", 200) {
+		t.Fail()
+	}
+}
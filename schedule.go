@@ -0,0 +1,79 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var levelTimer *time.Timer // Reverts a temporary level change
+var levelTimerLock = sync.Mutex{}
+var revertLevel int
+
+// Temporarily sets the app log level for the duration, then reverts it to the level set before.
+// Calling it again extends or replaces the temporary level, SetAppLogLevel cancels it.
+func SetLevelFor(level int, d time.Duration) {
+
+	if !checkLevel(level) {
+		return
+	}
+
+	levelTimerLock.Lock()
+	defer levelTimerLock.Unlock()
+
+	if levelTimer != nil {
+		levelTimer.Stop()
+	} else {
+		revertLevel = appLogLevel()
+	}
+
+	atomic.StoreInt32(&aLoglevel, int32(level))
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		levelTimerLock.Lock()
+		defer levelTimerLock.Unlock()
+
+		if levelTimer == timer { // Not replaced or cancelled meanwhile
+			atomic.StoreInt32(&aLoglevel, int32(revertLevel))
+			levelTimer = nil
+		}
+	})
+	levelTimer = timer
+}
+
+// Cancels the scheduled revert of a temporary level change.
+func cancelLevelFor() {
+
+	levelTimerLock.Lock()
+	defer levelTimerLock.Unlock()
+
+	if levelTimer != nil {
+		levelTimer.Stop()
+		levelTimer = nil
+	}
+}
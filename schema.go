@@ -0,0 +1,71 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Version of the formats of the app log and public access log files. It is incremented when a
+// change may break a parser of the files, see the Log schema section of the readme.
+const SchemaVersion = 1
+
+const schemaHeaderPrefix = "# gol schema="
+
+var schemaHeaders int32 // 1 to write the schema headers
+
+// Writes a header line with the schema version and the format of the file at the start of each
+// new log file, e.g. "# gol schema=1 format=app", so that the parsers can branch on the version.
+// The header lines are skipped by the gol readers.
+func SetSchemaHeaders(enabled bool) {
+	atomic.StoreInt32(&schemaHeaders, toggle(enabled))
+}
+
+// Writes the schema header if the current file is empty. The channel must be locked.
+func (c *channel) writeHeader() {
+
+	if atomic.LoadInt32(&schemaHeaders) == 0 || c.file == nil {
+		return
+	}
+
+	format := c.format
+	if format == "" {
+		format = "app"
+	}
+
+	header := []byte(schemaHeaderPrefix + strconv.Itoa(SchemaVersion) + " format=" + format + "\n")
+
+	if c.mm != nil {
+		if c.mm.size() == 0 {
+			c.mm.Write(header)
+		}
+		return
+	}
+
+	if info, err := c.file.Stat(); err == nil && info.Size() == 0 {
+		c.file.Write(header)
+	}
+}
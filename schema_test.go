@@ -0,0 +1,106 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSchemaHeaders(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1)
+	LogToStdout(false)
+	SetSchemaHeaders(true)
+	defer SetSchemaHeaders(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	for j := 0; j < 50; j++ {
+		Info("entry", j)
+	}
+	Raw(PublicLog, "GET / HTTP/1.1")
+
+	Stop()
+
+	files, _ := ioutil.ReadDir(".")
+	headers := 0
+
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), "application.log") {
+			if !strings.HasPrefix(readFile("./"+f.Name(), t), "# gol schema=1 format=app\n") {
+				fmt.Println("Missing schema header in", f.Name())
+				t.Fail()
+			}
+			headers++
+		}
+	}
+
+	if headers < 2 || !strings.HasPrefix(readFile("./access.log", t), "# gol schema=1 format=access\n") {
+		fmt.Println("Missing schema headers", headers)
+		t.Fail()
+	}
+
+	r, err := OpenLogReader(".", "application.log", ReaderOptions{})
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	defer r.Close()
+
+	count := 0
+
+	for {
+		_, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		count++
+	}
+
+	if count != 50 {
+		fmt.Println("Unexpected entry count", count)
+		t.Fail()
+	}
+}
@@ -0,0 +1,52 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+var sequenceNumbers = false
+
+// Stamps every entry with a sequence number, increasing per log file (app and public access logs),
+// logged as seq=N so that gaps and reordering can be detected downstream.
+func SetSequenceNumbers(enabled bool) {
+	sequenceNumbers = enabled
+}
+
+// Returns the next sequence number of the channel, 0 if sequence numbers are disabled.
+func (c *channel) nextSequence() uint64 {
+
+	if !sequenceNumbers {
+		return 0
+	}
+
+	return atomic.AddUint64(&c.sequence, 1)
+}
+
+func formatSequence(seq uint64) string {
+	return "seq=" + strconv.FormatUint(seq, 10)
+}
@@ -0,0 +1,68 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSequenceNumbers(t *testing.T) {
+
+	SetSequenceNumbers(true)
+	defer SetSequenceNumbers(false)
+
+	first := decorateAppLogEntry(INFO, INFO, nil, []interface{}{"first", Field{Key: "user", Value: "bob"}}, 1)
+	second := decorateAppLogEntry(INFO, INFO, nil, []interface{}{"second"}, 1)
+
+	if first.Sequence == 0 || second.Sequence != first.Sequence+1 {
+		fmt.Println("Unexpected sequence numbers", first.Sequence, second.Sequence)
+		t.Fail()
+	}
+
+	parsed, err := ParseLogLine(first.String())
+
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	if parsed.Sequence != first.Sequence || fmt.Sprint(parsed.Fields) != "[{user bob}]" {
+		fmt.Printf("Unexpected entry %+v\n", parsed)
+		t.Fail()
+	}
+
+	if skipped := decorateAppLogEntry(DEBUG, INFO, nil, []interface{}{"not logged"}, 1); skipped != nil {
+		fmt.Println("Unexpected entry", skipped)
+		t.Fail()
+	}
+
+	SetSequenceNumbers(false)
+
+	if e := decorateAppLogEntry(INFO, INFO, nil, []interface{}{"unstamped"}, 1); e.Sequence != 0 {
+		fmt.Println("Unexpected sequence number", e.Sequence)
+		t.Fail()
+	}
+}
@@ -0,0 +1,178 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+)
+
+// Run with -race: the runtime setters are changed while entries are logged.
+func TestConcurrentSetters(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	journalOutput = ioutil.Discard
+	defer func() {
+		journalOutput = os.Stderr
+	}()
+
+	const audit = 8
+	defer unregisterLevel(audit)
+
+	defer SetAppLogLevel(INFO)
+	defer SetStdoutLogLevel(-1)
+	defer SetStdoutFormat(TextFormat)
+	defer SetStdoutColors(false)
+	defer SetShortLevels(false)
+	defer ShowLineNumbers(true)
+	defer ShowFunctionNames(false)
+	defer LogToStdout(false)
+	defer SetSynchronous(false)
+	defer SetFatalHandler(nil)
+	defer SetErrorHandler(nil)
+	defer SetJournalPrefixes(false)
+	defer SetDiscardWrites(false)
+	defer SetArchiveCompression("", 0)
+	defer SetArchiveChunkSize(0)
+	defer SetReadOnlyFallback(true)
+	defer SetAppLogMaxSize(1024)
+	defer SetAppLogMaxAge(10)
+	defer SetAppLogQuota(0)
+	defer SetPublicLogMaxSize(1024)
+	defer SetJSONMaxSize(4096)
+	defer SetFieldLimits(20, 3)
+	defer SetAppLogTemplate("")
+	defer SetAppLogSyslogFormat("", 0, "")
+	defer SetGoroutineIDs(false)
+	defer SetSchemaHeaders(false)
+	defer SetShutdownReport(false)
+	defer SetErrorLog(false)
+	defer SetOrderedWrites(false)
+	defer SetMmapWrites(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var loggers sync.WaitGroup
+	done := make(chan struct{})
+
+	req, _ := http.NewRequest("GET", "http://www.deal.com/setters", nil)
+
+	for i := 0; i < 4; i++ {
+		loggers.Add(1)
+		go func() {
+			defer loggers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					Debug("in flight")
+					Info("in flight")
+					Log(audit, "in flight")
+					Info("in flight", JSON("payload", map[string]int{"a": 1}), Slice("ids", []int{1, 2, 3}))
+					Public(*req, 200, 10, 0)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		SetAppLogLevel([]int{DEBUG, INFO, WARN}[i%3])
+		SetStdoutLogLevel([]int{-1, WARN}[i%2])
+		LogToStdout(i%2 == 0)
+		SetStdoutFormat([]string{TextFormat, JSONFormat}[i%2])
+		SetStdoutColors(i%2 == 0)
+		SetShortLevels(i%3 == 0)
+		ShowLineNumbersFor(WARN, ERROR)
+		ShowLineNumbers(i%2 == 0)
+		ShowFunctionNames(i%2 == 1)
+		SetMinFreeDiskSpace(0)
+		SetSynchronous(i%4 == 0)
+		SetFatalHandler(func(message string) {})
+		SetErrorHandler(func(err error) {})
+		SetJournalPrefixes(i%2 == 0)
+		SetDiscardWrites(i%5 == 0)
+		SetArchiveCompression([]string{"", "gzip"}[i%2], 0)
+		SetArchiveChunkSize(i % 2)
+		SetReadOnlyFallback(i%2 == 0)
+		SetAppLogMaxSize(int64(1 + i%2))
+		SetAppLogMaxAge(10 + i%2)
+		SetAppLogQuota(int64(1024 * (i % 2)))
+		SetPublicLogMaxSize(int64(1 + i%2))
+		SetJSONMaxSize(4 + i%2)
+		SetFieldLimits(1+i%2, 1+i%3)
+		SetAppLogTemplate([]string{"", "{{.Message}}"}[i%2])
+		SetAppLogSyslogFormat([]string{"", RFC5424, ""}[i%3], 1, "setters")
+		SetGoroutineIDs(i%2 == 0)
+		SetSchemaHeaders(i%2 == 1)
+		SetShutdownReport(i%2 == 0)
+		SetErrorLog(i%2 == 1)
+		SetOrderedWrites(i%2 == 0)
+		SetMmapWrites(i%2 == 1)
+		Stats()
+
+		if i%2 == 0 {
+			RegisterLevel(audit, "AUDIT", 40, 4)
+		} else {
+			unregisterLevel(audit)
+		}
+	}
+
+	close(done)
+	loggers.Wait()
+
+	SetAppLogLevel(INFO)
+	LogToStdout(false)
+	SetDiscardWrites(false)
+	SetAppLogTemplate("")
+	SetAppLogSyslogFormat("", 0, "")
+	Info("settled")
+
+	Stop()
+
+	b, err := ioutil.ReadFile("./application.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !fileContains("./application.log", "settled", t) || len(b) == 0 {
+		fmt.Println("Entries not written while the settings changed")
+		t.Fail()
+	}
+}
@@ -0,0 +1,95 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync/atomic"
+)
+
+var levelShedding int32  // 1 to shed the low level entries under queue pressure
+var shedLevel int32 = -1 // Highest level shed, -1 if none
+var shedCount int64      // Entries shed
+
+// Sheds the DEBUG entries when the app log queue is half full, and the INFO entries too when it is
+// 80% full, instead of blocking the callers. WARN entries and above are always kept. Shedding stops
+// once the queue is back under 25%. Each change is logged at WARN level and the shed entries are
+// counted in the stats.
+func SetLevelShedding(enabled bool) {
+	atomic.StoreInt32(&levelShedding, toggle(enabled))
+}
+
+// Returns true if the entry of the level must be dropped because of the app log queue pressure.
+// Called by appLogSkip, which holds runLock.
+func shed(level int) bool {
+
+	if atomic.LoadInt32(&levelShedding) == 0 || isSynchronous() || atLeast(level, WARN) {
+		return false
+	}
+
+	fill := len(appLogChan) * 100 / cap(appLogChan)
+	current := int(atomic.LoadInt32(&shedLevel))
+	next := current
+
+	switch {
+	case fill >= 80:
+		next = INFO
+	case fill >= 50:
+		if current < DEBUG {
+			next = DEBUG
+		}
+	case fill >= 25:
+		if current > DEBUG {
+			next = DEBUG
+		}
+	default:
+		next = -1
+	}
+
+	if next != current && atomic.CompareAndSwapInt32(&shedLevel, int32(current), int32(next)) {
+		logShedding(next, fill)
+	}
+
+	if next != -1 && rank(level) <= rank(next) {
+		atomic.AddInt64(&shedCount, 1)
+		return true
+	}
+
+	return false
+}
+
+// Logs the shedding change, runLock being already held (a read lock can't be taken twice).
+func logShedding(level int, fill int) {
+
+	fields := []Field{{Key: "queue_fill", Value: fill}, {Key: "shed_total", Value: atomic.LoadInt64(&shedCount)}}
+
+	message := "App log queue pressure over, entries no longer shed"
+	if level != -1 {
+		message = "App log queue under pressure, shedding entries up to " + levelName(level)
+	}
+
+	if e := decorateAppLogEntry(WARN, appLogLevel(), fields, []interface{}{message}, 2); e != nil {
+		sendAppLog(e)
+	}
+}
@@ -0,0 +1,119 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLevelShedding(t *testing.T) {
+
+	saved := appLogChan
+	defer func() { appLogChan = saved }()
+
+	appLogChan = make(chan *Entry, 100)
+	resetStats()
+
+	SetLevelShedding(true)
+	defer SetLevelShedding(false)
+
+	fill := func(n int) {
+		for len(appLogChan) < n {
+			appLogChan <- &Entry{}
+		}
+		for len(appLogChan) > n {
+			<-appLogChan
+		}
+	}
+
+	fill(60)
+	if !shed(DEBUG) || shed(INFO) || shed(WARN) {
+		t.Error("Expected DEBUG only to be shed at 60%")
+	}
+
+	fill(85)
+	if !shed(INFO) || shed(ERROR) {
+		t.Error("Expected INFO to be shed at 85%")
+	}
+
+	fill(40)
+	if shed(INFO) || !shed(DEBUG) {
+		t.Error("Expected DEBUG only to be shed at 40%")
+	}
+
+	fill(10)
+	if shed(DEBUG) {
+		t.Error("Expected no shedding at 10%")
+	}
+
+	if Stats().Shed != 3 {
+		t.Error("Unexpected shed count", Stats().Shed)
+	}
+}
+
+// The shedding change is logged by the log call holding runLock, while Stop waits for it.
+func TestSheddingWhileStopping(t *testing.T) {
+
+	saved := appLogChan
+	defer func() { appLogChan = saved }()
+
+	appLogChan = make(chan *Entry, 100)
+	for len(appLogChan) < 60 {
+		appLogChan <- &Entry{}
+	}
+
+	SetLevelShedding(true)
+	defer SetLevelShedding(false)
+	defer atomic.StoreInt32(&shedLevel, -1)
+
+	runLock.RLock() // Held by the log call
+
+	stopped := make(chan struct{})
+	go func() {
+		runLock.Lock() // Stop
+		runLock.Unlock()
+		close(stopped)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan bool)
+	go func() {
+		done <- shed(DEBUG)
+	}()
+
+	select {
+	case shedded := <-done:
+		if !shedded {
+			t.Error("Expected DEBUG to be shed at 60%")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shedding change deadlocked with Stop")
+	}
+
+	runLock.RUnlock()
+	<-stopped
+}
@@ -0,0 +1,71 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// Marker of a shipped archive, e.g. 2017-03-01-0-application.log.gz.shipped
+const shippedSuffix = ".shipped"
+
+var shippingConfirmation = false
+
+// Makes the purges, the quotas and the disk space watchdog only remove the archives confirmed
+// shipped by an external shipper, with MarkShipped or a file.shipped marker next to the archive.
+func SetShippingConfirmation(enabled bool) {
+	shippingConfirmation = enabled
+}
+
+// Confirms that the archive file of the folder (as listed in its manifest) has been shipped,
+// so that it can be purged.
+func MarkShipped(folder string, file string) error {
+	return ioutil.WriteFile(folder+"/"+file+shippedSuffix, nil, 0644)
+}
+
+// Returns true if the file at path can't be removed until it is confirmed shipped.
+func awaitingShipment(path string) bool {
+
+	if !shippingConfirmation {
+		return false
+	}
+
+	_, err := os.Stat(path + shippedSuffix)
+
+	return err != nil
+}
+
+// Removes the archive at path and its shipped marker.
+func removeArchive(path string) error {
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	os.Remove(path + shippedSuffix)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestShippingConfirmation(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetAppLogMaxAge(1)
+	defer SetAppLogMaxAge(10)
+
+	SetShippingConfirmation(true)
+	defer SetShippingConfirmation(false)
+
+	old := time.Now().AddDate(0, 0, -3)
+	for _, name := range []string{"2000-01-01-0-application.log", "2000-01-02-0-application.log"} {
+		ioutil.WriteFile("./"+name, []byte("entry\n"), 0644)
+		os.Chtimes("./"+name, old, old)
+	}
+
+	if removed, _, _ := purge([]*channel{appChannel}, false); len(removed) != 0 {
+		fmt.Println("Archives removed before being shipped", removed)
+		t.Fail()
+	}
+
+	if err := MarkShipped(".", "2000-01-01-0-application.log"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, _, _ := purge([]*channel{appChannel}, false)
+
+	if len(removed) != 1 || removed[0] != "./2000-01-01-0-application.log" {
+		fmt.Println("Unexpected removed archives", removed)
+		t.Fail()
+	}
+
+	if _, err := os.Stat("./2000-01-01-0-application.log" + shippedSuffix); !os.IsNotExist(err) {
+		fmt.Println("Marker of a removed archive kept")
+		t.Fail()
+	}
+
+	// The quota spares the archives not shipped either
+	ioutil.WriteFile("./2000-01-02-0-application.log", make([]byte, 4096), 0644)
+	SetAppLogQuota(1)
+	defer SetAppLogQuota(0)
+
+	if over, _ := appChannel.overQuota(); len(over) != 0 {
+		fmt.Println("Archives over the quota removed before being shipped", over)
+		t.Fail()
+	}
+}
@@ -0,0 +1,135 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"log"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink receives the application log entries at or above its level, in addition to the app log file
+// (e.g. a remote alerting service). WriteEntry is called from the app log write routines.
+type Sink interface {
+	WriteEntry(e Entry) error
+}
+
+type levelSink struct {
+	sink  Sink
+	level int
+}
+
+var sinks []levelSink
+var sinkMinLevel = -1 // Lowest level accepted by a sink, -1 without sinks
+var sinksLock = sync.RWMutex{}
+
+// Adds a sink receiving the application log entries at or above the level. The sink must be a
+// pointer, as RemoveSink finds it by identity; other sinks are reported and ignored.
+func AddSink(sink Sink, level int) {
+
+	if !checkLevel(level) {
+		return
+	}
+
+	if reflect.ValueOf(sink).Kind() != reflect.Ptr {
+		reportError(errors.New("sink ignored, not a pointer"))
+		return
+	}
+
+	sinksLock.Lock()
+	defer sinksLock.Unlock()
+
+	sinks = append(sinks, levelSink{sink: sink, level: level})
+	updateSinkMinLevel()
+}
+
+// Removes a sink added with AddSink, the same pointer being passed.
+func RemoveSink(sink Sink) {
+
+	sinksLock.Lock()
+	defer sinksLock.Unlock()
+
+	kept := sinks[:0]
+	for _, s := range sinks {
+		if s.sink != sink {
+			kept = append(kept, s)
+		}
+	}
+	sinks = kept
+	updateSinkMinLevel()
+}
+
+func updateSinkMinLevel() {
+
+	sinkMinLevel = -1
+
+	for _, s := range sinks {
+		if sinkMinLevel == -1 || rank(s.level) < rank(sinkMinLevel) {
+			sinkMinLevel = s.level
+		}
+	}
+}
+
+func writeSinks(e *Entry) {
+
+	sinksLock.RLock()
+	defer sinksLock.RUnlock()
+
+	for _, s := range sinks {
+		if atLeast(e.Level, s.level) {
+			chaosSinkDelay()
+			if err := s.sink.WriteEntry(*e); err != nil {
+				log.Println("ERROR - Sink unable to log message ["+e.String()+"]", err)
+			}
+		}
+	}
+}
+
+// Returns true if stdout accepts the entry.
+func stdoutAccepts(e *Entry) bool {
+
+	level := int(atomic.LoadInt32(&stdoutLevel))
+
+	if level == -1 {
+		return e.toFile
+	}
+
+	return atLeast(e.Level, level)
+}
+
+// Returns true if a destination other than the app log file accepts the level.
+func destinationsAccept(level int) bool {
+
+	if min := int(atomic.LoadInt32(&stdoutLevel)); stdoutEnabled() && min != -1 && atLeast(level, min) {
+		return true
+	}
+
+	sinksLock.RLock()
+	defer sinksLock.RUnlock()
+
+	return sinkMinLevel != -1 && atLeast(level, sinkMinLevel)
+}
@@ -0,0 +1,153 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	lock    sync.Mutex
+	entries []Entry
+}
+
+func (s *recordingSink) WriteEntry(e Entry) error {
+	s.lock.Lock()
+	s.entries = append(s.entries, e)
+	s.lock.Unlock()
+	return nil
+}
+
+// Waits for the sink to receive an entry with the message.
+func (s *recordingSink) received(message string) bool {
+
+	for i := 0; i < 100; i++ {
+		s.lock.Lock()
+		for _, e := range s.entries {
+			if e.Message == message {
+				s.lock.Unlock()
+				return true
+			}
+		}
+		s.lock.Unlock()
+		time.Sleep(1 * time.Millisecond)
+	}
+	return false
+}
+
+func TestLevelPerDestination(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+
+	alerts := &recordingSink{}
+	AddSink(alerts, ERROR)
+	defer RemoveSink(alerts)
+
+	traces := &recordingSink{}
+	AddSink(traces, DEBUG)
+	defer RemoveSink(traces)
+
+	err := Start()
+	defer Stop()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	SetAppLogLevel(WARN)
+	defer SetAppLogLevel(INFO)
+
+	Debug("debug1")
+	Error("error1")
+
+	path := "./application.log"
+
+	if !fileContains(path, "error1", t) || fileContains(path, "debug1", t) {
+		fmt.Println("Wrong entries in the app log file")
+		t.FailNow()
+	}
+
+	if !alerts.received("error1") || alerts.received("debug1") {
+		fmt.Println("Wrong entries in the ERROR sink")
+		t.FailNow()
+	}
+
+	if !traces.received("error1") || !traces.received("debug1") {
+		fmt.Println("Wrong entries in the DEBUG sink")
+		t.FailNow()
+	}
+}
+
+// Sink of a non comparable type, which RemoveSink couldn't find by value
+type sliceSink []Entry
+
+func (s sliceSink) WriteEntry(e Entry) error {
+	return nil
+}
+
+func TestNonPointerSink(t *testing.T) {
+
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(nil)
+
+	AddSink(sliceSink{}, ERROR)
+
+	if reported == nil {
+		fmt.Println("Non pointer sink not reported")
+		t.Fail()
+	}
+
+	sinksLock.RLock()
+	added := len(sinks)
+	sinksLock.RUnlock()
+
+	if added != 0 {
+		fmt.Println("Non pointer sink added")
+		t.Fail()
+	}
+
+	alerts := &recordingSink{}
+	AddSink(alerts, ERROR)
+	RemoveSink(sliceSink{})
+	RemoveSink(alerts)
+
+	sinksLock.RLock()
+	added = len(sinks)
+	sinksLock.RUnlock()
+
+	if added != 0 {
+		fmt.Println("Sink not removed")
+		t.Fail()
+	}
+}
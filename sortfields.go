@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sort"
+)
+
+var sortFields = false
+var priorityKeys = map[string]int{} // Rank of the keys written first
+
+// Writes the fields of the entries sorted by key, the priority keys first in their given order
+// (e.g. "request_id", "user"), so that the lines of the same event stay identical across runs
+// for diffs and greps. The global fields are written after them, sorted by key.
+func SetSortedFields(enabled bool, priority ...string) {
+
+	keys := map[string]int{}
+	for i, k := range priority {
+		if _, ok := keys[k]; !ok {
+			keys[k] = i
+		}
+	}
+
+	priorityKeys = keys
+	sortFields = enabled
+}
+
+// Returns the fields sorted if enabled, without modifying the given slice.
+func sortedFields(fields []Field) []Field {
+
+	if !sortFields || len(fields) < 2 {
+		return fields
+	}
+
+	keys := priorityKeys
+	sorted := append([]Field(nil), fields...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, pi := keys[sorted[i].Key]
+		rj, pj := keys[sorted[j].Key]
+
+		switch {
+		case pi && pj:
+			return ri < rj
+		case pi != pj:
+			return pi
+		default:
+			return sorted[i].Key < sorted[j].Key
+		}
+	})
+
+	return sorted
+}
@@ -0,0 +1,70 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSortedFields(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	SetAppLogMaxSize(1024)
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := Start()
+
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	fields := []Field{{Key: "zone", Value: "eu"}, {Key: "user", Value: "bob"}, {Key: "attempt", Value: 2}, {Key: "request_id", Value: "r1"}}
+
+	Info("unsorted", fields[0], fields[1], fields[2], fields[3])
+
+	SetSortedFields(true, "request_id", "user")
+	defer SetSortedFields(false)
+
+	Info("sorted", fields[0], fields[1], fields[2], fields[3])
+
+	Stop()
+
+	if !fileContains("./application.log", "[unsorted] zone=eu user=bob attempt=2 request_id=r1", t) ||
+		!fileContains("./application.log", "[sorted] request_id=r1 user=bob attempt=2 zone=eu", t) {
+		fmt.Println("Unexpected field order", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if fields[0].Key != "zone" {
+		fmt.Println("Fields of the caller modified")
+		t.Fail()
+	}
+}
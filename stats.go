@@ -0,0 +1,139 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Statistics of the current run of gol.
+type Statistics struct {
+	Started    time.Time
+	Uptime     time.Duration
+	Entries    map[string]int64     // App log entries written per level name
+	Public     int64                // Public access log entries written
+	SampledOut int64                // Public access log entries dropped by sampling
+	Vetoed     int64                // Public access log entries dropped by a hook, see AddPublicHook
+	Dropped    int64                // Entries dropped (e.g. slow subscribers)
+	Shed       int64                // Entries shed under queue pressure or over the write budget, see SetLevelShedding and SetWriteBudget
+	Rotations  int64                // File rotations of the app and public access logs
+	Bytes      int64                // Bytes written to the app and public access log files
+	Latency    Histogram            // Latencies of the requests logged by Public, sampled out or not
+	Routes     map[string]Histogram // Latencies per route pattern, see AddRoutePattern
+}
+
+var startTime atomic.Value // time.Time, start of the current run
+var levelCounts [levelSlots]int64
+var publicCount int64
+var sampledOutCount int64
+var droppedCount int64
+
+var shutdownReport int32 // 1 to log a summary of the run on Stop
+
+// Makes Stop log a summary of the run: entries per level, drops, rotations, bytes written and uptime.
+func SetShutdownReport(enabled bool) {
+	atomic.StoreInt32(&shutdownReport, toggle(enabled))
+}
+
+// Returns the statistics of the current run.
+func Stats() Statistics {
+
+	started, _ := startTime.Load().(time.Time)
+
+	stats := Statistics{
+		Started:    started,
+		Uptime:     time.Since(started),
+		Entries:    map[string]int64{},
+		Public:     atomic.LoadInt64(&publicCount),
+		SampledOut: atomic.LoadInt64(&sampledOutCount),
+		Vetoed:     atomic.LoadInt64(&vetoedCount),
+		Dropped:    atomic.LoadInt64(&droppedCount),
+		Shed:       atomic.LoadInt64(&shedCount),
+		Latency:    publicLatencies.snapshot(),
+		Routes:     routeHistograms(),
+	}
+
+	for level, name := range levelNames() {
+		stats.Entries[name] = atomic.LoadInt64(&levelCounts[level])
+	}
+
+	for _, c := range logChannels() {
+		stats.Rotations += atomic.LoadInt64(&c.rotations)
+		stats.Bytes += atomic.LoadInt64(&c.bytes)
+	}
+
+	return stats
+}
+
+func resetStats() {
+
+	startTime.Store(time.Now())
+
+	for level := range levelCounts {
+		atomic.StoreInt64(&levelCounts[level], 0)
+	}
+
+	atomic.StoreInt64(&publicCount, 0)
+	atomic.StoreInt64(&sampledOutCount, 0)
+	atomic.StoreInt64(&vetoedCount, 0)
+	atomic.StoreInt64(&droppedCount, 0)
+	atomic.StoreInt64(&shedCount, 0)
+	atomic.StoreInt32(&shedLevel, -1)
+	publicLatencies.reset()
+	resetRouteLatencies()
+
+	for _, c := range append(logChannels(), errorChannel) {
+		atomic.StoreInt64(&c.rotations, 0)
+		atomic.StoreInt64(&c.bytes, 0)
+	}
+}
+
+// Writes the summary of the run in the app log.
+func writeShutdownReport() {
+
+	stats := Stats()
+
+	fields := []Field{{Key: "uptime", Value: stats.Uptime.Round(time.Second)}}
+
+	for _, level := range sortedLevels() {
+		fields = append(fields, Field{Key: strings.ToLower(levelName(level)), Value: stats.Entries[levelName(level)]})
+	}
+
+	fields = append(fields,
+		Field{Key: "public", Value: stats.Public},
+		Field{Key: "sampled_out", Value: stats.SampledOut},
+		Field{Key: "vetoed", Value: stats.Vetoed},
+		Field{Key: "dropped", Value: stats.Dropped},
+		Field{Key: "shed", Value: stats.Shed},
+		Field{Key: "rotations", Value: stats.Rotations},
+		Field{Key: "bytes", Value: stats.Bytes},
+	)
+
+	if e := decorateAppLogEntry(INFO, INFO, fields, []interface{}{"gol shutdown report"}, 2); e != nil {
+		doAppLogWrite(e)
+	}
+}
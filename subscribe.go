@@ -0,0 +1,95 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Receives the app log entries accepted by its filter.
+type subscriber struct {
+	filter func(e *Entry) bool
+	ch     chan Entry
+}
+
+const subscriptionBuffer = 1000
+
+var subscribers = map[*subscriber]bool{}
+var subscribersLock = sync.RWMutex{}
+
+// Returns a channel receiving the live app log entries accepted by the filter (nil accepts all), and
+// the function to call to stop receiving them, which closes the channel. Entries are dropped
+// when the subscriber is too slow to receive them.
+func Subscribe(filter func(e Entry) bool) (<-chan Entry, func()) {
+
+	if filter == nil {
+		return subscribe(nil, subscriptionBuffer)
+	}
+
+	return subscribe(func(e *Entry) bool {
+		return filter(*e)
+	}, subscriptionBuffer)
+}
+
+func subscribe(filter func(e *Entry) bool, buffer int) (<-chan Entry, func()) {
+
+	s := &subscriber{filter: filter, ch: make(chan Entry, buffer)}
+
+	subscribersLock.Lock()
+	subscribers[s] = true
+	subscribersLock.Unlock()
+
+	var once sync.Once
+
+	cancel := func() {
+		once.Do(func() {
+			subscribersLock.Lock()
+			delete(subscribers, s)
+			subscribersLock.Unlock()
+			close(s.ch)
+		})
+	}
+
+	return s.ch, cancel
+}
+
+// Sends the entry to the subscribers, without blocking.
+func publishEntry(e *Entry) {
+
+	subscribersLock.RLock()
+	defer subscribersLock.RUnlock()
+
+	for s := range subscribers {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- *e:
+		default: // Slow subscriber
+			atomic.AddInt64(&droppedCount, 1)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Syslog facilities, see RFC 5424.
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+)
+
+// SyslogSink is a sink sending the app log entries to a syslog server as RFC 5424 messages.
+// The fields of an entry are sent as the parameters of a structured data element and the name
+// of a named logger as MSGID. Stream networks (tcp, unix) use octet counting framing (RFC 6587).
+type SyslogSink struct {
+	Facility int    // Default FacilityUser
+	AppName  string // APP-NAME, default the name of the executable
+	SDID     string // ID of the structured data element of the fields, default "gol@32473" (example enterprise number)
+
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+	lock     sync.Mutex
+}
+
+// Returns a sink sending the entries to the syslog server, e.g. ("udp", "localhost:514") or ("unixgram", "/dev/log").
+func NewSyslogSink(network string, address string, appName string) (*SyslogSink, error) {
+
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+
+	if appName == "" && len(os.Args) > 0 {
+		appName = os.Args[0][strings.LastIndexAny(os.Args[0], `/\`)+1:]
+	}
+
+	return &SyslogSink{
+		Facility: FacilityUser,
+		AppName:  appName,
+		SDID:     "gol@32473",
+		network:  network,
+		address:  address,
+		hostname: hostname,
+		conn:     conn,
+	}, nil
+}
+
+func (s *SyslogSink) WriteEntry(e Entry) error {
+
+	msg := formatSyslog(e, s.Facility, s.hostname, s.AppName, os.Getpid(), s.SDID)
+
+	if s.network == "tcp" || s.network == "tcp4" || s.network == "tcp6" || s.network == "unix" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.Dial(s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil // Reconnects on the next entry
+		return err
+	}
+
+	return nil
+}
+
+// Closes the connection to the syslog server.
+func (s *SyslogSink) Close() error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+// Returns the RFC 5424 severity of the level.
+func syslogSeverity(level int) int {
+
+	levelsLock.RLock()
+	severity, ok := levelSeverities[level]
+	levelsLock.RUnlock()
+
+	if ok {
+		return severity
+	}
+
+	switch level {
+	case DEBUG:
+		return 7 // Debug
+	case INFO:
+		return 6 // Informational
+	case WARN:
+		return 4 // Warning
+	case ERROR:
+		return 3 // Error
+	default:
+		return 2 // Critical
+	}
+}
+
+// Formats the entry as an RFC 5424 message.
+func formatSyslog(e Entry, facility int, hostname string, appName string, procID int, sdID string) string {
+
+	msgID := "-"
+	params := ""
+
+	for _, f := range append(e.Fields[:len(e.Fields):len(e.Fields)], loadGlobals().fields...) {
+		if f.Key == loggerKey {
+			if name, ok := f.Value.(string); ok {
+				msgID = syslogName(name, 32)
+				continue
+			}
+		}
+
+		value := fmt.Sprint(f.Value)
+		if r, ok := f.Value.(rawValue); ok {
+			value = r.render()
+		}
+
+		params += " " + syslogName(f.Key, 32) + `="` + syslogEscaper.Replace(value) + `"`
+	}
+
+	sd := "-"
+	if params != "" {
+		sd = "[" + syslogName(sdID, 32) + params + "]"
+	}
+
+	pri := facility*8 + syslogSeverity(e.Level)
+
+	return "<" + strconv.Itoa(pri) + ">1 " +
+		e.Time.Format("2006-01-02T15:04:05.000000Z07:00") + " " +
+		syslogName(hostname, 255) + " " +
+		syslogName(appName, 48) + " " +
+		strconv.Itoa(procID) + " " +
+		msgID + " " +
+		sd + " " +
+		e.Message
+}
+
+var syslogEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// Returns the name restricted to the printable ASCII characters allowed by RFC 5424, "-" if empty.
+func syslogName(name string, max int) string {
+
+	b := []byte(name)
+
+	for i, c := range b {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) > max {
+		b = b[:max]
+	}
+
+	if len(b) == 0 {
+		return "-"
+	}
+
+	return string(b)
+}
@@ -0,0 +1,92 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatSyslog(t *testing.T) {
+
+	e := Entry{
+		Time:    time.Date(2017, 3, 4, 5, 6, 7, 8000, time.UTC),
+		Level:   WARN,
+		Message: "slow query",
+		Fields:  []Field{{Key: loggerKey, Value: "db.pool"}, {Key: "sql", Value: `select "a"]`}, {Key: "bad key", Value: 3}},
+	}
+
+	msg := formatSyslog(e, FacilityLocal0, "host1", "billing", 42, "gol@32473")
+	expected := `<132>1 2017-03-04T05:06:07.000008Z host1 billing 42 db.pool [gol@32473 sql="select \"a\"\]" bad_key="3"] slow query`
+
+	if msg != expected {
+		fmt.Println("Unexpected message", msg)
+		t.Fail()
+	}
+
+	e.Fields = nil
+	e.Level = FATAL
+
+	if msg = formatSyslog(e, FacilityUser, "", "", 42, "gol@32473"); !strings.HasPrefix(msg, "<10>1 ") || !strings.HasSuffix(msg, " - - 42 - - slow query") {
+		fmt.Println("Unexpected message", msg)
+		t.Fail()
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+	defer server.Close()
+
+	sink, err := NewSyslogSink("udp", server.LocalAddr().String(), "billing")
+	if err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+	defer sink.Close()
+
+	if err := sink.WriteEntry(Entry{Time: time.Now(), Level: ERROR, Message: "payment failed"}); err != nil {
+		fmt.Println(err)
+		t.Fatal()
+	}
+
+	server.SetReadDeadline(time.Now().Add(1 * time.Second))
+
+	buf := make([]byte, 1024)
+	n, _, err := server.ReadFrom(buf)
+
+	if err != nil || !strings.HasPrefix(string(buf[:n]), "<11>1 ") || !strings.Contains(string(buf[:n]), " billing ") ||
+		!strings.HasSuffix(string(buf[:n]), "payment failed") {
+		fmt.Println("Unexpected syslog message", string(buf[:n]), err)
+		t.Fail()
+	}
+}
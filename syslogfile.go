@@ -0,0 +1,138 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Syslog line formats of the app log file, see SetAppLogSyslogFormat.
+const (
+	RFC3164 = "rfc3164" // <PRI>Mmm dd hh:mm:ss host tag[pid]: message key=value...
+	RFC5424 = "rfc5424" // As sent by the SyslogSink
+)
+
+// Syslog line format of the app log file, nil for the gol layout
+type syslogFile struct {
+	format   string
+	facility int
+	hostname string
+	tag      string
+}
+
+var appSyslogFormat atomic.Value // *syslogFile, nil for the gol layout
+
+// Writes the app log file in a syslog line format, RFC3164 or RFC5424, with the facility and
+// the tag (APP-NAME), the name of the executable if empty, for collectors tailing syslog files.
+// It takes precedence over the template, see SetAppLogTemplate. Line breaks of the messages are
+// written as #012, as rsyslog does. An empty format restores the gol layout.
+func SetAppLogSyslogFormat(format string, facility int, tag string) error {
+
+	if format == "" {
+		appSyslogFormat.Store((*syslogFile)(nil))
+		appChannel.update(func() { appChannel.format = appFileFormat() })
+		return nil
+	}
+
+	if format != RFC3164 && format != RFC5424 {
+		return errors.New("unknown syslog format [" + format + "]")
+	}
+
+	if facility < 0 || facility > 23 {
+		return errors.New("invalid syslog facility " + strconv.Itoa(facility))
+	}
+
+	hostname, _ := os.Hostname()
+
+	if tag == "" && len(os.Args) > 0 {
+		tag = os.Args[0][strings.LastIndexAny(os.Args[0], `/\`)+1:]
+	}
+
+	appSyslogFormat.Store(&syslogFile{format: format, facility: facility, hostname: hostname, tag: tag})
+	appChannel.update(func() { appChannel.format = appFileFormat() })
+
+	return nil
+}
+
+// Returns the syslog line format of the app log file, nil for the gol layout.
+func loadAppSyslogFormat() *syslogFile {
+	f, _ := appSyslogFormat.Load().(*syslogFile)
+	return f
+}
+
+// Returns the format of the app log file for the schema header, empty for the gol layout.
+func appFileFormat() string {
+
+	if f := loadAppSyslogFormat(); f != nil {
+		return f.format
+	}
+
+	if loadAppTemplate() != nil {
+		return "template"
+	}
+
+	return ""
+}
+
+var syslogLineEscaper = strings.NewReplacer("\r\n", "#012", "\n", "#012", "\r", "#015")
+
+// Returns the entry formatted as a syslog line.
+func (s *syslogFile) line(e *Entry) string {
+
+	entry := *e
+	entry.Message = syslogLineEscaper.Replace(e.Message)
+
+	if s.format == RFC5424 {
+		return formatSyslog(entry, s.facility, s.hostname, s.tag, os.Getpid(), "gol@32473") + "\n"
+	}
+
+	buf := make([]byte, 0, 64+len(entry.Message))
+
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(s.facility*8+syslogSeverity(e.Level)), 10)
+	buf = append(buf, '>')
+	buf = append(buf, e.Time.Format("Jan _2 15:04:05")...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogName(s.hostname, 255)...)
+	buf = append(buf, ' ')
+	buf = append(buf, syslogName(s.tag, 32)...)
+	buf = append(buf, '[')
+	buf = strconv.AppendInt(buf, int64(os.Getpid()), 10)
+	buf = append(buf, "]: "...)
+	buf = append(buf, entry.Message...)
+
+	for _, f := range append(e.Fields[:len(e.Fields):len(e.Fields)], loadGlobals().fields...) {
+		buf = append(buf, ' ')
+		buf = appendField(buf, f)
+	}
+
+	buf = append(buf, '\n')
+
+	return string(buf)
+}
@@ -0,0 +1,80 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestAppLogSyslogFormat(t *testing.T) {
+
+	pid := strconv.Itoa(os.Getpid())
+
+	for format, pattern := range map[string]string{
+		RFC3164: `^<134>[A-Z][a-z]{2} [ 0-9]\d \d\d:\d\d:\d\d \S+ billing\[` + pid + `\]: first#012second user=bob\n` +
+			`<132>[A-Z][a-z]{2} [ 0-9]\d \d\d:\d\d:\d\d \S+ billing\[` + pid + `\]: low disk\n$`,
+		RFC5424: `^<134>1 \S+ \S+ billing ` + pid + ` - \[gol@32473 user="bob"\] first#012second\n` +
+			`<132>1 \S+ \S+ billing ` + pid + ` - - low disk\n$`,
+	} {
+		removeLogFiles(".")
+
+		SetAppLogFolder(".")
+		SetPublicLogFolder(".")
+		LogToStdout(false)
+		SetSynchronous(true)
+
+		if err := SetAppLogSyslogFormat(format, FacilityLocal0, "billing"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		With("user", "bob").Info("first\nsecond")
+		Warn("low disk")
+
+		Stop()
+
+		text := readFile("./application.log", t)
+
+		if !regexp.MustCompile(pattern).MatchString(text) {
+			fmt.Printf("Unexpected %s file %q\n", format, text)
+			t.Fail()
+		}
+	}
+
+	SetAppLogSyslogFormat("", 0, "")
+	SetSynchronous(false)
+
+	if err := SetAppLogSyslogFormat("rfc1", FacilityUser, ""); err == nil {
+		fmt.Println("Unknown syslog format accepted")
+		t.Fail()
+	}
+}
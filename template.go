@@ -0,0 +1,109 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"text/template"
+)
+
+var appTemplate atomic.Value // *template.Template, layout of the app log entries, nil for the gol layout
+
+// Functions of the app log templates
+var templateFuncs = template.FuncMap{
+	"level": func(level int) string { return levelName(level) },
+	"short": shortLevelName,
+	"fields": func(fields []Field) string {
+		var buf []byte
+		for i, f := range fields {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = appendField(buf, f)
+		}
+		return string(buf)
+	},
+	"pad": func(width int, s string) string {
+		if len(s) >= width {
+			return s
+		}
+		return s + strings.Repeat(" ", width-len(s))
+	},
+}
+
+// Sets the layout of the app log entries as a text/template executed with the *Entry, e.g.
+//
+//	{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}} {{level .Level | pad 5}} {{.Message}} {{fields .Fields}}
+//
+// The functions level and short return the name and the 3 letter token of a level, fields the
+// key=value pairs of the fields (global fields included) and pad a string padded to a width.
+// Entries are written on one line, the gol readers can't parse a custom layout. An empty
+// template restores the gol layout.
+func SetAppLogTemplate(text string) error {
+
+	if text == "" {
+		appTemplate.Store((*template.Template)(nil))
+		appChannel.update(func() { appChannel.format = appFileFormat() })
+		return nil
+	}
+
+	t, err := template.New("app").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	appTemplate.Store(t)
+	appChannel.update(func() { appChannel.format = appFileFormat() })
+
+	return nil
+}
+
+// Returns the app log template, nil for the gol layout.
+func loadAppTemplate() *template.Template {
+	t, _ := appTemplate.Load().(*template.Template)
+	return t
+}
+
+// Formats the entry with the app log template, the gol layout being kept if it fails.
+func applyTemplate(t *template.Template, e *Entry) {
+
+	data := *e
+	data.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], loadGlobals().fields...)
+
+	var buf bytes.Buffer
+
+	if err := t.Execute(&buf, &data); err != nil {
+		reportError(err)
+		return
+	}
+
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	e.text = buf.String()
+}
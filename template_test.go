@@ -0,0 +1,75 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAppLogTemplate(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetSynchronous(true)
+	defer SetSynchronous(false)
+
+	err := SetAppLogTemplate(`{{.Time.Format "2006"}}|{{level .Level | pad 5}}|{{short .Level}}|{{.Message}}|{{fields .Fields}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetAppLogTemplate("")
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	With("user", "bob smith").Info("hello")
+	Warn("world")
+
+	Stop()
+
+	if !fileContains("./application.log", "|INFO |INF|hello|user=\"bob smith\"\n", t) {
+		fmt.Println("Template layout not applied", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if !fileContains("./application.log", "|WARN |WRN|world|\n", t) {
+		fmt.Println("Template layout not applied", readFile("./application.log", t))
+		t.Fail()
+	}
+
+	if err := SetAppLogTemplate("{{.Message"); err == nil {
+		fmt.Println("Invalid template accepted")
+		t.Fail()
+	}
+
+	if err := (Config{Level: "INFO", Template: "{{.Unknown"}).Validate(); err == nil {
+		fmt.Println("Invalid template not reported by Validate")
+		t.Fail()
+	}
+}
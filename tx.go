@@ -0,0 +1,173 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"log"
+	"sync"
+)
+
+// Tx groups app log entries written contiguously in the app log file on Commit, without entries
+// of other goroutines in between, or not at all on Discard. E.g. a multi-line report:
+//
+//	tx := gol.Begin()
+//	tx.Info("report", "rows", 12)
+//	tx.Warn("rows rejected", "count", 2)
+//	tx.Commit()
+type Tx struct {
+	lock    sync.Mutex
+	entries []*Entry
+	done    bool
+}
+
+// Starts a group of entries.
+func Begin() *Tx {
+	return &Tx{}
+}
+
+func (tx *Tx) Debug(v ...interface{}) {
+	tx.add(DEBUG, v)
+}
+
+func (tx *Tx) Info(v ...interface{}) {
+	tx.add(INFO, v)
+}
+
+func (tx *Tx) Warn(v ...interface{}) {
+	tx.add(WARN, v)
+}
+
+func (tx *Tx) Error(v ...interface{}) {
+	tx.add(ERROR, v)
+}
+
+// Adds an entry at the level, e.g. a custom level (see RegisterLevel).
+func (tx *Tx) Log(level int, v ...interface{}) {
+	if isLevel(level) && level != FATAL {
+		tx.add(level, v)
+	}
+}
+
+// Keeps the entry until the commit, with its time and caller.
+func (tx *Tx) add(level int, v []interface{}) {
+
+	e := decorateAppLogEntry(level, appLogLevel(), nil, v, 3)
+	if e == nil {
+		return
+	}
+
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	if !tx.done {
+		tx.entries = append(tx.entries, e)
+	}
+}
+
+// Writes the entries of the group contiguously. The group can't be used afterwards.
+func (tx *Tx) Commit() {
+
+	tx.lock.Lock()
+	entries := tx.entries
+	tx.entries, tx.done = nil, true
+	tx.lock.Unlock()
+
+	runLock.RLock()
+	defer runLock.RUnlock()
+
+	if !running || len(entries) == 0 {
+		return
+	}
+
+	group := &Entry{group: entries}
+
+	if isSynchronous() {
+		if err := doAppLogWrite(group); err != nil {
+			log.Println("Unable to log group of entries", err)
+		}
+		return
+	}
+
+	appLogChan <- group
+}
+
+// Drops the entries of the group. The group can't be used afterwards.
+func (tx *Tx) Discard() {
+
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	tx.entries, tx.done = nil, true
+}
+
+// Returns the entries of a group, or the entry itself.
+func (e *Entry) entries() []*Entry {
+
+	if e.group != nil {
+		return e.group
+	}
+
+	return []*Entry{e}
+}
+
+// Writes the entries of the group to the app log file at once (or none of them over the write
+// budget), then to their other destinations.
+func doGroupWrite(group []*Entry) error {
+
+	var buf []byte
+	level := -1
+
+	for _, e := range group {
+		if e.toFile && !e.written && routeFor(e) == nil {
+			buf = append(buf, e.String()...)
+			if rank(e.budgetLevel()) > rank(level) {
+				level = e.budgetLevel()
+			}
+		}
+	}
+
+	if len(buf) > 0 {
+		written := budget.take(len(buf), level)
+
+		for _, e := range group {
+			if e.toFile && !e.written && routeFor(e) == nil {
+				e.written = written
+				e.toFile = written
+			}
+		}
+
+		if written {
+			appChannel.write(buf)
+		}
+	}
+
+	for _, e := range group {
+		if err := doAppLogWrite(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
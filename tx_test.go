@@ -0,0 +1,129 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTxCommit(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var flood sync.WaitGroup
+	flood.Add(1)
+	go func() {
+		defer flood.Done()
+		for i := 0; i < 2000; i++ {
+			Info("concurrent entry")
+		}
+	}()
+
+	tx := Begin()
+	for i := 0; i < 20; i++ {
+		tx.Info("report line", i)
+	}
+	tx.Warn("report end")
+	tx.Commit()
+	tx.Info("after commit")
+
+	discarded := Begin()
+	discarded.Error("discarded entry")
+	discarded.Discard()
+
+	flood.Wait()
+	Stop()
+
+	text := readFile("./application.log", t)
+
+	start := strings.Index(text, "[report line 0]")
+	end := strings.Index(text, "[report end]")
+
+	if start < 0 || end < start {
+		fmt.Println("Group not written")
+		t.FailNow()
+	}
+
+	if strings.Contains(text[start:end], "concurrent entry") || strings.Count(text[start:end], "\n") != 20 {
+		fmt.Println("Group not written contiguously")
+		t.Fail()
+	}
+
+	if strings.Contains(text, "after commit") || strings.Contains(text, "discarded entry") {
+		fmt.Println("Discarded or committed group written")
+		t.Fail()
+	}
+
+	if !strings.Contains(text[start:start+200], "tx_test.go") {
+		fmt.Println("Caller not reported")
+		t.Fail()
+	}
+}
+
+func TestTxOrderedWrites(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+	LogToStdout(false)
+	SetOrderedWrites(true)
+	defer SetOrderedWrites(false)
+
+	if err := Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("before")
+	tx := Begin()
+	tx.Info("first")
+	tx.Info("second")
+	tx.Commit()
+	Info("after")
+
+	Stop()
+
+	text := readFile("./application.log", t)
+
+	if i := strings.Index(text, "[before]"); i < 0 || i > strings.Index(text, "[first]") ||
+		strings.Index(text, "[second]") > strings.Index(text, "[after]") {
+		fmt.Println("Group not written in order")
+		t.Fail()
+	}
+
+	if n := Stats().Entries["INFO"]; n != 4 {
+		fmt.Println("Unexpected number of entries", n)
+		t.Fail()
+	}
+}
@@ -0,0 +1,165 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var minFreeDisk int64 = 0    // Minimum free space in KB on the log volumes, 0 disables the watchdog
+var diskFreeSpace = diskFree // Returns the free space of the volume of a path
+var lowDiskSpace = false     // Free space below the minimum at the last check
+
+var errDiskFreeUnsupported = errors.New("free disk space unsupported on this platform, disk space watchdog stopped")
+
+// Sets the minimum free space in KB on the log volumes. Below it, the watchdog deletes the oldest
+// archives, disables DEBUG and reports an error through the error handler. 0 disables the watchdog.
+func SetMinFreeDiskSpace(minFree int64) {
+	atomic.StoreInt64(&minFreeDisk, minFree)
+}
+
+func watchDiskSpace() {
+
+	defer routines.Done()
+
+	for {
+		if !checkDiskSpace() || !idle(10*time.Second) {
+			return
+		}
+	}
+}
+
+// Removes the oldest archives while the free disk space is below the minimum. Returns false if
+// the free space can't be known on this platform, the error being reported once.
+func checkDiskSpace() bool {
+
+	minFree := atomic.LoadInt64(&minFreeDisk)
+	if minFree <= 0 {
+		return true
+	}
+
+	channels := logChannels()
+
+	// Archives of all the channels, oldest first
+	var archives []string
+	var modTimes []time.Time
+
+	for _, c := range channels {
+		if c.deletionPaused() {
+			continue
+		}
+		files, err := c.archives()
+		if err != nil {
+			reportError(err)
+			continue
+		}
+		folder := c.logFolder()
+		for _, f := range files {
+			if awaitingShipment(folder + "/" + f.Name()) {
+				continue
+			}
+			archives = append(archives, folder+"/"+f.Name())
+			modTimes = append(modTimes, f.ModTime())
+		}
+	}
+
+	sort.Sort(byModTime{archives, modTimes})
+
+	low := false
+
+	for _, c := range channels {
+		for {
+			free, err := diskFreeSpace(c.logFolder())
+			if err == errDiskFreeUnsupported {
+				reportError(err)
+				return false
+			}
+			if err != nil {
+				reportError(err)
+				break
+			}
+
+			if free >= uint64(minFree)*1024 {
+				break
+			}
+
+			low = true
+
+			if len(archives) == 0 {
+				break
+			}
+
+			if err := removeArchive(archives[0]); err != nil {
+				reportError(err)
+			} else {
+				log.Println("Disk space watchdog removed file [" + archives[0] + "]")
+			}
+
+			archives, modTimes = archives[1:], modTimes[1:]
+		}
+	}
+
+	if low {
+		for _, c := range channels {
+			c.updateManifest()
+		}
+	}
+
+	if low && appLogLevel() == DEBUG {
+		SetAppLogLevel(INFO)
+	}
+
+	if low && !lowDiskSpace {
+		reportError(errors.New("free disk space below " + strconv.FormatInt(minFree, 10) + "KB on the log volume"))
+	}
+
+	lowDiskSpace = low
+
+	return true
+}
+
+// Sorts archive paths by modification time.
+type byModTime struct {
+	paths    []string
+	modTimes []time.Time
+}
+
+func (b byModTime) Len() int {
+	return len(b.paths)
+}
+
+func (b byModTime) Less(i, j int) bool {
+	return b.modTimes[i].Before(b.modTimes[j])
+}
+
+func (b byModTime) Swap(i, j int) {
+	b.paths[i], b.paths[j] = b.paths[j], b.paths[i]
+	b.modTimes[i], b.modTimes[j] = b.modTimes[j], b.modTimes[i]
+}
@@ -0,0 +1,131 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDiskSpaceWatchdog(t *testing.T) {
+	removeLogFiles(".")
+
+	SetAppLogFolder(".")
+	SetPublicLogFolder(".")
+
+	// Three archives, the volume has enough space once only one is left
+	for i, name := range []string{"2000-01-02-0-application.log", "2000-01-01-0-access.log", "2000-01-03-0-application.log"} {
+		if err := ioutil.WriteFile(name, []byte("archive"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().AddDate(0, 0, i-10)
+		os.Chtimes(name, modTime, modTime)
+	}
+
+	diskFreeSpace = func(path string) (uint64, error) {
+		files, _ := appChannel.archives()
+		more, _ := publicChannel.archives()
+		if len(files)+len(more) > 1 {
+			return 0, nil
+		}
+		return 2048, nil
+	}
+	defer func() { diskFreeSpace = diskFree }()
+
+	var reported []error
+	SetErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+	defer SetErrorHandler(nil)
+
+	SetMinFreeDiskSpace(1)
+	defer SetMinFreeDiskSpace(0)
+
+	SetAppLogLevel(DEBUG)
+	defer SetAppLogLevel(INFO)
+
+	checkDiskSpace()
+
+	if fileExists("2000-01-02-0-application.log", t) || fileExists("2000-01-01-0-access.log", t) {
+		fmt.Println("Oldest archives not removed")
+		t.Fail()
+	}
+
+	if !fileExists("2000-01-03-0-application.log", t) {
+		fmt.Println("Newest archive removed")
+		t.Fail()
+	}
+
+	if appLogLevel() != INFO {
+		fmt.Println("DEBUG not disabled")
+		t.Fail()
+	}
+
+	if len(reported) != 1 {
+		fmt.Println("Low disk space not reported")
+		t.Fail()
+	}
+}
+
+func TestDiskSpaceUnsupported(t *testing.T) {
+
+	diskFreeSpace = func(path string) (uint64, error) {
+		return 0, errDiskFreeUnsupported
+	}
+	defer func() { diskFreeSpace = diskFree }()
+
+	var reported []error
+	SetErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+	defer SetErrorHandler(nil)
+
+	SetMinFreeDiskSpace(1)
+	defer SetMinFreeDiskSpace(0)
+
+	stopping = make(chan struct{})
+	routines.Add(1)
+
+	done := make(chan struct{})
+	go func() {
+		watchDiskSpace()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		close(stopping)
+		t.Fatal("Watchdog not stopped")
+	}
+
+	if len(reported) != 1 || reported[0] != errDiskFreeUnsupported {
+		fmt.Println("Unsupported free disk space not reported once", reported)
+		t.Fail()
+	}
+}
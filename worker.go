@@ -0,0 +1,78 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+type workerKey struct{}
+
+var goroutineIDs int32 // 1 to tag the entries with the goroutine ID
+
+// Returns a context labelling the entries logged with it through DebugContext, InfoContext,
+// WarnContext and ErrorContext with worker=label. Loggers can use With("worker", label).
+func WithWorker(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, workerKey{}, label)
+}
+
+// Tags every app log entry with goroutine=N, the ID of the logging goroutine, so that the
+// interleaved entries of a worker pool can be untangled. The ID is only meant for debugging.
+func SetGoroutineIDs(enabled bool) {
+	atomic.StoreInt32(&goroutineIDs, toggle(enabled))
+}
+
+// Returns the worker label of the context, empty if none.
+func workerLabel(ctx context.Context) string {
+
+	if ctx == nil {
+		return ""
+	}
+
+	label, _ := ctx.Value(workerKey{}).(string)
+
+	return label
+}
+
+// Returns the ID of the current goroutine, parsed from the header of its stack trace.
+func goroutineID() uint64 {
+
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+
+	// goroutine 18 [running]:
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+
+	return id
+}
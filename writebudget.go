@@ -0,0 +1,115 @@
+//
+// MIT License
+//
+// Copyright (c) 2017 Alex Vauthey
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gol
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Token bucket of the bytes written to the log files, refilled at the rate per second
+// up to one second of writes.
+type writeBudget struct {
+	lock     sync.Mutex
+	rate     float64 // Bytes per second, 0 for no budget
+	tokens   float64
+	last     time.Time
+	shedding bool
+}
+
+var budget = &writeBudget{}
+
+// Caps the bytes written per second to the app and public access log files, e.g. 10MB/s, to spare
+// the disk bandwidth of the host. Over the budget, the DEBUG and INFO entries and the public access
+// log entries are shed (counted in the stats), WARN entries and above are always written. Each
+// start and end of the shedding is logged at WARN level. 0 disables the budget.
+func SetWriteBudget(bytesPerSecond int64) {
+
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	budget.rate = float64(bytesPerSecond)
+	budget.tokens = budget.rate
+	budget.last = time.Now()
+	budget.shedding = false
+}
+
+// Returns true if n bytes can be written at the level (-1 for an access log entry),
+// consuming the budget, and false if they must be shed.
+func (b *writeBudget) take(n int, level int) bool {
+
+	b.lock.Lock()
+
+	if b.rate <= 0 {
+		b.lock.Unlock()
+		return true
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	allowed := b.tokens >= float64(n)
+	if allowed || atLeast(level, WARN) {
+		b.tokens -= float64(n) // Debt paid by the next entries
+	}
+
+	changed := b.shedding == allowed && !atLeast(level, WARN)
+	if changed {
+		b.shedding = !allowed
+	}
+
+	b.lock.Unlock()
+
+	if changed {
+		logWriteBudget(!allowed)
+	}
+
+	if !allowed && !atLeast(level, WARN) {
+		atomic.AddInt64(&shedCount, 1)
+		return false
+	}
+
+	return true
+}
+
+// Writes the start or end of the shedding over the write budget, from the write routines.
+func logWriteBudget(shedding bool) {
+
+	message := "Write budget exceeded, shedding DEBUG, INFO and public access log entries"
+	if !shedding {
+		message = "Write budget recovered, entries no longer shed"
+	}
+
+	fields := []Field{{Key: "shed_total", Value: atomic.LoadInt64(&shedCount)}}
+
+	if e := decorateAppLogEntry(WARN, appLogLevel(), fields, []interface{}{message}, 2); e != nil {
+		doAppLogWrite(e)
+	}
+}